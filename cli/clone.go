@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/vimcolorschemes/worker/internal/clone"
+	"github.com/vimcolorschemes/worker/internal/dotenv"
+	file "github.com/vimcolorschemes/worker/internal/file"
+	repoHelper "github.com/vimcolorschemes/worker/internal/repository"
+)
+
+const defaultCloneMaxSizeMegabytes = 100
+
+// Full commit SHAs the plugins of the lockfile are pinned to
+var commitPattern = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+var errUnpinnedPlugin = errors.New("not pinned to a commit SHA, run bin/pin-plugins")
+
+// lockedPlugin is a plugin the extraction depends on, pinned to a commit in
+// vim/plugins.lock.json
+type lockedPlugin struct {
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	Commit string `json:"commit"`
+}
+
+// Reads the plugins the extraction depends on from vim/plugins.lock.json,
+// failing when one isn't pinned
+func getLockedPlugins(vimFilesPath string) ([]lockedPlugin, error) {
+	content, err := file.GetLocalFileContent(fmt.Sprintf("%s/plugins.lock.json", vimFilesPath))
+	if err != nil {
+		return nil, err
+	}
+
+	var plugins []lockedPlugin
+	if err := json.Unmarshal([]byte(content), &plugins); err != nil {
+		return nil, fmt.Errorf("error parsing plugins.lock.json: %w", err)
+	}
+
+	for _, plugin := range plugins {
+		if plugin.Name == "" || plugin.URL == "" {
+			return nil, fmt.Errorf("plugins.lock.json entry %v needs a name and a url", plugin)
+		}
+		if !commitPattern.MatchString(plugin.Commit) {
+			return nil, fmt.Errorf("plugins.lock.json entry %s: %w", plugin.Name, errUnpinnedPlugin)
+		}
+	}
+
+	return plugins, nil
+}
+
+// Returns the cloner of the colorscheme repositories, caching them in
+// CLONE_CACHE_DIRECTORY when set, and rejecting the ones over
+// CLONE_MAX_SIZE_MB (defaults to 100, 0 disables it)
+func getCloner() (clone.Cloner, error) {
+	cachePath, _ := dotenv.Get("CLONE_CACHE_DIRECTORY")
+
+	maxSizeMegabytes := defaultCloneMaxSizeMegabytes
+	if value, exists := dotenv.Get("CLONE_MAX_SIZE_MB"); exists && value != "" {
+		var err error
+		maxSizeMegabytes, err = dotenv.GetInt("CLONE_MAX_SIZE_MB")
+		if err != nil {
+			return clone.Cloner{}, err
+		}
+	}
+
+	return clone.Cloner{CachePath: cachePath, MaxSize: int64(maxSizeMegabytes) << 20}, nil
+}
+
+// Returns the error code of a failed clone, clone_not_found when the
+// repository doesn't exist anymore
+func cloneErrorCode(err error) repoHelper.ErrorCode {
+	if clone.IsNotFound(err) {
+		return repoHelper.CloneNotFoundError
+	}
+	return repoHelper.UnknownError
+}
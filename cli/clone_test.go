@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGetLockedPlugins(t *testing.T) {
+	t.Run("reads the repository lockfile", func(t *testing.T) {
+		plugins, err := getLockedPlugins("../vim")
+		if err != nil {
+			t.Fatalf("getLockedPlugins returned error: %v", err)
+		}
+		names := map[string]bool{}
+		for _, plugin := range plugins {
+			names[plugin.Name] = true
+		}
+		if !names["extractor.nvim"] || !names["lush.nvim"] {
+			t.Fatalf("plugins = %v, want extractor.nvim and lush.nvim", plugins)
+		}
+	})
+
+	t.Run("rejects entries without a commit", func(t *testing.T) {
+		directory := t.TempDir()
+		content := `[{"name": "lush.nvim", "url": "https://github.com/rktjmp/lush.nvim", "commit": ""}]`
+		if err := os.WriteFile(filepath.Join(directory, "plugins.lock.json"), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := getLockedPlugins(directory); !errors.Is(err, errUnpinnedPlugin) {
+			t.Fatalf("getLockedPlugins error = %v, want an unpinned plugin error", err)
+		}
+	})
+
+	t.Run("rejects entries without a url", func(t *testing.T) {
+		directory := t.TempDir()
+		if err := os.WriteFile(filepath.Join(directory, "plugins.lock.json"), []byte(`[{"name": "lush.nvim"}]`), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := getLockedPlugins(directory); err == nil {
+			t.Fatal("getLockedPlugins returned no error")
+		}
+	})
+}
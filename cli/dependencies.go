@@ -0,0 +1,153 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/vimcolorschemes/worker/internal/clone"
+	"github.com/vimcolorschemes/worker/internal/dependencies"
+	"github.com/vimcolorschemes/worker/internal/scan"
+)
+
+// Clones of dependencies get their own timeout, the clone of a large plugin
+// taking longer than an extraction
+const dependencyCloneTimeout = 2 * time.Minute
+
+var dependencyTable dependencies.Table
+var dependencyDirectoryPath string
+
+// Dependencies installed during the run, keyed by name. The locked plugins
+// are preinstalled in the pack path, the others are cloned once and linked
+// into the pack path for the repositories requiring them.
+var installedDependencies map[string]dependencies.Resolved
+var preinstalledDependencies map[string]bool
+var failedDependencies map[string]error
+
+// Commits of the dependencies of the run keyed by name, read once at setup from
+// the lockfile so every run installs the same commits until they're pinned
+// again
+var dependencyCommits map[string]string
+
+// Loads the dependency table, recording the locked plugins it lists as
+// installed at their locked commit
+func setupDependencies(pluginCommits map[string]string) {
+	var err error
+	dependencyTable, err = dependencies.Load()
+	if err != nil {
+		log.Panic(err)
+	}
+
+	installedDependencies = map[string]dependencies.Resolved{}
+	preinstalledDependencies = map[string]bool{}
+	failedDependencies = map[string]error{}
+	for _, dependency := range dependencyTable.Dependencies {
+		if commit, ok := pluginCommits[dependency.Name]; ok {
+			installedDependencies[dependency.Name] = dependencies.Resolved{Name: dependency.Name, Repository: dependency.Repository, Commit: commit}
+			preinstalledDependencies[dependency.Name] = true
+		}
+	}
+}
+
+// Returns the commits of every dependency of the table, the preinstalled ones
+// at their locked plugin commit and the others at their commit in
+// dependencies.lock.json
+func resolveDependencyCommits() (map[string]string, error) {
+	lockedCommits, err := dependencyTable.Commits()
+	if err != nil {
+		return nil, err
+	}
+
+	dependencyCommits = map[string]string{}
+	for _, dependency := range dependencyTable.Dependencies {
+		if installed, ok := installedDependencies[dependency.Name]; ok {
+			dependencyCommits[dependency.Name] = installed.Commit
+			continue
+		}
+		dependencyCommits[dependency.Name] = lockedCommits[dependency.Name]
+	}
+	return dependencyCommits, nil
+}
+
+// Installs the dependencies the repository requires into the pack path, and
+// returns the ones installed. A dependency failing to install is skipped,
+// leaving the extraction to fail with the error of the missing module.
+func installDependencies(result scan.Result) []dependencies.Resolved {
+	required, unresolved := dependencyTable.Resolve(result.Dependencies)
+	if len(unresolved) > 0 {
+		log.Printf("No known dependency provides %v", unresolved)
+	}
+
+	var resolved []dependencies.Resolved
+	for _, dependency := range required {
+		installed, err := installDependency(dependency)
+		if err != nil {
+			log.Printf("Error installing dependency %s: %s", dependency.Name, err)
+			continue
+		}
+
+		if !preinstalledDependencies[dependency.Name] {
+			link := fmt.Sprintf("%s/%s", packDirectoryPath, dependency.Name)
+			if err := os.Symlink(fmt.Sprintf("%s/%s", dependencyDirectoryPath, dependency.Name), link); err != nil {
+				log.Printf("Error linking dependency %s: %s", dependency.Name, err)
+				continue
+			}
+		}
+		resolved = append(resolved, installed)
+	}
+	return resolved
+}
+
+// Removes the dependencies linked into the pack path for a repository
+func removeDependencies(resolved []dependencies.Resolved) {
+	for _, dependency := range resolved {
+		if preinstalledDependencies[dependency.Name] {
+			continue
+		}
+		if err := os.Remove(fmt.Sprintf("%s/%s", packDirectoryPath, dependency.Name)); err != nil {
+			log.Printf("Error removing dependency %s: %s", dependency.Name, err)
+		}
+	}
+}
+
+// Clones a dependency at its resolved commit the first time it's required,
+// and returns the commit it was installed at
+func installDependency(dependency dependencies.Dependency) (dependencies.Resolved, error) {
+	if installed, ok := installedDependencies[dependency.Name]; ok {
+		return installed, nil
+	}
+	if err, ok := failedDependencies[dependency.Name]; ok {
+		return dependencies.Resolved{}, err
+	}
+
+	installed, err := cloneDependency(dependency)
+	if err != nil {
+		failedDependencies[dependency.Name] = err
+		return dependencies.Resolved{}, err
+	}
+	installedDependencies[dependency.Name] = installed
+	return installed, nil
+}
+
+func cloneDependency(dependency dependencies.Dependency) (dependencies.Resolved, error) {
+	log.Printf("Installing dependency %s", dependency.Name)
+
+	target := fmt.Sprintf("%s/%s", dependencyDirectoryPath, dependency.Name)
+
+	ctx, cancel := context.WithTimeout(context.Background(), dependencyCloneTimeout)
+	defer cancel()
+
+	dependencyCloner := clone.Cloner{MaxSize: cloner.MaxSize}
+	if err := dependencyCloner.ClonePinned(ctx, dependency.URL(), dependencyCommits[dependency.Name], target); err != nil {
+		return dependencies.Resolved{}, fmt.Errorf("installing %s: %w", dependency.Name, err)
+	}
+
+	commit, err := clone.Head(ctx, target)
+	if err != nil {
+		return dependencies.Resolved{}, fmt.Errorf("reading the commit of %s: %w", dependency.Name, err)
+	}
+
+	return dependencies.Resolved{Name: dependency.Name, Repository: dependency.Repository, Commit: commit}, nil
+}
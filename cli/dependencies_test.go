@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/vimcolorschemes/worker/internal/dependencies"
+	"github.com/vimcolorschemes/worker/internal/scan"
+)
+
+func TestInstallDependencies(t *testing.T) {
+	tmpDirectoryPath = t.TempDir()
+	packDirectoryPath = filepath.Join(tmpDirectoryPath, "pack", "plugins", "start")
+	dependencyDirectoryPath = filepath.Join(tmpDirectoryPath, "dependencies")
+	if err := os.MkdirAll(filepath.Join(dependencyDirectoryPath, "colorbuddy.nvim", "lua", "colorbuddy"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(packDirectoryPath, 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	setupDependencies(map[string]string{"extractor.nvim": "a", "lush.nvim": "b"})
+	// Installed earlier in the run
+	installedDependencies["colorbuddy.nvim"] = dependencies.Resolved{Name: "colorbuddy.nvim", Repository: "tjdevries/colorbuddy.nvim", Commit: "c"}
+
+	resolved := installDependencies(scan.Result{Dependencies: []string{"colorbuddy", "lush", "unknown"}})
+
+	var names []string
+	for _, dependency := range resolved {
+		names = append(names, dependency.Name+"@"+dependency.Commit)
+	}
+	if !slices.Equal(names, []string{"lush.nvim@b", "colorbuddy.nvim@c"}) {
+		t.Fatalf("installDependencies() = %v, want [lush.nvim@b colorbuddy.nvim@c]", names)
+	}
+	if _, err := os.Stat(filepath.Join(packDirectoryPath, "colorbuddy.nvim", "lua", "colorbuddy")); err != nil {
+		t.Fatalf("colorbuddy.nvim is not linked into the pack path: %v", err)
+	}
+
+	removeDependencies(resolved)
+	if _, err := os.Lstat(filepath.Join(packDirectoryPath, "colorbuddy.nvim")); !os.IsNotExist(err) {
+		t.Fatalf("colorbuddy.nvim link still exists: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dependencyDirectoryPath, "colorbuddy.nvim")); err != nil {
+		t.Fatalf("colorbuddy.nvim clone was removed: %v", err)
+	}
+}
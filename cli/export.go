@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+
+	"github.com/vimcolorschemes/worker/internal/database"
+	"github.com/vimcolorschemes/worker/internal/dotenv"
+	"github.com/vimcolorschemes/worker/internal/export"
+	file "github.com/vimcolorschemes/worker/internal/file"
+	repoHelper "github.com/vimcolorschemes/worker/internal/repository"
+)
+
+const defaultExportDirectoryPath = "./export"
+
+// Export writes terminal and editor themes for the generated colorschemes
+func Export(_force bool, _debug bool, repoKey string) map[string]interface{} {
+	exportDirectoryPath, exists := dotenv.Get("EXPORT_DIRECTORY")
+	if !exists || exportDirectoryPath == "" {
+		exportDirectoryPath = defaultExportDirectoryPath
+	}
+
+	var repositories []repoHelper.Repository
+	if repoKey != "" {
+		repository, err := database.GetRepository(repoKey)
+		if err != nil {
+			log.Panic(err)
+		}
+		repositories = []repoHelper.Repository{repository}
+	} else {
+		var err error
+		repositories, err = database.GetRepositoriesWithColorschemes()
+		if err != nil {
+			log.Panic(err)
+		}
+	}
+
+	log.Printf("Exporting colorschemes of %d repositories to %s", len(repositories), exportDirectoryPath)
+
+	artifactCount := 0
+	repositoryErrorCount := 0
+	repositoryErrorSamples := []string{}
+
+	for _, repository := range repositories {
+		count, err := exportRepository(repository, exportDirectoryPath)
+		artifactCount += count
+		if err != nil {
+			log.Printf("Error exporting %s/%s: %s", repository.Owner.Name, repository.Name, err)
+			repositoryErrorCount++
+			repositoryErrorSamples = appendRepositoryErrorSample(repositoryErrorSamples, repository, err)
+		}
+	}
+
+	log.Printf("Exported %d artifacts", artifactCount)
+
+	return map[string]interface{}{
+		"repositoryCount":        len(repositories),
+		"repositoryErrorCount":   repositoryErrorCount,
+		"repositoryErrorSamples": repositoryErrorSamples,
+		"artifactCount":          artifactCount,
+	}
+}
+
+// Writes the artifacts of every colorscheme of the repository, and a zip
+// archive of its standalone colors/ files, returning how many were written.
+// Hidden colorschemes, failing the quality checks, are left out.
+func exportRepository(repository repoHelper.Repository, exportDirectoryPath string) (int, error) {
+	repositoryDirectoryPath := filepath.Join(exportDirectoryPath, repository.Owner.Name, repository.Name)
+
+	count := 0
+	var vimArtifacts []export.Artifact
+	for _, colorscheme := range repository.Colorschemes {
+		if colorscheme.Hidden {
+			continue
+		}
+
+		artifacts, err := export.Artifacts(colorscheme)
+		if err != nil {
+			return count, err
+		}
+
+		for _, artifact := range artifacts {
+			path := filepath.Join(repositoryDirectoryPath, filepath.FromSlash(artifact.Path))
+			if err := file.WriteToFile(artifact.Content, path); err != nil {
+				return count, fmt.Errorf("write %s: %w", path, err)
+			}
+			count++
+		}
+
+		vimArtifacts = append(vimArtifacts, export.VimArtifacts(colorscheme)...)
+	}
+
+	if len(vimArtifacts) == 0 {
+		return count, nil
+	}
+
+	archive, err := export.Archive(vimArtifacts)
+	if err != nil {
+		return count, err
+	}
+
+	path := filepath.Join(repositoryDirectoryPath, "colors.zip")
+	if err := file.WriteToFile(string(archive), path); err != nil {
+		return count, fmt.Errorf("write %s: %w", path, err)
+	}
+
+	return count + 1, nil
+}
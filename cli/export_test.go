@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	repoHelper "github.com/vimcolorschemes/worker/internal/repository"
+)
+
+func TestExportRepository(t *testing.T) {
+	exportDirectoryPath := t.TempDir()
+
+	count, err := exportRepository(repoHelper.Repository{
+		Owner: repoHelper.Owner{Name: "owner"},
+		Name:  "repo",
+		Colorschemes: []repoHelper.Colorscheme{
+			{
+				Name: "myscheme",
+				Data: repoHelper.ColorschemeData{
+					Dark: []repoHelper.ColorschemeGroup{
+						{Name: "NormalBg", HexCode: "#000000"},
+						{Name: "NormalFg", HexCode: "#ffffff"},
+					},
+				},
+			},
+			{Name: "nodata"},
+			{
+				Name:   "hidden",
+				Hidden: true,
+				Data: repoHelper.ColorschemeData{
+					Dark: []repoHelper.ColorschemeGroup{{Name: "NormalBg", HexCode: "#000000"}},
+				},
+			},
+		},
+	}, exportDirectoryPath)
+	if err != nil {
+		t.Fatalf("exportRepository returned error: %v", err)
+	}
+	if count != 9 {
+		t.Fatalf("count = %d, want 9", count)
+	}
+
+	for _, path := range []string{
+		filepath.Join("alacritty", "myscheme-dark.toml"),
+		filepath.Join("colors", "myscheme.vim"),
+		filepath.Join("colors", "myscheme.lua"),
+		"colors.zip",
+	} {
+		path = filepath.Join(exportDirectoryPath, "owner", "repo", path)
+		if _, err := os.Stat(path); err != nil {
+			t.Fatalf("expected %s to exist: %v", path, err)
+		}
+	}
+
+	hiddenPath := filepath.Join(exportDirectoryPath, "owner", "repo", "colors", "hidden.vim")
+	if _, err := os.Stat(hiddenPath); !os.IsNotExist(err) {
+		t.Fatalf("expected the hidden colorscheme not to be exported: %v", err)
+	}
+}
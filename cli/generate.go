@@ -3,27 +3,55 @@ package cli
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"log"
 	"os"
 	"os/exec"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/vimcolorschemes/worker/internal/clone"
 	"github.com/vimcolorschemes/worker/internal/database"
+	"github.com/vimcolorschemes/worker/internal/dependencies"
+	"github.com/vimcolorschemes/worker/internal/dotenv"
 	file "github.com/vimcolorschemes/worker/internal/file"
+	"github.com/vimcolorschemes/worker/internal/plugins"
 	repoHelper "github.com/vimcolorschemes/worker/internal/repository"
+	"github.com/vimcolorschemes/worker/internal/sandbox"
 )
 
 const previewGenerationTimeout = 30 * time.Second
 
+var workingDirectoryPath string
 var tmpDirectoryPath string
 var packDirectoryPath string
 var vimrcPath string
+var vimVimrcPath string
+var defaultRunner repoHelper.Runner
+var nvimVersionMatrix []nvimBinary
 var vimFilesPath string
 var colorDataFilePath string
+var extractErrorFilePath string
+var messagesFilePath string
+var highlightCapturesFilePath string
+var highlightLinksFilePath string
+var pluginQueryFilePath string
+var pluginSupportFilePath string
+var pluginList plugins.List
 var defaultColorschemeFilePath string
 var defaultColorschemes map[string]bool
+var codeSamples []codeSample
+var recipes map[string]repoHelper.Recipe
 var debugMode bool
+var colorschemeSandbox sandbox.Sandbox
+var cloner clone.Cloner
+var toolchain repoHelper.Toolchain
+var retryPolicy repoHelper.RetryPolicy
+var qualityPolicy repoHelper.QualityPolicy
 
 // Generate colorscheme data for all valid repositories
 func Generate(force bool, debug bool, repoKey string) map[string]interface{} {
@@ -31,8 +59,55 @@ func Generate(force bool, debug bool, repoKey string) map[string]interface{} {
 
 	initRuntimeFiles()
 
+	var err error
+	codeSamples, err = getCodeSamples(vimFilesPath)
+	if err != nil {
+		log.Panic(err)
+	}
+
+	recipes, err = getRecipes(vimFilesPath)
+	if err != nil {
+		log.Panic(err)
+	}
+
+	defaultRunner, err = getDefaultRunner()
+	if err != nil {
+		log.Panic(err)
+	}
+
+	nvimVersionMatrix = getNvimVersionMatrix()
+
+	sandboxConfig, err := getSandboxConfig()
+	if err != nil {
+		log.Panic(err)
+	}
+	colorschemeSandbox, err = sandbox.New(sandboxConfig)
+	if err != nil {
+		log.Panic(err)
+	}
+
+	cloner, err = getCloner()
+	if err != nil {
+		log.Panic(err)
+	}
+
+	retryPolicy, err = getRetryPolicy()
+	if err != nil {
+		log.Panic(err)
+	}
+
+	qualityPolicy, err = getQualityPolicy()
+	if err != nil {
+		log.Panic(err)
+	}
+
 	setupRuntime()
 
+	renderer, err := newPreviewRenderer(codeSamples[0].path)
+	if err != nil {
+		log.Panic(err)
+	}
+
 	fmt.Println()
 
 	var repositories []repoHelper.Repository
@@ -49,8 +124,13 @@ func Generate(force bool, debug bool, repoKey string) map[string]interface{} {
 			log.Panic(err)
 		}
 	} else {
-		var err error
-		repositories, err = database.GetRepositoriesToGenerate()
+		released, err := database.ReleaseQuarantinedRepositories()
+		if err != nil {
+			log.Panic(err)
+		}
+		log.Printf("Released %d quarantined repositories pushed since their quarantine", released)
+
+		repositories, err = database.GetRepositoriesToGenerate(defaultRunner, toolchain)
 		if err != nil {
 			log.Panic(err)
 		}
@@ -59,23 +139,81 @@ func Generate(force bool, debug bool, repoKey string) map[string]interface{} {
 	log.Printf("Generating previews for %d repositories", len(repositories))
 	repositoryErrorCount := 0
 	repositoryErrorSamples := []string{}
+	repositorySkippedCount := 0
+	repositoryQuarantinedCount := 0
+	colorschemeHiddenCount := 0
 
 	for index, repository := range repositories {
 		log.Print("\nGenerating previews for ", repository.Owner.Name, "/", repository.Name, " (", index+1, "/", len(repositories), ")")
 
+		runner := defaultRunner
+		if repository.Runner != "" {
+			runner = repository.Runner
+		}
+
 		key := fmt.Sprintf("%s__%s", repository.Owner.Name, repository.Name)
-		err := installPlugin(repository.GithubURL, key)
+		commitSHA, err := installPlugin(repository, key)
 		if err != nil {
 			log.Printf("Error installing plugin: %s", err)
 			repositoryErrorCount++
 			repositoryErrorSamples = appendRepositoryErrorSample(repositoryErrorSamples, repository, err)
-			if eventErr := database.CreateRepositoryGenerateErrorEvent(repository.ID, err.Error()); eventErr != nil {
-				log.Printf("Error creating generate failure event: %s", eventErr)
+			if recordGenerateFailure(repository, err) {
+				repositoryQuarantinedCount++
+			}
+			continue
+		}
+
+		// Forced and single repository runs always generate
+		if !force && !debug && repoKey == "" && isRepositoryUnchanged(repository, commitSHA, runner) {
+			repositorySkippedCount++
+			if err := deletePlugin(key); err != nil {
+				log.Printf("Error deleting plugin: %s", err)
+			}
+			continue
+		}
+
+		scanResult, err := scanRepository(repository, key, commitSHA)
+		if err != nil {
+			log.Printf("Error scanning repository: %s", err)
+			if deleteError := deletePlugin(key); deleteError != nil {
+				log.Printf("Error deleting plugin: %s", deleteError)
+			}
+			repositoryErrorCount++
+			repositoryErrorSamples = appendRepositoryErrorSample(repositoryErrorSamples, repository, err)
+			if recordGenerateFailure(repository, err) {
+				repositoryQuarantinedCount++
 			}
 			continue
 		}
 
-		var data, dataError = getColorschemeColorData()
+		// Dependencies are Lua plugins, which only nvim loads
+		var resolvedDependencies []dependencies.Resolved
+		if runner == repoHelper.NeovimRunner {
+			resolvedDependencies = installDependencies(scanResult)
+		}
+		if err := database.UpdateRepositoryDependencies(repository.ID, resolvedDependencies); err != nil {
+			log.Printf("Error storing repository dependencies: %s", err)
+		}
+
+		recipe := getRecipe(repository)
+		var data, dataError = getColorschemeColorData(runner, codeSamples[0], recipe.Env(nil))
+		dataError = withScanHint(dataError, scanResult)
+		var languageData map[string]map[string]repoHelper.ColorschemeData
+		var variantData map[string]variantColorData
+		var pluginSupport map[string][]repoHelper.PluginSupport
+		var nvimVersionResults []repoHelper.NvimVersionResult
+		if dataError == nil {
+			if runner == repoHelper.NeovimRunner {
+				pluginSupport, err = getPluginSupport()
+				if err != nil {
+					log.Printf("Error getting plugin support: %s", err)
+				}
+				nvimVersionResults = runNvimVersionMatrix(nvimVersionMatrix, codeSamples[0], recipe.Env(nil), data)
+			}
+			languageData = getLanguageColorData(runner, codeSamples[1:], recipe.Env(nil))
+			variantData = getVariantColorData(runner, codeSamples, recipe)
+		}
+		removeDependencies(resolvedDependencies)
 		err = deletePlugin(key)
 		if err != nil {
 			log.Printf("Error deleting plugin: %s", err)
@@ -84,8 +222,8 @@ func Generate(force bool, debug bool, repoKey string) map[string]interface{} {
 			log.Printf("Error getting color data: %s", dataError)
 			repositoryErrorCount++
 			repositoryErrorSamples = appendRepositoryErrorSample(repositoryErrorSamples, repository, dataError)
-			if eventErr := database.CreateRepositoryGenerateErrorEvent(repository.ID, dataError.Error()); eventErr != nil {
-				log.Printf("Error creating generate failure event: %s", eventErr)
+			if recordGenerateFailure(repository, dataError) {
+				repositoryQuarantinedCount++
 			}
 			continue
 		}
@@ -98,40 +236,136 @@ func Generate(force bool, debug bool, repoKey string) map[string]interface{} {
 				continue
 			}
 
-			var backgrounds []repoHelper.BackgroundValue
-			if data[name].Light != nil {
-				backgrounds = append(backgrounds, repoHelper.LightBackground)
+			backgroundChecks := validateBackgrounds(name, data[name])
+			colorscheme := repoHelper.Colorscheme{
+				Name:             name,
+				Data:             data[name],
+				Backgrounds:      repoHelper.DetectedBackgrounds(backgroundChecks),
+				BackgroundChecks: backgroundChecks,
+				Runner:           runner,
+				PluginSupport:    pluginSupport[name],
+				CommitSHA:        commitSHA,
+			}
+
+			for language, languageColorschemes := range languageData {
+				if colorschemeData, ok := languageColorschemes[name]; ok {
+					if colorscheme.Languages == nil {
+						colorscheme.Languages = map[string]repoHelper.ColorschemeData{}
+					}
+					colorscheme.Languages[language] = colorschemeData
+				}
+			}
+
+			colorscheme = checkQuality(colorscheme)
+			colorscheme.Previews = renderPreviews(renderer, repository, colorscheme)
+			colorschemes = append(colorschemes, colorscheme)
+		}
+
+		// Each variant of the recipe is a colorscheme of its own, unless the
+		// repository ships a colorscheme of the same name
+		for _, variant := range recipe.Variants {
+			extracted, ok := variantData[variant.Name]
+			if !ok {
+				continue
 			}
-			if data[name].Dark != nil {
-				backgrounds = append(backgrounds, repoHelper.DarkBackground)
+			name := repoHelper.VariantName(variant.Colorscheme, variant.Name)
+			if _, exists := data[name]; exists {
+				log.Printf("Skipping %s variant, %s is a colorscheme of the repository", variant.Name, name)
+				continue
 			}
 
-			colorschemes = append(
-				colorschemes,
-				repoHelper.Colorscheme{
-					Name:        name,
-					Data:        data[name],
-					Backgrounds: backgrounds,
-				})
+			backgroundChecks := validateBackgrounds(name, extracted.data)
+			colorscheme := repoHelper.Colorscheme{
+				Name:             name,
+				Data:             extracted.data,
+				Languages:        extracted.languages,
+				Backgrounds:      repoHelper.DetectedBackgrounds(backgroundChecks),
+				BackgroundChecks: backgroundChecks,
+				Runner:           runner,
+				PluginSupport:    extracted.pluginSupport,
+				CommitSHA:        commitSHA,
+				Variant:          variant.Name,
+				Parent:           variant.Colorscheme,
+			}
+			colorscheme = checkQuality(colorscheme)
+			colorscheme.Previews = renderPreviews(renderer, repository, colorscheme)
+			colorschemes = append(colorschemes, colorscheme)
 		}
 
-		repository.Colorschemes = colorschemes
-		updateRepositoryAfterGenerate(repository)
+		repository.Colorschemes = repoHelper.AssignVariants(colorschemes)
+		for _, colorscheme := range repository.Colorschemes {
+			if colorscheme.Hidden {
+				colorschemeHiddenCount++
+			}
+		}
+		// The generation records the commits of the dependencies it installed only
+		generationToolchain := toolchain
+		generationToolchain.DependencyCommits = dependencies.Commits(resolvedDependencies)
+		generation := repoHelper.Generation{CommitSHA: commitSHA, Runner: runner, Toolchain: generationToolchain}
+		if err := updateRepositoryAfterGenerate(repository, generation, nvimVersionResults); err != nil {
+			log.Printf("Error storing color data: %s", err)
+			repositoryErrorCount++
+			repositoryErrorSamples = appendRepositoryErrorSample(repositoryErrorSamples, repository, err)
+			if recordGenerateFailure(repository, err) {
+				repositoryQuarantinedCount++
+			}
+		}
+	}
+
+	// Single repository and debug runs leave the neighbors to the next full run
+	similarColorschemeCount := 0
+	if repoKey == "" && !debug {
+		similarColorschemeCount, err = updateSimilarColorschemes()
+		if err != nil {
+			log.Printf("Error updating similar colorschemes: %s", err)
+		}
 	}
 
 	cleanUp()
 
 	return map[string]interface{}{
-		"repositoryCount":        len(repositories),
-		"repositoryErrorCount":   repositoryErrorCount,
-		"repositoryErrorSamples": repositoryErrorSamples,
+		"repositoryCount":            len(repositories),
+		"repositoryErrorCount":       repositoryErrorCount,
+		"repositoryErrorSamples":     repositoryErrorSamples,
+		"repositorySkippedCount":     repositorySkippedCount,
+		"repositoryQuarantinedCount": repositoryQuarantinedCount,
+		"similarColorschemeCount":    similarColorschemeCount,
+		"colorschemeHiddenCount":     colorschemeHiddenCount,
 	}
 }
 
-func updateRepositoryAfterGenerate(repository repoHelper.Repository) {
+// Stores the generated colorschemes, a failing write being a db_write error
+func updateRepositoryAfterGenerate(repository repoHelper.Repository, generation repoHelper.Generation, nvimVersionResults []repoHelper.NvimVersionResult) error {
 	log.Printf("Generated %d colorschemes", len(repository.Colorschemes))
-	data := getGenerateData(repository)
-	database.UpdateRepositoryFromGenerate(repository.ID, data)
+	data := getGenerateData(repository, generation, nvimVersionResults)
+
+	if err := database.UpdateRepositoryFromGenerate(repository.ID, data); err != nil {
+		return repoHelper.NewGenerateError(repoHelper.DBWriteError, fmt.Errorf("storing the generate data failed: %w", err))
+	}
+	return nil
+}
+
+// Computes and stores the nearest neighbors of every stored colorscheme
+func updateSimilarColorschemes() (int, error) {
+	log.Print("Computing similar colorschemes")
+
+	colorschemesByRepository, err := database.GetColorschemeGroupsByRepository(repoHelper.SimilarityGroupNames)
+	if err != nil {
+		return 0, err
+	}
+
+	var candidates []repoHelper.SimilarityCandidate
+	for repositoryID, colorschemes := range colorschemesByRepository {
+		candidates = append(candidates, repoHelper.GetSimilarityCandidates(repositoryID, colorschemes)...)
+	}
+
+	similar := repoHelper.ComputeSimilarColorschemes(candidates, repoHelper.SimilarColorschemeCount)
+	if err := database.ReplaceSimilarColorschemes(similar); err != nil {
+		return 0, err
+	}
+
+	log.Printf("Stored %d similar colorschemes for %d colorscheme backgrounds", len(similar), len(candidates))
+	return len(similar), nil
 }
 
 // Initializes a temporary directory for runtime configuration files
@@ -141,11 +375,20 @@ func initRuntimeFiles() {
 		log.Panic(err)
 	}
 
+	workingDirectoryPath = workingDirectory
 	tmpDirectoryPath = fmt.Sprintf("%s/.tmp", workingDirectory)
 	packDirectoryPath = fmt.Sprintf("%s/pack/plugins/start", tmpDirectoryPath)
+	dependencyDirectoryPath = fmt.Sprintf("%s/dependencies", tmpDirectoryPath)
 	vimFilesPath = fmt.Sprintf("%s/vim", workingDirectory)
 	vimrcPath = fmt.Sprintf("%s/init.lua", tmpDirectoryPath)
+	vimVimrcPath = fmt.Sprintf("%s/vimrc", tmpDirectoryPath)
 	colorDataFilePath = fmt.Sprintf("%s/data.json", tmpDirectoryPath)
+	extractErrorFilePath = fmt.Sprintf("%s/extract_error.txt", tmpDirectoryPath)
+	messagesFilePath = fmt.Sprintf("%s/messages.json", tmpDirectoryPath)
+	highlightCapturesFilePath = fmt.Sprintf("%s/captures.json", tmpDirectoryPath)
+	highlightLinksFilePath = fmt.Sprintf("%s/links.json", tmpDirectoryPath)
+	pluginQueryFilePath = fmt.Sprintf("%s/plugin_query.json", tmpDirectoryPath)
+	pluginSupportFilePath = fmt.Sprintf("%s/plugin_support.json", tmpDirectoryPath)
 	defaultColorschemeFilePath = fmt.Sprintf("%s/default_colorschemes.json", tmpDirectoryPath)
 
 	if _, err := os.Stat(tmpDirectoryPath); !os.IsNotExist(err) {
@@ -186,10 +429,10 @@ func setupRuntime() {
 
 	myVimrc := fmt.Sprintf("vim.env.MYVIMRC=\"%s\"\n", vimrcPath)
 
-	runtimepath := fmt.Sprintf("vim.opt.runtimepath:append(\"%s\")\n", tmpDirectoryPath)
+	runtimepath := fmt.Sprintf("vim.opt.runtimepath:append(\"%s\")\nvim.opt.runtimepath:append(\"%s\")\n", tmpDirectoryPath, vimFilesPath)
 	packpath := fmt.Sprintf("vim.opt.packpath:append(\"%s\")\n", tmpDirectoryPath)
 
-	colorDataPath := fmt.Sprintf("vim.env.COLOR_DATA_PATH=\"%s\"\n", colorDataFilePath)
+	colorDataPath := fmt.Sprintf("vim.env.COLOR_DATA_PATH=vim.env.COLOR_DATA_PATH or \"%s\"\n", colorDataFilePath)
 
 	vimrcContent := fmt.Sprintf("%s\n%s\n%s\n%s\n%s\n", baseVimrcContent, myVimrc, runtimepath, packpath, colorDataPath)
 
@@ -198,19 +441,91 @@ func setupRuntime() {
 		log.Panic(err)
 	}
 
-	err = installPlugin("https://github.com/vimcolorschemes/extractor.nvim", "extractor.nvim")
+	setupVimRuntime()
+
+	pluginList, err = plugins.Load()
+	if err != nil {
+		log.Panic(err)
+	}
+
+	pluginQuery, err := json.Marshal(pluginList.Query())
 	if err != nil {
 		log.Panic(err)
 	}
 
-	err = installPlugin("https://github.com/rktjmp/lush.nvim", "lush.nvim")
+	err = file.WriteToFile(string(pluginQuery), pluginQueryFilePath)
+	if err != nil {
+		log.Panic(err)
+	}
+
+	lockedPlugins, err := getLockedPlugins(vimFilesPath)
+	if err != nil {
+		log.Panic(err)
+	}
+
+	pluginCommits := make(map[string]string, len(lockedPlugins))
+	for _, plugin := range lockedPlugins {
+		pluginCommits[plugin.Name], err = installLockedPlugin(plugin)
+		if err != nil {
+			log.Panic(err)
+		}
+	}
+
+	setupDependencies(pluginCommits)
+	lockedDependencyCommits, err := resolveDependencyCommits()
 	if err != nil {
 		log.Panic(err)
 	}
+	toolchain = getToolchain(pluginCommits, lockedDependencyCommits)
+
+	if defaultRunner == repoHelper.VimRunner {
+		// vim/autoload/vimcolorschemes/extract.vim skips the built-in
+		// colorschemes itself, and nvim might not be installed
+		log.Print("Skipping default colorschemes capture for the vim runner")
+		return
+	}
 
 	captureDefaultColorschemes()
 }
 
+// Sets up the vimrc of the vim runner, the Vim script equivalent of the nvim runtime config
+func setupVimRuntime() {
+	baseVimrcContent, err := file.GetLocalFileContent(fmt.Sprintf("%s/vimrc", vimFilesPath))
+	if err != nil {
+		log.Panic(err)
+	}
+
+	vimrcContent := fmt.Sprintf(
+		"%s\nlet $MYVIMRC = '%s'\nset runtimepath+=%s,%s\nset packpath+=%s\nif empty($COLOR_DATA_PATH)\n  let $COLOR_DATA_PATH = '%s'\nendif\n",
+		baseVimrcContent, vimVimrcPath, tmpDirectoryPath, vimFilesPath, tmpDirectoryPath, colorDataFilePath,
+	)
+
+	err = file.WriteToFile(vimrcContent, vimVimrcPath)
+	if err != nil {
+		log.Panic(err)
+	}
+}
+
+// Returns the runner of the run from GENERATE_RUNNER, nvim by default, failing
+// when its editor isn't installed. Repositories with a runner of their own
+// override it.
+func getDefaultRunner() (repoHelper.Runner, error) {
+	runner := repoHelper.NeovimRunner
+	if value, exists := dotenv.Get("GENERATE_RUNNER"); exists && value != "" {
+		var err error
+		runner, err = repoHelper.ParseRunner(value)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	// The runner is also the name of the editor binary
+	if _, err := exec.LookPath(string(runner)); err != nil {
+		return "", fmt.Errorf("the %s runner needs %s installed: %w", runner, runner, err)
+	}
+	return runner, nil
+}
+
 // captureDefaultColorschemes runs nvim to get the list of built-in colorschemes
 // and populates the defaultColorschemes map.
 func captureDefaultColorschemes() {
@@ -228,7 +543,7 @@ func captureDefaultColorschemes() {
 
 	err := cmd.Run()
 	if err != nil {
-		log.Panic(wrapCommandError(ctx, "capturing default colorschemes", err))
+		log.Panic(wrapCommandError(ctx, "capturing default colorschemes", err, repoHelper.NvimTimeoutError, repoHelper.NvimCrashError))
 	}
 
 	content, err := file.GetLocalFileContent(defaultColorschemeFilePath)
@@ -250,8 +565,9 @@ func captureDefaultColorschemes() {
 	log.Printf("Captured %d default colorschemes", len(defaultColorschemes))
 }
 
-// Installs a plugin/colorscheme on the runtime configuration from a Github URL
-func installPlugin(gitRepositoryURL string, path string) error {
+// Installs a colorscheme repository on the runtime configuration, and returns
+// the commit SHA it was installed at
+func installPlugin(repository repoHelper.Repository, path string) (string, error) {
 	log.Printf("Installing %s", path)
 
 	target := fmt.Sprintf("%s/%s", packDirectoryPath, path)
@@ -259,16 +575,42 @@ func installPlugin(gitRepositoryURL string, path string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), previewGenerationTimeout)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, "git", "clone", gitRepositoryURL, target)
+	log.Printf("Cloning %s (timeout: %s)", repository.GithubURL, previewGenerationTimeout)
 
-	log.Printf("Running %s (timeout: %s)", cmd, previewGenerationTimeout)
+	err := cloner.Clone(ctx, strconv.FormatInt(repository.ID, 10), repository.GithubURL, repository.PushedAt, target)
+	if err != nil {
+		return "", wrapCommandError(ctx, "installing "+path, err, repoHelper.CloneTimeoutError, cloneErrorCode(err))
+	}
 
-	err := cmd.Run()
+	commitSHA, err := clone.Head(ctx, target)
 	if err != nil {
-		return wrapCommandError(ctx, "installing "+path, err)
+		return "", wrapCommandError(ctx, "reading the commit of "+path, err, repoHelper.CloneTimeoutError, repoHelper.UnknownError)
 	}
 
-	return nil
+	return commitSHA, nil
+}
+
+// Installs a plugin of the runtime configuration at its locked commit, and
+// returns the commit SHA it was installed at
+func installLockedPlugin(plugin lockedPlugin) (string, error) {
+	log.Printf("Installing %s", plugin.Name)
+
+	target := fmt.Sprintf("%s/%s", packDirectoryPath, plugin.Name)
+
+	ctx, cancel := context.WithTimeout(context.Background(), previewGenerationTimeout)
+	defer cancel()
+
+	err := cloner.ClonePinned(ctx, plugin.URL, plugin.Commit, target)
+	if err != nil {
+		return "", wrapCommandError(ctx, "installing "+plugin.Name, err, repoHelper.CloneTimeoutError, cloneErrorCode(err))
+	}
+
+	commitSHA, err := clone.Head(ctx, target)
+	if err != nil {
+		return "", wrapCommandError(ctx, "reading the commit of "+plugin.Name, err, repoHelper.CloneTimeoutError, repoHelper.UnknownError)
+	}
+
+	return commitSHA, nil
 }
 
 // Clears all installation traces of the plugin
@@ -279,62 +621,270 @@ func deletePlugin(key string) error {
 	return err
 }
 
-// Gathers the colorscheme data from vimcolorschemes/extractor.nvim
-func getColorschemeColorData() (map[string]repoHelper.ColorschemeData, error) {
-	err := executePreviewGenerator()
+// Validates the backgrounds the colorscheme data was extracted for, logging
+// the mismatches. The backgrounds of the colorscheme are the detected ones.
+func validateBackgrounds(name string, data repoHelper.ColorschemeData) []repoHelper.BackgroundCheck {
+	checks := repoHelper.ValidateBackgrounds(data)
+	for _, check := range checks {
+		if check.Mismatch != "" {
+			log.Printf("%s %s background is %s (%s mismatch, confidence %.2f)", name, check.Background, check.Detected, check.Mismatch, check.Confidence)
+		}
+	}
+	return checks
+}
+
+// Renders the previews of the colorscheme, none without a renderer
+func renderPreviews(renderer *previewRenderer, repository repoHelper.Repository, colorscheme repoHelper.Colorscheme) []repoHelper.Preview {
+	if renderer == nil {
+		return nil
+	}
+	previews, err := renderer.render(repository, colorscheme)
 	if err != nil {
-		log.Printf("Error executing nvim: %s", err)
-		return nil, err
+		log.Printf("Error rendering previews: %s", err)
+	}
+	return previews
+}
+
+// Gathers the colorscheme data of the other language samples, skipping the
+// samples that fail since the main sample already succeeded
+func getLanguageColorData(runner repoHelper.Runner, samples []codeSample, recipeEnv []string) map[string]map[string]repoHelper.ColorschemeData {
+	languageData := make(map[string]map[string]repoHelper.ColorschemeData, len(samples))
+	for _, sample := range samples {
+		data, err := getColorschemeColorData(runner, sample, recipeEnv)
+		if err != nil {
+			log.Printf("Error getting %s color data: %s", sample.language, err)
+			continue
+		}
+		languageData[sample.language] = data
+	}
+	return languageData
+}
+
+// Gathers the colorscheme data of a code sample from vimcolorschemes/extractor.nvim,
+// with the recipe code set in recipeEnv run before the extraction
+func getColorschemeColorData(runner repoHelper.Runner, sample codeSample, recipeEnv []string) (map[string]repoHelper.ColorschemeData, error) {
+	dataFilePath := colorDataFilePath
+	if sample.language != repoHelper.DefaultLanguage {
+		dataFilePath = fmt.Sprintf("%s/data.%s.json", tmpDirectoryPath, sample.language)
+	}
+
+	return extractColorData(runner, string(runner), dataFilePath, sample, recipeEnv)
+}
+
+// Runs the editor binary of the runner on the code sample, and reads the
+// colorscheme data it wrote to dataFilePath
+func extractColorData(runner repoHelper.Runner, binary string, dataFilePath string, sample codeSample, recipeEnv []string) (map[string]repoHelper.ColorschemeData, error) {
+	// The Treesitter captures, links and plugin groups are recorded by nvim
+	// only, vim/autoload/vimcolorschemes/extract.vim outputs links itself
+	recorded := runner == repoHelper.NeovimRunner && sample.language == repoHelper.DefaultLanguage
+
+	env := []string{"COLOR_DATA_PATH=" + dataFilePath, "EXTRACT_ERROR_PATH=" + extractErrorFilePath}
+	env = append(env, recipeEnv...)
+	if recorded {
+		env = append(env, recorderEnv()...)
+		env = append(env, "PLUGIN_QUERY_PATH="+pluginQueryFilePath, "PLUGIN_SUPPORT_PATH="+pluginSupportFilePath)
 	}
 
-	colorSchemeOutput, err := file.GetLocalFileContent(colorDataFilePath)
+	output, err := executePreviewGenerator(runner, binary, sample.path, env)
 	if err != nil {
-		log.Printf("Error getting local file content from \"%s\": %s", colorDataFilePath, err)
+		log.Printf("Error executing %s: %s", binary, err)
 		return nil, err
 	}
 
+	// The extraction writes no data when it throws, the error explains why
+	extractError := getExtractError()
+
 	var data map[string]repoHelper.ColorschemeData
-	err = json.Unmarshal([]byte(colorSchemeOutput), &data)
+	err = readOutputFile(dataFilePath, &data)
 	if err != nil {
-		return nil, err
+		log.Printf("Error reading color data from \"%s\": %s", dataFilePath, err)
+		if extractError != nil && repoHelper.ErrorCodeOf(err) == repoHelper.EmptyOutputError {
+			err = extractError
+		}
+		return nil, repoHelper.WithErrorOutput(err, output)
 	}
 
-	if !debugMode {
-		err = os.Remove(colorDataFilePath)
-		if err != nil {
-			return nil, err
-		}
+	if recorded {
+		applyRecordedData(data)
 	}
 
 	return data, nil
 }
 
-// Starts a runtime instance and auto commands to configure and start vcspg on load
-func executePreviewGenerator() error {
-	args := []string{"-u", vimrcPath}
+// Returns the environment enabling the recorders of highlight captures and links
+func recorderEnv() []string {
+	return []string{
+		"HIGHLIGHT_CAPTURES_PATH=" + highlightCapturesFilePath,
+		"HIGHLIGHT_LINKS_PATH=" + highlightLinksFilePath,
+	}
+}
+
+// Completes the extracted data with the highlight captures and links recorded along
+func applyRecordedData(data map[string]repoHelper.ColorschemeData) {
+	captures, err := getHighlightCaptures()
+	if err != nil {
+		// Captures complete the data, which is still usable without them
+		log.Printf("Error getting highlight captures: %s", err)
+	}
+	for name, colorschemeCaptures := range captures {
+		if colorschemeData, ok := data[name]; ok {
+			data[name] = repoHelper.MergeGroups(colorschemeData, colorschemeCaptures)
+		}
+	}
+
+	var links map[string]map[repoHelper.BackgroundValue]map[string]string
+	err = readOutputFile(highlightLinksFilePath, &links)
+	if err != nil {
+		log.Printf("Error getting highlight links: %s", err)
+	}
+	for name, colorschemeLinks := range links {
+		if colorschemeData, ok := data[name]; ok {
+			colorschemeData.Light = repoHelper.ApplyLinks(colorschemeData.Light, colorschemeLinks[repoHelper.LightBackground])
+			colorschemeData.Dark = repoHelper.ApplyLinks(colorschemeData.Dark, colorschemeLinks[repoHelper.DarkBackground])
+			data[name] = colorschemeData
+		}
+	}
+}
+
+// Gathers the Treesitter and LSP semantic token groups recorded by vim/lua/vimcolorschemes/captures.lua
+func getHighlightCaptures() (map[string]repoHelper.ColorschemeData, error) {
+	var captures map[string]repoHelper.ColorschemeData
+	err := readOutputFile(highlightCapturesFilePath, &captures)
+	return captures, err
+}
 
+// Returns the error thrown while extracting the colors, written to
+// extractErrorFilePath by init.lua or vimrc, classified as a lua_error
+func getExtractError() error {
+	content, err := file.GetLocalFileContent(extractErrorFilePath)
+	if err != nil {
+		return nil
+	}
 	if !debugMode {
-		args = append(args, "--headless", "-c", ":qa!")
+		_ = os.Remove(extractErrorFilePath)
 	}
 
-	args = append(args, "./vim/code_sample.vim")
+	message := strings.TrimSpace(content)
+	if message == "" {
+		return nil
+	}
+	return repoHelper.NewGenerateError(repoHelper.LuaError, fmt.Errorf("extracting colors failed: %s", message))
+}
+
+// Reads a JSON file written by nvim or vim, and removes it unless debugging.
+// A missing or empty file is an empty_output error, an unparsable one an
+// invalid_json error.
+func readOutputFile(path string, value any) error {
+	content, err := file.GetLocalFileContent(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return repoHelper.NewGenerateError(repoHelper.EmptyOutputError, err)
+	}
+	if err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(content) == "" {
+		return repoHelper.NewGenerateError(repoHelper.EmptyOutputError, fmt.Errorf("%s is empty", path))
+	}
+
+	err = json.Unmarshal([]byte(content), value)
+	if err != nil {
+		return repoHelper.NewGenerateError(repoHelper.InvalidJSONError, fmt.Errorf("parsing %s: %w", path, err))
+	}
+
+	if !debugMode {
+		return os.Remove(path)
+	}
+
+	return nil
+}
+
+// Gathers the plugin groups and themes found by vim/lua/vimcolorschemes/plugins.lua
+// while extracting the main sample, and computes the coverage of each plugin
+func getPluginSupport() (map[string][]repoHelper.PluginSupport, error) {
+	var found map[string]map[repoHelper.BackgroundValue]plugins.Query
+	err := readOutputFile(pluginSupportFilePath, &found)
+	if err != nil {
+		return nil, err
+	}
+
+	support := make(map[string][]repoHelper.PluginSupport, len(found))
+	for name, backgrounds := range found {
+		for _, background := range []repoHelper.BackgroundValue{repoHelper.LightBackground, repoHelper.DarkBackground} {
+			if query, ok := backgrounds[background]; ok {
+				support[name] = append(support[name], pluginList.Support(background, query)...)
+			}
+		}
+	}
+
+	return support, nil
+}
+
+// Starts a runtime instance of the runner's command on the code sample,
+// whose auto commands extract the color data to the paths set in env, and
+// returns what it printed. The output is attached to the error of a failed run.
+func executePreviewGenerator(runner repoHelper.Runner, command string, codeSamplePath string, env []string) (repoHelper.ErrorOutput, error) {
+	var args []string
+	switch runner {
+	case repoHelper.VimRunner:
+		args = []string{"--not-a-term", "-u", vimVimrcPath, "-i", "NONE"}
+		if !debugMode {
+			args = append(args, "-c", "qa!")
+		}
+	default:
+		args = []string{"-u", vimrcPath}
+		if !debugMode {
+			args = append(args, "--headless", "-c", ":qa!")
+		}
+	}
+
+	args = append(args, codeSamplePath)
+
+	// Colorscheme code runs sandboxed, with a throwaway home for each run
+	homePath, err := os.MkdirTemp(tmpDirectoryPath, "home-")
+	if err != nil {
+		return repoHelper.ErrorOutput{}, err
+	}
+	if !debugMode {
+		defer func() {
+			_ = os.RemoveAll(homePath)
+		}()
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), previewGenerationTimeout)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, "nvim", args...)
+	env = append(env, "MESSAGES_PATH="+messagesFilePath)
+	cmd, err := colorschemeSandbox.Command(ctx, homePath, env, command, args...)
+	if err != nil {
+		return repoHelper.ErrorOutput{}, err
+	}
 
 	log.Printf("Running %s (timeout: %s)", cmd, previewGenerationTimeout)
 
-	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
+	var stdout, stderr outputBuffer
+	cmd.Stderr = &stderr
+	if debugMode {
+		cmd.Stderr = io.MultiWriter(os.Stderr, &stderr)
+	}
 
-	err := cmd.Run()
+	// Vim draws its screen on stdout even with --not-a-term, only show it when debugging
+	if runner == repoHelper.NeovimRunner {
+		cmd.Stdin = os.Stdin
+		cmd.Stdout = io.MultiWriter(os.Stdout, &stdout)
+	} else if debugMode {
+		cmd.Stdin = os.Stdin
+		cmd.Stdout = os.Stdout
+	}
+
+	err = cmd.Run()
+	output := getEditorOutput(&stdout, &stderr)
 	if err != nil {
-		return wrapCommandError(ctx, "preview generation", err)
+		err = wrapCommandError(ctx, "preview generation", colorschemeSandbox.Violation(err), repoHelper.NvimTimeoutError, repoHelper.NvimCrashError)
+		return output, repoHelper.WithErrorOutput(err, output)
 	}
 
-	return nil
+	return output, nil
 }
 
 // Deletes the temporary directory used for runtime config
@@ -349,18 +899,40 @@ func cleanUp() {
 	}
 }
 
-func getGenerateData(repository repoHelper.Repository) database.GenerateData {
+func getGenerateData(repository repoHelper.Repository, generation repoHelper.Generation, nvimVersionResults []repoHelper.NvimVersionResult) database.GenerateData {
 	return database.GenerateData{
-		Colorschemes: repository.Colorschemes,
+		Colorschemes:       repository.Colorschemes,
+		NvimVersionResults: nvimVersionResults,
+		Generation:         generation,
+	}
+}
+
+// Returns true if the last generation of the repository was built from the
+// same commit with the same runner and toolchain, and records the skip
+func isRepositoryUnchanged(repository repoHelper.Repository, commitSHA string, runner repoHelper.Runner) bool {
+	generation, exists, err := database.GetLastRepositoryGeneration(repository.ID)
+	if err != nil {
+		log.Printf("Error getting the last generation: %s", err)
+		return false
+	}
+	if !exists || !generation.Matches(commitSHA, runner, toolchain) {
+		return false
+	}
+
+	log.Printf("Skipping, %s was already generated with the same toolchain", commitSHA)
+	if err := database.CreateRepositoryGenerateSkippedEvent(repository.ID, generation.ID); err != nil {
+		log.Printf("Error creating generate skipped event: %s", err)
 	}
+	return true
 }
 
-// wrapCommandError returns an error message that distinguishes timeout from other failures.
-func wrapCommandError(ctx context.Context, action string, err error) error {
+// wrapCommandError returns an error message that distinguishes timeout from
+// other failures, classified by the error code of each.
+func wrapCommandError(ctx context.Context, action string, err error, timeoutCode repoHelper.ErrorCode, failureCode repoHelper.ErrorCode) error {
 	if ctx.Err() == context.DeadlineExceeded {
-		return fmt.Errorf("%s timed out after %s", action, previewGenerationTimeout)
+		return repoHelper.NewGenerateError(timeoutCode, fmt.Errorf("%s timed out after %s", action, previewGenerationTimeout))
 	}
-	return fmt.Errorf("%s failed: %w", action, err)
+	return repoHelper.NewGenerateError(failureCode, fmt.Errorf("%s failed: %w", action, err))
 }
 
 func isDefaultColorscheme(name string) bool {
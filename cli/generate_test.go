@@ -1,6 +1,18 @@
 package cli
 
-import "testing"
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	file "github.com/vimcolorschemes/worker/internal/file"
+	repoHelper "github.com/vimcolorschemes/worker/internal/repository"
+)
 
 func TestIsDefaultColorscheme(t *testing.T) {
 	tests := []struct {
@@ -26,3 +38,199 @@ func TestIsDefaultColorscheme(t *testing.T) {
 		})
 	}
 }
+
+func TestReadOutputFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "links.json")
+	if err := os.WriteFile(path, []byte(`{"myscheme":{"dark":{"Comment":"Constant"},"light":null}}`), 0o644); err != nil {
+		t.Fatalf("WriteFile returned error: %v", err)
+	}
+
+	var links map[string]map[repoHelper.BackgroundValue]map[string]string
+	if err := readOutputFile(path, &links); err != nil {
+		t.Fatalf("readOutputFile returned error: %v", err)
+	}
+
+	expected := map[string]map[repoHelper.BackgroundValue]map[string]string{
+		"myscheme": {repoHelper.DarkBackground: {"Comment": "Constant"}, repoHelper.LightBackground: nil},
+	}
+	if !reflect.DeepEqual(links, expected) {
+		t.Fatalf("links = %#v, want %#v", links, expected)
+	}
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected %s to be removed, got %v", path, err)
+	}
+}
+
+func TestReadOutputFileErrorCodes(t *testing.T) {
+	directory := t.TempDir()
+	for content, expected := range map[string]repoHelper.ErrorCode{
+		"":           repoHelper.EmptyOutputError,
+		"\n":         repoHelper.EmptyOutputError,
+		`{"mytest":`: repoHelper.InvalidJSONError,
+	} {
+		path := filepath.Join(directory, "data.json")
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("WriteFile returned error: %v", err)
+		}
+
+		var data map[string]repoHelper.ColorschemeData
+		if code := repoHelper.ErrorCodeOf(readOutputFile(path, &data)); code != expected {
+			t.Errorf("readOutputFile(%q) code = %s, want %s", content, code, expected)
+		}
+	}
+
+	var data map[string]repoHelper.ColorschemeData
+	if code := repoHelper.ErrorCodeOf(readOutputFile(filepath.Join(directory, "missing.json"), &data)); code != repoHelper.EmptyOutputError {
+		t.Errorf("readOutputFile(missing) code = %s, want %s", code, repoHelper.EmptyOutputError)
+	}
+}
+
+func TestGetExtractError(t *testing.T) {
+	extractErrorFilePath = filepath.Join(t.TempDir(), "extract_error.txt")
+	if err := getExtractError(); err != nil {
+		t.Fatalf("getExtractError() = %v without an error file, want nil", err)
+	}
+
+	if err := os.WriteFile(extractErrorFilePath, []byte("colors/mytest.lua:3: module 'lush' not found\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile returned error: %v", err)
+	}
+	err := getExtractError()
+	if repoHelper.ErrorCodeOf(err) != repoHelper.LuaError || err.Error() != "extracting colors failed: colors/mytest.lua:3: module 'lush' not found" {
+		t.Fatalf("getExtractError() = %v, want a lua_error", err)
+	}
+	if _, err := os.Stat(extractErrorFilePath); !os.IsNotExist(err) {
+		t.Fatalf("expected %s to be removed, got %v", extractErrorFilePath, err)
+	}
+}
+
+func TestWrapCommandErrorCodes(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 0)
+	defer cancel()
+	<-ctx.Done()
+
+	err := wrapCommandError(ctx, "preview generation", errors.New("signal: killed"), repoHelper.NvimTimeoutError, repoHelper.NvimCrashError)
+	if repoHelper.ErrorCodeOf(err) != repoHelper.NvimTimeoutError {
+		t.Fatalf("wrapCommandError(timed out) = %v, want a nvim_timeout", err)
+	}
+
+	err = wrapCommandError(context.Background(), "preview generation", errors.New("exit status 1"), repoHelper.NvimTimeoutError, repoHelper.NvimCrashError)
+	if repoHelper.ErrorCodeOf(err) != repoHelper.NvimCrashError || err.Error() != "preview generation failed: exit status 1" {
+		t.Fatalf("wrapCommandError(failed) = %v, want a nvim_crash", err)
+	}
+}
+
+func TestGetColorschemeColorDataWithVim(t *testing.T) {
+	if _, err := exec.LookPath("vim"); err != nil {
+		t.Skip("vim is not installed")
+	}
+
+	workingDirectory, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Getwd returned error: %v", err)
+	}
+
+	tmpDirectoryPath = t.TempDir()
+	vimFilesPath = filepath.Join(workingDirectory, "..", "vim")
+	vimVimrcPath = filepath.Join(tmpDirectoryPath, "vimrc")
+	colorDataFilePath = filepath.Join(tmpDirectoryPath, "data.json")
+
+	colorscheme := "hi clear\nlet g:colors_name = 'mytest'\nset background=dark\nhi Normal guifg=#ebdbb2 guibg=#1d2021\nhi Comment guifg=Gray gui=italic\nhi link vimLet Statement\n"
+	colorschemePath := filepath.Join(tmpDirectoryPath, "pack", "plugins", "start", "mytest", "colors", "mytest.vim")
+	if err := file.WriteToFile(colorscheme, colorschemePath); err != nil {
+		t.Fatalf("WriteToFile returned error: %v", err)
+	}
+
+	setupVimRuntime()
+
+	data, err := getColorschemeColorData(repoHelper.VimRunner, codeSample{
+		language: repoHelper.DefaultLanguage,
+		path:     filepath.Join(vimFilesPath, "code_sample.vim"),
+	}, nil)
+	if err != nil {
+		t.Fatalf("getColorschemeColorData returned error: %v", err)
+	}
+
+	if _, ok := data["default"]; ok {
+		t.Fatal("built-in colorschemes should be skipped")
+	}
+	scheme, ok := data["mytest"]
+	if !ok {
+		t.Fatalf("data = %v, want mytest", data)
+	}
+	if scheme.Light != nil {
+		t.Fatal("Light groups should be empty for a dark only colorscheme")
+	}
+
+	for name, expected := range map[string]repoHelper.ColorschemeGroup{
+		"NormalBg":  {Name: "NormalBg", HexCode: "#1d2021"},
+		"CommentFg": {Name: "CommentFg", HexCode: "#bebebe", Italic: true},
+	} {
+		hexCode, ok := repoHelper.GroupHexCode(scheme.Dark, name)
+		if !ok || hexCode != expected.HexCode {
+			t.Errorf("%s = %q, want %q", name, hexCode, expected.HexCode)
+		}
+	}
+
+	for _, group := range scheme.Dark {
+		if group.Name == "vimLetFg" && group.Link != "Statement" {
+			t.Errorf("vimLetFg link = %q, want Statement", group.Link)
+		}
+	}
+}
+
+func TestExecutePreviewGeneratorOutputWithVim(t *testing.T) {
+	if _, err := exec.LookPath("vim"); err != nil {
+		t.Skip("vim is not installed")
+	}
+
+	workingDirectory, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Getwd returned error: %v", err)
+	}
+
+	tmpDirectoryPath = t.TempDir()
+	vimFilesPath = filepath.Join(workingDirectory, "..", "vim")
+	vimVimrcPath = filepath.Join(tmpDirectoryPath, "vimrc")
+	colorDataFilePath = filepath.Join(tmpDirectoryPath, "data.json")
+	messagesFilePath = filepath.Join(tmpDirectoryPath, "messages.json")
+
+	colorscheme := "hi clear\nlet g:colors_name = 'mytest'\nsilent! call NoSuchFunction()\nhi Normal guifg=#ebdbb2 guibg=#1d2021\n"
+	colorschemePath := filepath.Join(tmpDirectoryPath, "pack", "plugins", "start", "mytest", "colors", "mytest.vim")
+	if err := file.WriteToFile(colorscheme, colorschemePath); err != nil {
+		t.Fatalf("WriteToFile returned error: %v", err)
+	}
+
+	setupVimRuntime()
+
+	output, err := executePreviewGenerator(repoHelper.VimRunner, "vim", filepath.Join(vimFilesPath, "code_sample.vim"), []string{"COLOR_DATA_PATH=" + colorDataFilePath})
+	if err != nil {
+		t.Fatalf("executePreviewGenerator returned error: %v", err)
+	}
+
+	if output.Colorscheme != "mytest" {
+		t.Errorf("output.Colorscheme = %q, want mytest", output.Colorscheme)
+	}
+	if !strings.Contains(output.ErrorMessage, "E117") {
+		t.Errorf("output.ErrorMessage = %q, want the unknown function error", output.ErrorMessage)
+	}
+	if _, err := os.Stat(messagesFilePath); !os.IsNotExist(err) {
+		t.Fatalf("expected %s to be removed, got %v", messagesFilePath, err)
+	}
+}
+
+func TestGetDefaultRunner(t *testing.T) {
+	if _, err := exec.LookPath("vim"); err != nil {
+		t.Skip("vim is not installed")
+	}
+
+	t.Setenv("GENERATE_RUNNER", "vim")
+	if runner, err := getDefaultRunner(); err != nil || runner != repoHelper.VimRunner {
+		t.Errorf("getDefaultRunner() = %q, %v, want vim", runner, err)
+	}
+
+	t.Setenv("PATH", t.TempDir())
+	if _, err := getDefaultRunner(); err == nil {
+		t.Error("getDefaultRunner returned no error without vim installed")
+	}
+}
@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"log"
+
+	repoHelper "github.com/vimcolorschemes/worker/internal/repository"
+)
+
+// outputBuffer keeps the end of what a command writes, up to twice the
+// length of the stored excerpts
+type outputBuffer struct {
+	data []byte
+}
+
+func (buffer *outputBuffer) Write(p []byte) (int, error) {
+	buffer.data = append(buffer.data, p...)
+	if limit := 2 * repoHelper.MaxOutputExcerptLength; len(buffer.data) > limit {
+		buffer.data = buffer.data[len(buffer.data)-limit:]
+	}
+	return len(p), nil
+}
+
+func (buffer *outputBuffer) String() string {
+	return string(buffer.data)
+}
+
+// editorMessages is written to messagesFilePath by vim/init.lua or vim/vimrc
+// before the editor exits
+type editorMessages struct {
+	Colorscheme  string `json:"colorscheme"`
+	Messages     string `json:"messages"`
+	ErrorMessage string `json:"errorMessage"`
+}
+
+// Returns what the editor printed, completed with its :messages and v:errmsg
+func getEditorOutput(stdout *outputBuffer, stderr *outputBuffer) repoHelper.ErrorOutput {
+	output := repoHelper.ErrorOutput{
+		Stdout: stdout.String(),
+		Stderr: stderr.String(),
+	}
+
+	var messages editorMessages
+	if err := readOutputFile(messagesFilePath, &messages); err != nil {
+		// The editor didn't get to exit cleanly when it crashed or timed out
+		log.Printf("Error reading editor messages: %s", err)
+		return output
+	}
+
+	output.Colorscheme = messages.Colorscheme
+	output.Messages = messages.Messages
+	output.ErrorMessage = messages.ErrorMessage
+	return output
+}
@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/vimcolorschemes/worker/internal/dotenv"
+	"github.com/vimcolorschemes/worker/internal/export"
+	file "github.com/vimcolorschemes/worker/internal/file"
+	"github.com/vimcolorschemes/worker/internal/preview"
+	repoHelper "github.com/vimcolorschemes/worker/internal/repository"
+)
+
+// previewRenderer renders and stores the preview images of colorschemes
+type previewRenderer struct {
+	store     preview.Store
+	lines     []preview.Line
+	rasterize bool
+}
+
+// Returns a preview renderer configured from the environment, or nil if
+// PREVIEW_DIRECTORY is not set
+func newPreviewRenderer(codeSamplePath string) (*previewRenderer, error) {
+	directoryPath, exists := dotenv.Get("PREVIEW_DIRECTORY")
+	if !exists || directoryPath == "" {
+		return nil, nil
+	}
+
+	baseURL, _ := dotenv.Get("PREVIEW_BASE_URL")
+
+	rasterize := false
+	if value, exists := dotenv.Get("PREVIEW_PNG"); exists && value != "" {
+		var err error
+		rasterize, err = strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing PREVIEW_PNG to bool with value %s", value)
+		}
+	}
+
+	codeSample, err := file.GetLocalFileContent(codeSamplePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &previewRenderer{
+		store:     preview.DirectoryStore{Path: directoryPath, BaseURL: baseURL},
+		lines:     preview.TokenizeVim(codeSample),
+		rasterize: rasterize,
+	}, nil
+}
+
+// Renders every background the colorscheme was extracted for and returns the
+// stored previews
+func (renderer *previewRenderer) render(repository repoHelper.Repository, colorscheme repoHelper.Colorscheme) ([]repoHelper.Preview, error) {
+	var previews []repoHelper.Preview
+	for _, background := range colorscheme.Data.Backgrounds() {
+		theme, ok := preview.NewTheme(colorscheme.Data.Groups(background))
+		if !ok {
+			continue
+		}
+
+		fileName := fmt.Sprintf("%s-%s", export.FileName(colorscheme.Name), background)
+		contents := map[string][]byte{"svg": []byte(preview.RenderSVG(theme, renderer.lines))}
+		if renderer.rasterize {
+			content, err := preview.RenderPNG(theme, renderer.lines)
+			if err != nil {
+				return nil, fmt.Errorf("render %s png: %w", fileName, err)
+			}
+			contents["png"] = content
+		}
+
+		for _, format := range []string{"svg", "png"} {
+			content, ok := contents[format]
+			if !ok {
+				continue
+			}
+			url, err := renderer.store.Put(preview.Key(repository.Owner.Name, repository.Name, fileName, format), content)
+			if err != nil {
+				return nil, fmt.Errorf("store %s %s: %w", fileName, format, err)
+			}
+			previews = append(previews, repoHelper.Preview{Background: background, Format: format, URL: url})
+		}
+	}
+
+	log.Printf("Rendered %d previews for %s", len(previews), colorscheme.Name)
+	return previews, nil
+}
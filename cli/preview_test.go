@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/vimcolorschemes/worker/internal/preview"
+	repoHelper "github.com/vimcolorschemes/worker/internal/repository"
+)
+
+func TestPreviewRendererRender(t *testing.T) {
+	previewDirectoryPath := t.TempDir()
+	renderer := &previewRenderer{
+		store:     preview.DirectoryStore{Path: previewDirectoryPath, BaseURL: "https://previews.example.com"},
+		lines:     preview.TokenizeVim("let x = 1\n"),
+		rasterize: true,
+	}
+
+	previews, err := renderer.render(
+		repoHelper.Repository{Owner: repoHelper.Owner{Name: "owner"}, Name: "repo"},
+		repoHelper.Colorscheme{
+			Name: "myscheme",
+			Data: repoHelper.ColorschemeData{
+				Light: []repoHelper.ColorschemeGroup{{Name: "NormalFg", HexCode: "#000000"}},
+				Dark: []repoHelper.ColorschemeGroup{
+					{Name: "NormalBg", HexCode: "#000000"},
+					{Name: "NormalFg", HexCode: "#ffffff"},
+				},
+			},
+		},
+	)
+	if err != nil {
+		t.Fatalf("render returned error: %v", err)
+	}
+
+	expected := []repoHelper.Preview{
+		{Background: repoHelper.DarkBackground, Format: "svg", URL: "https://previews.example.com/owner/repo/myscheme-dark.svg"},
+		{Background: repoHelper.DarkBackground, Format: "png", URL: "https://previews.example.com/owner/repo/myscheme-dark.png"},
+	}
+	if len(previews) != len(expected) {
+		t.Fatalf("previews = %#v, want %#v", previews, expected)
+	}
+	for index := range expected {
+		if previews[index] != expected[index] {
+			t.Fatalf("previews[%d] = %#v, want %#v", index, previews[index], expected[index])
+		}
+	}
+
+	for _, name := range []string{"myscheme-dark.svg", "myscheme-dark.png"} {
+		path := filepath.Join(previewDirectoryPath, "owner", "repo", name)
+		if _, err := os.Stat(path); err != nil {
+			t.Fatalf("expected %s to exist: %v", path, err)
+		}
+	}
+}
@@ -120,13 +120,23 @@ func sendDailyJobSummary(ctx context.Context, publishResult map[string]interface
 		return fmt.Errorf("load generate event counts: %w", err)
 	}
 
+	generateErrorCodeCounts, err := database.CountRepositoryJobEventErrorCodes("generate", day)
+	if err != nil {
+		return fmt.Errorf("load generate error codes: %w", err)
+	}
+
 	generateErrorMessages, err := database.ListRepositoryJobEventMessages("generate", "error", day, 5)
 	if err != nil {
 		return fmt.Errorf("load generate event messages: %w", err)
 	}
 
+	repositoryChanges, err := database.ListRepositoryChanges(day, 10)
+	if err != nil {
+		return fmt.Errorf("load repository changes: %w", err)
+	}
+
 	subject := fmt.Sprintf("vimcolorschemes daily summary %s", day.Format("2006-01-02"))
-	body := buildDailyJobSummary(day, reports, publishResult, generateEventCounts, generateErrorMessages, frontendURL)
+	body := buildDailyJobSummary(day, reports, publishResult, generateEventCounts, generateErrorCodeCounts, generateErrorMessages, repositoryChanges, frontendURL)
 
 	if err := notify.PublishJobNotification(ctx, subject, body); err != nil {
 		return fmt.Errorf("send job summary notification: %w", err)
@@ -135,7 +145,7 @@ func sendDailyJobSummary(ctx context.Context, publishResult map[string]interface
 	return nil
 }
 
-func buildDailyJobSummary(day time.Time, reports map[string]database.JobReport, publishResult map[string]interface{}, generateEventCounts map[string]int, generateErrorMessages []string, frontendURL string) string {
+func buildDailyJobSummary(day time.Time, reports map[string]database.JobReport, publishResult map[string]interface{}, generateEventCounts map[string]int, generateErrorCodeCounts map[string]int, generateErrorMessages []string, repositoryChanges []database.RepositoryChange, frontendURL string) string {
 	var b strings.Builder
 
 	b.WriteString("vimcolorschemes — Daily Summary\n")
@@ -148,7 +158,10 @@ func buildDailyJobSummary(day time.Time, reports map[string]database.JobReport,
 
 	for _, job := range []string{"import", "update", "generate"} {
 		b.WriteString("\n")
-		writeJobSection(&b, job, reports[job], job == "generate", generateEventCounts, generateErrorMessages)
+		writeJobSection(&b, job, reports[job], job == "generate", generateEventCounts, generateErrorCodeCounts, generateErrorMessages)
+		if job == "generate" {
+			writeRepositoryChanges(&b, repositoryChanges)
+		}
 	}
 
 	b.WriteString("\n")
@@ -157,12 +170,12 @@ func buildDailyJobSummary(day time.Time, reports map[string]database.JobReport,
 		Status: "success",
 		Date:   day,
 		Data:   publishResult,
-	}, false, nil, nil)
+	}, false, nil, nil, nil)
 
 	return strings.TrimRight(b.String(), "\n")
 }
 
-func writeJobSection(b *strings.Builder, job string, report database.JobReport, includeGenerateEvents bool, generateEventCounts map[string]int, generateErrorMessages []string) {
+func writeJobSection(b *strings.Builder, job string, report database.JobReport, includeGenerateEvents bool, generateEventCounts map[string]int, generateErrorCodeCounts map[string]int, generateErrorMessages []string) {
 	b.WriteString(summaryDivider)
 	b.WriteString("\n")
 	b.WriteString(fmt.Sprintf("%s · %s\n", titleCase(job), sectionStatus(report)))
@@ -212,6 +225,9 @@ func writeJobSection(b *strings.Builder, job string, report database.JobReport,
 
 	if includeGenerateEvents {
 		rows = append(rows, [2]string{"Event errors", formatInt(int64(generateEventCounts["error"]))})
+		if len(generateErrorCodeCounts) > 0 {
+			rows = append(rows, [2]string{"Error codes", formatCountMap(generateErrorCodeCounts)})
+		}
 	}
 
 	writeSummaryRows(b, rows)
@@ -240,6 +256,18 @@ func writeJobSection(b *strings.Builder, job string, report database.JobReport,
 	}
 }
 
+func writeRepositoryChanges(b *strings.Builder, changes []database.RepositoryChange) {
+	if len(changes) == 0 {
+		return
+	}
+
+	b.WriteString("\n  Changed themes:\n")
+	for _, change := range changes {
+		key := fmt.Sprintf("%s/%s", change.OwnerName, change.Name)
+		b.WriteString(fmt.Sprintf("    - %s: %s (https://github.com/%s)\n", key, change.Summary, key))
+	}
+}
+
 func writeSummaryRows(b *strings.Builder, rows [][2]string) {
 	width := 0
 	for _, row := range rows {
@@ -355,6 +383,26 @@ func formatInterfaceMap(values map[string]interface{}) string {
 	return strings.Join(parts, ", ")
 }
 
+// Formats counts from the most frequent, e.g. "lua_error=3, nvim_timeout=1"
+func formatCountMap(values map[string]int) string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i int, j int) bool {
+		if values[keys[i]] != values[keys[j]] {
+			return values[keys[i]] > values[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+
+	parts := make([]string, 0, len(values))
+	for _, key := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%d", key, values[key]))
+	}
+	return strings.Join(parts, ", ")
+}
+
 func sortedMapKeys(values map[string]string) []string {
 	keys := make([]string, 0, len(values))
 	for key := range values {
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/vimcolorschemes/worker/internal/database"
+	repoHelper "github.com/vimcolorschemes/worker/internal/repository"
 )
 
 func TestValidatePublishPrerequisites(t *testing.T) {
@@ -141,7 +142,11 @@ func TestBuildDailyJobSummary(t *testing.T) {
 		},
 	}
 
-	body := buildDailyJobSummary(day, reports, publishResult, map[string]int{"error": 1}, []string{"clone failed"}, frontendURL)
+	changes := []database.RepositoryChange{
+		{OwnerName: "owner", Name: "theme", Summary: repoHelper.ChangeSummary{ChangedColorschemes: 1, ChangedGroups: 3}},
+	}
+
+	body := buildDailyJobSummary(day, reports, publishResult, map[string]int{"error": 3}, map[string]int{"clone_not_found": 1, "lua_error": 2}, []string{"clone failed"}, changes, frontendURL)
 
 	for _, want := range []string{
 		"vimcolorschemes — Daily Summary",
@@ -158,10 +163,13 @@ func TestBuildDailyJobSummary(t *testing.T) {
 		"- owner2/repo2 (https://github.com/owner2/repo2)",
 		"- owner3/repo3 (https://github.com/owner3/repo3)",
 		"Generate · success",
-		"Event errors:  1",
+		"Event errors:  3",
+		"Error codes:   lua_error=2, clone_not_found=1",
 		"Recent errors:",
 		"- clone failed",
 		"- owner/repo: boom",
+		"Changed themes:",
+		"- owner/theme: 3 groups changed (https://github.com/owner/theme)",
 		"Publish · success",
 		"Status code:   201",
 		"Webhook:       triggered",
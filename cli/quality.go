@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/vimcolorschemes/worker/internal/dotenv"
+	repoHelper "github.com/vimcolorschemes/worker/internal/repository"
+)
+
+// Returns the quality policy of the extracted colorschemes, defaulting to
+// repoHelper.DefaultQualityPolicy
+func getQualityPolicy() (repoHelper.QualityPolicy, error) {
+	policy := repoHelper.DefaultQualityPolicy
+
+	for _, setting := range []struct {
+		key   string
+		apply func(int)
+	}{
+		{"QUALITY_MIN_GROUPS", func(count int) { policy.MinGroupCount = count }},
+		{"QUALITY_MIN_COLORS", func(count int) { policy.MinColorCount = count }},
+	} {
+		if value, exists := dotenv.Get(setting.key); !exists || value == "" {
+			continue
+		}
+		value, err := dotenv.GetInt(setting.key)
+		if err != nil {
+			return repoHelper.QualityPolicy{}, err
+		}
+		setting.apply(value)
+	}
+
+	if value, exists := dotenv.Get("QUALITY_MIN_CONTRAST"); exists && value != "" {
+		ratio, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return repoHelper.QualityPolicy{}, fmt.Errorf("error parsing QUALITY_MIN_CONTRAST to float with value %s", value)
+		}
+		policy.MinContrastRatio = ratio
+	}
+
+	if value, exists := dotenv.Get("QUALITY_REQUIRED_GROUPS"); exists {
+		policy.RequiredGroups = nil
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				policy.RequiredGroups = append(policy.RequiredGroups, name)
+			}
+		}
+	}
+
+	return policy, nil
+}
+
+// Scores the colorscheme data, hiding the colorscheme when it fails
+func checkQuality(colorscheme repoHelper.Colorscheme) repoHelper.Colorscheme {
+	colorscheme.Quality = qualityPolicy.Check(colorscheme.Data)
+	colorscheme.Hidden = !colorscheme.Quality.Passed()
+	if colorscheme.Hidden {
+		log.Printf("Hiding %s: %s", colorscheme.Name, strings.Join(colorscheme.Quality.Reasons, "; "))
+	}
+	return colorscheme
+}
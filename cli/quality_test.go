@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"slices"
+	"testing"
+
+	repoHelper "github.com/vimcolorschemes/worker/internal/repository"
+)
+
+func TestGetQualityPolicy(t *testing.T) {
+	t.Setenv("QUALITY_MIN_GROUPS", "20")
+	t.Setenv("QUALITY_MIN_COLORS", "")
+	t.Setenv("QUALITY_MIN_CONTRAST", "2.5")
+	t.Setenv("QUALITY_REQUIRED_GROUPS", "NormalBg, CommentFg,")
+
+	policy, err := getQualityPolicy()
+	if err != nil {
+		t.Fatalf("getQualityPolicy returned error: %v", err)
+	}
+	if policy.MinGroupCount != 20 || policy.MinColorCount != repoHelper.DefaultQualityPolicy.MinColorCount || policy.MinContrastRatio != 2.5 {
+		t.Errorf("getQualityPolicy() = %+v, want 20 groups, the default colors and a 2.5 contrast", policy)
+	}
+	if !slices.Equal(policy.RequiredGroups, []string{"NormalBg", "CommentFg"}) {
+		t.Errorf("RequiredGroups = %v, want [NormalBg CommentFg]", policy.RequiredGroups)
+	}
+
+	t.Setenv("QUALITY_MIN_CONTRAST", "high")
+	if _, err := getQualityPolicy(); err == nil {
+		t.Error("getQualityPolicy returned no error for an invalid contrast")
+	}
+}
+
+func TestCheckQuality(t *testing.T) {
+	qualityPolicy = repoHelper.QualityPolicy{MinGroupCount: 2}
+
+	colorscheme := checkQuality(repoHelper.Colorscheme{
+		Name: "broken",
+		Data: repoHelper.ColorschemeData{Dark: []repoHelper.ColorschemeGroup{{Name: "NormalBg", HexCode: "#000000"}}},
+	})
+	if !colorscheme.Hidden || len(colorscheme.Quality.Reasons) != 1 {
+		t.Errorf("checkQuality() = %+v, want a hidden colorscheme with one reason", colorscheme)
+	}
+}
@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/vimcolorschemes/worker/internal/database"
+	"github.com/vimcolorschemes/worker/internal/dotenv"
+	repoHelper "github.com/vimcolorschemes/worker/internal/repository"
+)
+
+// Quarantine lists the repositories that stopped being generated after
+// failing too many times in a row
+func Quarantine(_force bool, _debug bool, _repoKey string) map[string]interface{} {
+	quarantined, err := database.GetQuarantinedRepositories()
+	if err != nil {
+		log.Panic(err)
+	}
+
+	log.Printf("%d quarantined repositories", len(quarantined))
+
+	reasonCounts := map[string]int{}
+	repositories := make([]string, 0, len(quarantined))
+	for _, entry := range quarantined {
+		key := fmt.Sprintf("%s/%s", entry.Repository.Owner.Name, entry.Repository.Name)
+		log.Printf(
+			"%s: %s after %d failures, quarantined at %s: %s",
+			key,
+			entry.Reason,
+			entry.FailureCount,
+			entry.QuarantinedAt.UTC().Format(time.DateTime),
+			entry.LastErrorMessage,
+		)
+		reasonCounts[string(entry.Reason)]++
+		repositories = append(repositories, fmt.Sprintf("%s (%s)", key, entry.Reason))
+	}
+
+	return map[string]interface{}{
+		"repositoryCount": len(quarantined),
+		"reasonCounts":    reasonCounts,
+		"repositories":    repositories,
+	}
+}
+
+// Logs the failure of a generate and records it following the retry policy,
+// returning true if the repository got quarantined
+func recordGenerateFailure(repository repoHelper.Repository, err error) bool {
+	if output := repoHelper.ErrorOutputOf(err); output.ErrorMessage != "" {
+		log.Printf("Last error message loading %q: %s", output.Colorscheme, output.ErrorMessage)
+	}
+
+	failure, eventErr := database.RecordRepositoryGenerateFailure(repository.ID, err, retryPolicy)
+	if eventErr != nil {
+		log.Printf("Error creating generate failure event: %s", eventErr)
+		return false
+	}
+
+	if failure.Quarantined {
+		log.Printf("Quarantined after %d consecutive failures (%s, %s)", failure.Count, repoHelper.ErrorCodeOf(err), failure.Reason)
+	} else {
+		log.Printf("Retrying after %s (%d consecutive failures, %s, %s)", failure.RetryAt.UTC().Format(time.DateTime), failure.Count, repoHelper.ErrorCodeOf(err), failure.Reason)
+	}
+	return failure.Quarantined
+}
+
+// Returns the retry policy of failing repositories, defaulting to
+// repoHelper.DefaultRetryPolicy
+func getRetryPolicy() (repoHelper.RetryPolicy, error) {
+	policy := repoHelper.DefaultRetryPolicy
+
+	for _, setting := range []struct {
+		key   string
+		apply func(int)
+	}{
+		{"GENERATE_RETRY_BASE_HOURS", func(hours int) { policy.BaseDelay = time.Duration(hours) * time.Hour }},
+		{"GENERATE_RETRY_MAX_HOURS", func(hours int) { policy.MaxDelay = time.Duration(hours) * time.Hour }},
+		{"GENERATE_QUARANTINE_AFTER", func(count int) { policy.QuarantineAfter = count }},
+	} {
+		if value, exists := dotenv.Get(setting.key); !exists || value == "" {
+			continue
+		}
+		value, err := dotenv.GetInt(setting.key)
+		if err != nil {
+			return repoHelper.RetryPolicy{}, err
+		}
+		setting.apply(value)
+	}
+
+	return policy, nil
+}
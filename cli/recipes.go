@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	file "github.com/vimcolorschemes/worker/internal/file"
+	repoHelper "github.com/vimcolorschemes/worker/internal/repository"
+)
+
+// Reads the extraction recipes keyed by repository, e.g. "morhetz/gruvbox",
+// from vim/recipes.json
+func getRecipes(vimFilesPath string) (map[string]repoHelper.Recipe, error) {
+	content, err := file.GetLocalFileContent(fmt.Sprintf("%s/recipes.json", vimFilesPath))
+	if err != nil {
+		return nil, err
+	}
+
+	var recipes map[string]repoHelper.Recipe
+	if err := json.Unmarshal([]byte(content), &recipes); err != nil {
+		return nil, fmt.Errorf("error parsing recipes.json: %w", err)
+	}
+
+	for key, recipe := range recipes {
+		names := map[string]bool{}
+		for _, variant := range recipe.Variants {
+			if variant.Name == "" || names[variant.Name] {
+				return nil, fmt.Errorf("recipes.json variants of %s need unique names", key)
+			}
+			if variant.Colorscheme == "" {
+				return nil, fmt.Errorf("recipes.json variant %s of %s needs a colorscheme", variant.Name, key)
+			}
+			names[variant.Name] = true
+		}
+	}
+
+	return recipes, nil
+}
+
+// Returns the recipe of the repository, empty when it has none
+func getRecipe(repository repoHelper.Repository) repoHelper.Recipe {
+	return recipes[fmt.Sprintf("%s/%s", repository.Owner.Name, repository.Name)]
+}
+
+// variantColorData is the data of the colorscheme a variant configures
+type variantColorData struct {
+	data          repoHelper.ColorschemeData
+	languages     map[string]repoHelper.ColorschemeData
+	pluginSupport []repoHelper.PluginSupport
+}
+
+// Extracts the data of the colorscheme of each variant of the recipe from the
+// code samples, keyed by variant name, like the main extraction. The variants
+// that fail, that don't extract their colorscheme, or that have no code for
+// the runner, which would only repeat the main data, are skipped.
+func getVariantColorData(runner repoHelper.Runner, samples []codeSample, recipe repoHelper.Recipe) map[string]variantColorData {
+	variantData := make(map[string]variantColorData, len(recipe.Variants))
+	for _, variant := range recipe.Variants {
+		if !variant.HasCode(runner) {
+			log.Printf("Skipping %s variant without code for the %s runner", variant.Name, runner)
+			continue
+		}
+
+		env := recipe.Env(&variant)
+		data, err := getColorschemeColorData(runner, samples[0], env)
+		if err != nil {
+			log.Printf("Error getting %s variant color data: %s", variant.Name, err)
+			continue
+		}
+		colorschemeData, ok := data[variant.Colorscheme]
+		if !ok {
+			log.Printf("Skipping %s variant without data for %s", variant.Name, variant.Colorscheme)
+			continue
+		}
+		extracted := variantColorData{data: colorschemeData}
+
+		if runner == repoHelper.NeovimRunner {
+			pluginSupport, err := getPluginSupport()
+			if err != nil {
+				log.Printf("Error getting %s variant plugin support: %s", variant.Name, err)
+			}
+			extracted.pluginSupport = pluginSupport[variant.Colorscheme]
+		}
+
+		for language, languageData := range getLanguageColorData(runner, samples[1:], env) {
+			if colorschemeData, ok := languageData[variant.Colorscheme]; ok {
+				if extracted.languages == nil {
+					extracted.languages = map[string]repoHelper.ColorschemeData{}
+				}
+				extracted.languages[language] = colorschemeData
+			}
+		}
+
+		variantData[variant.Name] = extracted
+	}
+	return variantData
+}
@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	file "github.com/vimcolorschemes/worker/internal/file"
+	repoHelper "github.com/vimcolorschemes/worker/internal/repository"
+)
+
+func TestGetRecipes(t *testing.T) {
+	t.Run("reads vim/recipes.json", func(t *testing.T) {
+		recipes, err := getRecipes(filepath.Join("..", "vim"))
+		if err != nil {
+			t.Fatalf("getRecipes returned error: %v", err)
+		}
+		if len(recipes["morhetz/gruvbox"].Variants) == 0 {
+			t.Fatalf("recipes = %v, want the variants of morhetz/gruvbox", recipes)
+		}
+	})
+
+	t.Run("rejects variants without unique names", func(t *testing.T) {
+		directory := t.TempDir()
+		content := `{"owner/repo": {"variants": [{"name": "hard"}, {"name": "hard"}]}}`
+		if err := os.WriteFile(filepath.Join(directory, "recipes.json"), []byte(content), 0o644); err != nil {
+			t.Fatalf("WriteFile returned error: %v", err)
+		}
+		if _, err := getRecipes(directory); err == nil {
+			t.Fatal("getRecipes should reject duplicate variant names")
+		}
+	})
+
+	t.Run("rejects variants without a colorscheme", func(t *testing.T) {
+		directory := t.TempDir()
+		content := `{"owner/repo": {"variants": [{"name": "hard", "vim": "let g:contrast = 'hard'"}]}}`
+		if err := os.WriteFile(filepath.Join(directory, "recipes.json"), []byte(content), 0o644); err != nil {
+			t.Fatalf("WriteFile returned error: %v", err)
+		}
+		if _, err := getRecipes(directory); err == nil {
+			t.Fatal("getRecipes should reject variants without a colorscheme")
+		}
+	})
+}
+
+func TestGetVariantColorDataWithVim(t *testing.T) {
+	if _, err := exec.LookPath("vim"); err != nil {
+		t.Skip("vim is not installed")
+	}
+
+	workingDirectory, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Getwd returned error: %v", err)
+	}
+
+	tmpDirectoryPath = t.TempDir()
+	vimFilesPath = filepath.Join(workingDirectory, "..", "vim")
+	vimVimrcPath = filepath.Join(tmpDirectoryPath, "vimrc")
+	colorDataFilePath = filepath.Join(tmpDirectoryPath, "data.json")
+
+	colorscheme := "hi clear\nlet g:colors_name = 'mytest'\nset background=dark\nexecute 'hi Normal guibg=' . (get(g:, 'mytest_contrast', '') ==# 'hard' ? '#1d2021' : '#282828')\n"
+	colorschemePath := filepath.Join(tmpDirectoryPath, "pack", "plugins", "start", "mytest", "colors", "mytest.vim")
+	if err := file.WriteToFile(colorscheme, colorschemePath); err != nil {
+		t.Fatalf("WriteToFile returned error: %v", err)
+	}
+
+	setupVimRuntime()
+
+	recipe := repoHelper.Recipe{
+		Variants: []repoHelper.RecipeVariant{
+			{Name: "hard", Colorscheme: "mytest", Vim: "let g:mytest_contrast = 'hard'"},
+			{Name: "soft", Colorscheme: "mytest", Lua: "vim.g.mytest_contrast = 'soft'"},
+			{Name: "other", Colorscheme: "missing", Vim: "let g:mytest_contrast = 'hard'"},
+		},
+	}
+	variantData := getVariantColorData(repoHelper.VimRunner, []codeSample{{
+		language: repoHelper.DefaultLanguage,
+		path:     filepath.Join(vimFilesPath, "code_sample.vim"),
+	}}, recipe)
+
+	hexCode, ok := repoHelper.GroupHexCode(variantData["hard"].data.Dark, "NormalBg")
+	if !ok || hexCode != "#1d2021" {
+		t.Fatalf("hard NormalBg = %q, want #1d2021 from the variant code", hexCode)
+	}
+	if _, exists := variantData["soft"]; exists {
+		t.Fatal("the Lua only soft variant should be skipped by the vim runner")
+	}
+	if _, exists := variantData["other"]; exists {
+		t.Fatal("the variant of a colorscheme the repository doesn't have should be skipped")
+	}
+}
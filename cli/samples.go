@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/vimcolorschemes/worker/internal/dotenv"
+	repoHelper "github.com/vimcolorschemes/worker/internal/repository"
+)
+
+// codeSample is a file opened in nvim to extract the groups its language triggers
+type codeSample struct {
+	language string
+	path     string
+}
+
+// Returns the code samples to extract, relative to the vim files directory
+// unless configured with CODE_SAMPLES, e.g. "vim=./vim/code_sample.vim,lua=./samples/main.lua".
+// The DefaultLanguage sample always comes first.
+func getCodeSamples(vimFilesPath string) ([]codeSample, error) {
+	value, exists := dotenv.Get("CODE_SAMPLES")
+	if !exists || strings.TrimSpace(value) == "" {
+		return []codeSample{
+			{language: repoHelper.DefaultLanguage, path: fmt.Sprintf("%s/code_sample.vim", vimFilesPath)},
+			{language: "lua", path: fmt.Sprintf("%s/samples/code_sample.lua", vimFilesPath)},
+			{language: "python", path: fmt.Sprintf("%s/samples/code_sample.py", vimFilesPath)},
+			{language: "typescript", path: fmt.Sprintf("%s/samples/code_sample.ts", vimFilesPath)},
+			{language: "rust", path: fmt.Sprintf("%s/samples/code_sample.rs", vimFilesPath)},
+		}, nil
+	}
+
+	return parseCodeSamples(value)
+}
+
+func parseCodeSamples(value string) ([]codeSample, error) {
+	var samples []codeSample
+	languages := map[string]bool{}
+	for _, entry := range strings.Split(value, ",") {
+		language, path, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		language, path = strings.TrimSpace(language), strings.TrimSpace(path)
+		if !ok || language == "" || path == "" {
+			return nil, fmt.Errorf("invalid code sample %q, expected language=path", entry)
+		}
+		if languages[language] {
+			return nil, fmt.Errorf("duplicate code sample language %q", language)
+		}
+		languages[language] = true
+
+		sample := codeSample{language: language, path: path}
+		if language == repoHelper.DefaultLanguage {
+			samples = append([]codeSample{sample}, samples...)
+		} else {
+			samples = append(samples, sample)
+		}
+	}
+
+	if !languages[repoHelper.DefaultLanguage] {
+		return nil, fmt.Errorf("missing %s code sample", repoHelper.DefaultLanguage)
+	}
+
+	return samples, nil
+}
@@ -0,0 +1,35 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCodeSamples(t *testing.T) {
+	t.Run("puts the vim sample first", func(t *testing.T) {
+		samples, err := parseCodeSamples("lua=./samples/main.lua, vim=./vim/code_sample.vim")
+		if err != nil {
+			t.Fatalf("parseCodeSamples returned error: %v", err)
+		}
+		expected := []codeSample{
+			{language: "vim", path: "./vim/code_sample.vim"},
+			{language: "lua", path: "./samples/main.lua"},
+		}
+		if !reflect.DeepEqual(samples, expected) {
+			t.Fatalf("samples = %#v, want %#v", samples, expected)
+		}
+	})
+
+	for name, value := range map[string]string{
+		"rejects entries without a path":     "vim=./vim/code_sample.vim,lua",
+		"rejects duplicate languages":        "vim=./a.vim,vim=./b.vim",
+		"requires the vim sample":            "lua=./samples/main.lua",
+		"rejects entries without a language": "=./vim/code_sample.vim",
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := parseCodeSamples(value); err == nil {
+				t.Fatalf("parseCodeSamples(%q) returned no error", value)
+			}
+		})
+	}
+}
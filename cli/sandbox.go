@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/vimcolorschemes/worker/internal/dotenv"
+	"github.com/vimcolorschemes/worker/internal/sandbox"
+)
+
+const defaultSandboxMemoryMegabytes = 2048
+const defaultSandboxFileSizeMegabytes = 64
+
+// Returns the sandbox config of the colorscheme code runs, limits default to
+// the preview generation timeout of CPU time, 2 GB of memory and 64 MB files.
+// Namespaces are enabled by default and hide the worker directory, with its
+// .env file, except for the vim files and the temporary directory.
+func getSandboxConfig() (sandbox.Config, error) {
+	cpuSeconds, err := getSandboxInt("SANDBOX_CPU_SECONDS", int(previewGenerationTimeout/time.Second))
+	if err != nil {
+		return sandbox.Config{}, err
+	}
+
+	memoryMegabytes, err := getSandboxInt("SANDBOX_MEMORY_MB", defaultSandboxMemoryMegabytes)
+	if err != nil {
+		return sandbox.Config{}, err
+	}
+
+	fileSizeMegabytes, err := getSandboxInt("SANDBOX_FILE_SIZE_MB", defaultSandboxFileSizeMegabytes)
+	if err != nil {
+		return sandbox.Config{}, err
+	}
+
+	namespaces := true
+	if value, exists := dotenv.Get("SANDBOX_NAMESPACES"); exists && value != "" {
+		namespaces, err = strconv.ParseBool(value)
+		if err != nil {
+			return sandbox.Config{}, fmt.Errorf("error parsing SANDBOX_NAMESPACES to bool with value %s", value)
+		}
+	}
+
+	return sandbox.Config{
+		CPUTime:       time.Duration(cpuSeconds) * time.Second,
+		MemoryBytes:   int64(memoryMegabytes) << 20,
+		FileSizeBytes: int64(fileSizeMegabytes) << 20,
+		Namespaces:    namespaces,
+		HiddenPaths:   []string{workingDirectoryPath},
+		ReadOnlyPaths: []string{vimFilesPath},
+		WritablePaths: []string{tmpDirectoryPath},
+	}, nil
+}
+
+// Returns the int value of key, or defaultValue when it's not set. 0 disables
+// the limit.
+func getSandboxInt(key string, defaultValue int) (int, error) {
+	if value, exists := dotenv.Get(key); !exists || value == "" {
+		return defaultValue, nil
+	}
+	return dotenv.GetInt(key)
+}
@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/vimcolorschemes/worker/internal/database"
+	repoHelper "github.com/vimcolorschemes/worker/internal/repository"
+	"github.com/vimcolorschemes/worker/internal/scan"
+)
+
+// errNoColors rejects a repository before running any of its code
+var errNoColors = errors.New("no colors/*.vim or colors/*.lua files")
+
+// Scans the installed repository and stores the result. A repository without
+// colors/ files is a no_colors error.
+func scanRepository(repository repoHelper.Repository, key string, commitSHA string) (scan.Result, error) {
+	result, err := scan.Scan(fmt.Sprintf("%s/%s", packDirectoryPath, key))
+	if err != nil {
+		return scan.Result{}, err
+	}
+	log.Printf("Scanned %s", result)
+
+	if err := database.UpdateRepositoryScan(repository.ID, commitSHA, result); err != nil {
+		log.Printf("Error storing repository scan: %s", err)
+	}
+
+	if !result.HasColorschemes() {
+		return result, repoHelper.NewGenerateError(repoHelper.NoColorsError, errNoColors)
+	}
+	return result, nil
+}
+
+// Completes an extraction error with what the scan found in the repository,
+// keeping its code and output
+func withScanHint(err error, result scan.Result) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("%w (%s)", err, result)
+}
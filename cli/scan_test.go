@@ -0,0 +1,33 @@
+package cli
+
+import (
+	"errors"
+	"testing"
+
+	repoHelper "github.com/vimcolorschemes/worker/internal/repository"
+	"github.com/vimcolorschemes/worker/internal/scan"
+)
+
+func TestWithScanHint(t *testing.T) {
+	if err := withScanHint(nil, scan.Result{}); err != nil {
+		t.Fatalf("withScanHint(nil) = %v, want nil", err)
+	}
+
+	output := repoHelper.ErrorOutput{ErrorMessage: "module 'lush' not found"}
+	dataError := repoHelper.WithErrorOutput(repoHelper.NewGenerateError(repoHelper.LuaError, errors.New("extraction failed")), output)
+	err := withScanHint(dataError, scan.Result{
+		Files:        []string{"colors/theme.lua"},
+		Frameworks:   []scan.Framework{scan.LushFramework},
+		Dependencies: []string{"lush"},
+	})
+
+	if expected := "extraction failed (1 colors/ files, uses lush, requires lush)"; err.Error() != expected {
+		t.Errorf("withScanHint() = %q, want %q", err.Error(), expected)
+	}
+	if code := repoHelper.ErrorCodeOf(err); code != repoHelper.LuaError {
+		t.Errorf("ErrorCodeOf() = %s, want %s", code, repoHelper.LuaError)
+	}
+	if got := repoHelper.ErrorOutputOf(err); got != output {
+		t.Errorf("ErrorOutputOf() = %+v, want %+v", got, output)
+	}
+}
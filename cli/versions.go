@@ -0,0 +1,170 @@
+package cli
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/vimcolorschemes/worker/internal/dependencies"
+	"github.com/vimcolorschemes/worker/internal/dotenv"
+	repoHelper "github.com/vimcolorschemes/worker/internal/repository"
+)
+
+// nvimBinary is an nvim binary of the version matrix
+type nvimBinary struct {
+	path    string
+	version string
+}
+
+// Returns the nvim binaries listed in NVIM_VERSION_MATRIX, as comma
+// separated paths, skipping the ones whose version can't be read
+func getNvimVersionMatrix() []nvimBinary {
+	value, exists := dotenv.Get("NVIM_VERSION_MATRIX")
+	if !exists {
+		return nil
+	}
+
+	var binaries []nvimBinary
+	for _, path := range strings.Split(value, ",") {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+
+		version, err := getEditorVersion(path)
+		if err != nil {
+			log.Printf("Skipping %s from the version matrix: %s", path, err)
+			continue
+		}
+
+		log.Printf("Adding %s (%s) to the version matrix", path, version)
+		binaries = append(binaries, nvimBinary{path: path, version: version})
+	}
+	return binaries
+}
+
+// Returns the first line of `nvim --version` or `vim --version`, e.g.
+// "NVIM v0.10.2"
+func getEditorVersion(path string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), previewGenerationTimeout)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, path, "--version").Output()
+	if err != nil {
+		return "", wrapCommandError(ctx, "reading version", err, repoHelper.NvimTimeoutError, repoHelper.NvimCrashError)
+	}
+
+	version, _, _ := strings.Cut(strings.TrimSpace(string(output)), "\n")
+	if version == "" {
+		return "", fmt.Errorf("empty version output")
+	}
+	return strings.TrimSpace(version), nil
+}
+
+// Returns the toolchain of the run from the commits of the installed locked
+// plugins and dependencies, and the versions of the installed editors
+func getToolchain(pluginCommits map[string]string, dependencyCommits map[string]string) repoHelper.Toolchain {
+	toolchain := repoHelper.Toolchain{
+		ExtractorSHA:      pluginCommits["extractor.nvim"],
+		LushSHA:           pluginCommits["lush.nvim"],
+		DependencyCommits: dependencyCommits,
+	}
+
+	for _, editor := range []struct {
+		command string
+		version *string
+	}{
+		{"nvim", &toolchain.NvimVersion},
+		{"vim", &toolchain.VimVersion},
+	} {
+		version, err := getEditorVersion(editor.command)
+		if err != nil {
+			log.Printf("Error reading the %s version: %s", editor.command, err)
+			continue
+		}
+		*editor.version = version
+	}
+
+	inputsHash, err := getInputsHash(vimFilesPath, codeSamples)
+	if err != nil {
+		log.Printf("Error hashing the extraction inputs: %s", err)
+	}
+	toolchain.InputsHash = inputsHash
+
+	log.Printf("Generating with %+v", toolchain)
+	return toolchain
+}
+
+// Returns a checksum of the files the colorschemes are extracted with: the vim
+// files (runtime, recipes.json, bundled samples), the configured code samples,
+// which may live elsewhere, and the dependency table
+func getInputsHash(vimFilesPath string, samples []codeSample) (string, error) {
+	hash := sha256.New()
+
+	err := filepath.WalkDir(vimFilesPath, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		relativePath, err := filepath.Rel(vimFilesPath, path)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(hash, "%s\x00%d\x00", filepath.ToSlash(relativePath), len(content))
+		hash.Write(content)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	for _, sample := range samples {
+		content, err := os.ReadFile(sample.path)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(hash, "sample:%s\x00%d\x00", sample.language, len(content))
+		hash.Write(content)
+	}
+
+	fmt.Fprintf(hash, "dependencies:%s", dependencies.Checksum())
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// Extracts the main code sample with every binary of the version matrix, with
+// the environment of the main extraction, and compares the data to it
+func runNvimVersionMatrix(binaries []nvimBinary, sample codeSample, recipeEnv []string, baseline map[string]repoHelper.ColorschemeData) []repoHelper.NvimVersionResult {
+	results := make([]repoHelper.NvimVersionResult, 0, len(binaries))
+	for _, binary := range binaries {
+		result := repoHelper.NvimVersionResult{Version: binary.version, BinaryPath: binary.path}
+
+		data, err := extractColorData(repoHelper.NeovimRunner, binary.path, fmt.Sprintf("%s/data.matrix.json", tmpDirectoryPath), sample, recipeEnv)
+		if err != nil {
+			log.Printf("Error extracting with %s: %s", binary.version, err)
+			result.Error = err.Error()
+			result.ErrorCode = repoHelper.ErrorCodeOf(err)
+			result.ErrorOutput = repoHelper.ErrorOutputOf(err)
+			results = append(results, result)
+			continue
+		}
+
+		result.Success = true
+		result.ColorschemeCount = len(data)
+		result.Diff = repoHelper.DiffData(baseline, data)
+		if !result.Diff.IsEmpty() {
+			log.Printf("Extraction with %s differs", binary.version)
+		}
+		results = append(results, result)
+	}
+	return results
+}
@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeFakeNvim(t *testing.T, script string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "nvim")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0o755); err != nil {
+		t.Fatalf("write fake nvim: %v", err)
+	}
+	return path
+}
+
+func TestGetNvimVersionMatrix(t *testing.T) {
+	stable := writeFakeNvim(t, "printf 'NVIM v0.10.4\\nBuild type: Release\\n'\n")
+	broken := writeFakeNvim(t, "exit 1\n")
+
+	t.Setenv("NVIM_VERSION_MATRIX", stable+", "+broken+",,"+filepath.Join(t.TempDir(), "missing"))
+
+	binaries := getNvimVersionMatrix()
+	expected := []nvimBinary{{path: stable, version: "NVIM v0.10.4"}}
+	if !reflect.DeepEqual(binaries, expected) {
+		t.Fatalf("binaries = %#v, want %#v", binaries, expected)
+	}
+}
+
+func TestRunNvimVersionMatrix(t *testing.T) {
+	broken := writeFakeNvim(t, "exit 1\n")
+
+	results := runNvimVersionMatrix([]nvimBinary{{path: broken, version: "NVIM v0.9.5"}}, codeSample{language: "vim", path: "./vim/code_sample.vim"}, nil, nil)
+	if len(results) != 1 {
+		t.Fatalf("len(results) = %d, want 1", len(results))
+	}
+	if results[0].Success || results[0].Error == "" {
+		t.Fatalf("result = %#v, want a failure with an error", results[0])
+	}
+	if results[0].Version != "NVIM v0.9.5" || results[0].BinaryPath != broken {
+		t.Fatalf("result = %#v, want the binary version and path", results[0])
+	}
+}
+
+func TestGetInputsHash(t *testing.T) {
+	vimFiles := t.TempDir()
+	writeFile := func(path string, content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", path, err)
+		}
+	}
+	writeFile(filepath.Join(vimFiles, "recipes.json"), "{}")
+	samplePath := filepath.Join(t.TempDir(), "main.lua")
+	writeFile(samplePath, "local x = 1")
+	samples := []codeSample{{language: "lua", path: samplePath}}
+
+	hash := func() string {
+		t.Helper()
+		value, err := getInputsHash(vimFiles, samples)
+		if err != nil {
+			t.Fatalf("getInputsHash returned error: %v", err)
+		}
+		return value
+	}
+
+	initial := hash()
+	if initial == "" || hash() != initial {
+		t.Fatalf("getInputsHash = %q, want a stable checksum", initial)
+	}
+
+	writeFile(filepath.Join(vimFiles, "recipes.json"), `{"tokyonight": {}}`)
+	withRecipe := hash()
+	if withRecipe == initial {
+		t.Fatal("getInputsHash did not change with recipes.json")
+	}
+
+	writeFile(samplePath, "local x = 2")
+	if hash() == withRecipe {
+		t.Fatal("getInputsHash did not change with the code sample")
+	}
+
+	if _, err := getInputsHash(vimFiles, []codeSample{{language: "lua", path: filepath.Join(vimFiles, "missing.lua")}}); err == nil {
+		t.Fatal("getInputsHash returned no error for a missing code sample")
+	}
+}
@@ -16,10 +16,12 @@ import (
 type jobRunner func(force bool, debug bool, repoKey string) map[string]interface{}
 
 var jobRunnerMap = map[string]jobRunner{
-	"import":   cli.Import,
-	"update":   cli.Update,
-	"generate": cli.Generate,
-	"publish":  cli.Publish,
+	"import":     cli.Import,
+	"update":     cli.Update,
+	"generate":   cli.Generate,
+	"publish":    cli.Publish,
+	"export":     cli.Export,
+	"quarantine": cli.Quarantine,
 }
 
 func main() {
@@ -167,3 +167,15 @@ func TestJobRunnerMapIncludesPublish(t *testing.T) {
 		t.Fatal("jobRunnerMap[\"publish\"] = nil, want runner")
 	}
 }
+
+func TestJobRunnerMapIncludesExport(t *testing.T) {
+	if jobRunnerMap["export"] == nil {
+		t.Fatal("jobRunnerMap[\"export\"] = nil, want runner")
+	}
+}
+
+func TestJobRunnerMapIncludesQuarantine(t *testing.T) {
+	if jobRunnerMap["quarantine"] == nil {
+		t.Fatal("jobRunnerMap[\"quarantine\"] = nil, want runner")
+	}
+}
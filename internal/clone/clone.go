@@ -0,0 +1,232 @@
+// Package clone installs git repositories with shallow single branch clones,
+// cached as bare repositories between runs.
+package clone
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Git config key of the pushed_at date a cached repository was fetched at
+const pushedAtConfigKey = "vimcolorschemes.pushedAt"
+
+// Interval the size of a clone is checked at while git downloads it
+var sizeCheckInterval = 100 * time.Millisecond
+
+// Cloner clones repositories
+type Cloner struct {
+	// CachePath is the directory of the cached bare repositories, repositories
+	// aren't cached when empty
+	CachePath string
+	// MaxSize in bytes of a repository, not enforced when 0
+	MaxSize int64
+}
+
+// Clone installs the last commit of the default branch of the repository in
+// target. With a cache, the bare repository cached under key is only fetched
+// when pushedAt changed since the last fetch.
+func (c Cloner) Clone(ctx context.Context, key string, url string, pushedAt time.Time, target string) error {
+	if c.CachePath == "" {
+		if err := c.gitWithinSize(ctx, target, "", "clone", "--quiet", "--depth", "1", "--single-branch", url, target); err != nil {
+			return err
+		}
+		return c.checkSize(target)
+	}
+
+	cachePath := filepath.Join(c.CachePath, key+".git")
+	if err := c.updateCache(ctx, cachePath, url, pushedAt); err != nil {
+		return err
+	}
+
+	return git(ctx, "", "clone", "--quiet", "--shared", cachePath, target)
+}
+
+func (c Cloner) updateCache(ctx context.Context, cachePath string, url string, pushedAt time.Time) error {
+	if _, err := os.Stat(cachePath); os.IsNotExist(err) {
+		if err := c.gitWithinSize(ctx, cachePath, "", "clone", "--quiet", "--bare", "--depth", "1", "--single-branch", url, cachePath); err != nil {
+			_ = os.RemoveAll(cachePath)
+			return err
+		}
+	} else if err != nil {
+		return err
+	} else {
+		fetchedAt, _ := gitOutput(ctx, cachePath, "config", "--get", pushedAtConfigKey)
+		if fetchedAt == strconv.FormatInt(pushedAt.Unix(), 10) {
+			return nil
+		}
+
+		branch, err := gitOutput(ctx, cachePath, "symbolic-ref", "HEAD")
+		if err != nil {
+			return err
+		}
+		if err := c.gitWithinSize(ctx, cachePath, cachePath, "fetch", "--quiet", "--depth", "1", "--force", url, "+HEAD:"+branch); err != nil {
+			_ = os.RemoveAll(cachePath)
+			return err
+		}
+		// Drop the objects of the previous commits
+		if err := git(ctx, cachePath, "gc", "--quiet", "--prune=now"); err != nil {
+			return err
+		}
+	}
+
+	if err := c.checkSize(cachePath); err != nil {
+		_ = os.RemoveAll(cachePath)
+		return err
+	}
+
+	return git(ctx, cachePath, "config", pushedAtConfigKey, strconv.FormatInt(pushedAt.Unix(), 10))
+}
+
+// ClonePinned installs commit of the repository in target
+func (c Cloner) ClonePinned(ctx context.Context, url string, commit string, target string) error {
+	if err := os.MkdirAll(target, 0o755); err != nil {
+		return err
+	}
+
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"fetch", "--quiet", "--depth", "1", url, commit},
+		{"checkout", "--quiet", "FETCH_HEAD"},
+	} {
+		if err := c.gitWithinSize(ctx, target, target, args...); err != nil {
+			return err
+		}
+	}
+
+	return c.checkSize(target)
+}
+
+// Head returns the commit SHA checked out in the repository at path
+func Head(ctx context.Context, path string) (string, error) {
+	return gitOutput(ctx, path, "rev-parse", "HEAD")
+}
+
+// Runs git in directory like git, stopping it as soon as the files under path
+// grow over the size limit, so an oversized repository isn't downloaded whole
+func (c Cloner) gitWithinSize(ctx context.Context, path string, directory string, args ...string) error {
+	return c.runWithinSize(ctx, path, func(ctx context.Context) error {
+		return git(ctx, directory, args...)
+	})
+}
+
+func (c Cloner) runWithinSize(ctx context.Context, path string, run func(ctx context.Context) error) error {
+	if c.MaxSize <= 0 {
+		return run(ctx)
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	sizeErr := make(chan error, 1)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(sizeCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				// The files of a running git come and go, only the size counts
+				if size, _ := Size(path); size > c.MaxSize {
+					sizeErr <- fmt.Errorf("repository grew to %d bytes while downloading, over the %d bytes limit", size, c.MaxSize)
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+
+	err := run(runCtx)
+	select {
+	case limitErr := <-sizeErr:
+		return limitErr
+	default:
+		return err
+	}
+}
+
+func (c Cloner) checkSize(path string) error {
+	if c.MaxSize <= 0 {
+		return nil
+	}
+
+	size, err := Size(path)
+	if err != nil {
+		return err
+	}
+	if size > c.MaxSize {
+		return fmt.Errorf("repository is %d bytes, over the %d bytes limit", size, c.MaxSize)
+	}
+	return nil
+}
+
+// Size returns the size in bytes of the files under path
+func Size(path string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(path, func(_ string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.Type().IsRegular() {
+			info, err := entry.Info()
+			if err != nil {
+				return err
+			}
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
+
+func git(ctx context.Context, directory string, args ...string) error {
+	_, err := gitOutput(ctx, directory, args...)
+	return err
+}
+
+// IsNotFound returns true if the error is git failing to find the repository,
+// deleted, renamed to a private one, or never existing
+func IsNotFound(err error) bool {
+	if err == nil {
+		return false
+	}
+	message := strings.ToLower(err.Error())
+	for _, pattern := range []string{"repository not found", "could not read username", "does not appear to be a git repository", "does not exist"} {
+		if strings.Contains(message, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// Runs git in directory, the error includes the message git printed
+func gitOutput(ctx context.Context, directory string, args ...string) (string, error) {
+	if directory != "" {
+		args = append([]string{"-C", directory}, args...)
+	}
+
+	cmd := exec.CommandContext(ctx, "git", args...)
+	// Never prompt for the credentials of a deleted or private repository
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return "", fmt.Errorf("%w: %s", err, message)
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
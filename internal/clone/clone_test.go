@@ -0,0 +1,156 @@
+package clone
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Creates a repository with a colors/ file and returns its file:// URL
+func setupUpstream(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	path := t.TempDir()
+	runGit(t, path, "init", "--quiet", "--initial-branch", "main")
+	commitFile(t, path, "colors/mytest.vim", "hi Normal guifg=#ffffff")
+	return "file://" + path
+}
+
+func commitFile(t *testing.T, repositoryPath string, name string, content string) {
+	t.Helper()
+	path := filepath.Join(repositoryPath, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, repositoryPath, "add", "-A")
+	runGit(t, repositoryPath, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "-m", name)
+}
+
+func runGit(t *testing.T, directory string, args ...string) string {
+	t.Helper()
+	output, err := gitOutput(context.Background(), directory, args...)
+	if err != nil {
+		t.Fatalf("git %v: %v", args, err)
+	}
+	return output
+}
+
+func TestClone(t *testing.T) {
+	upstream := setupUpstream(t)
+	upstreamPath := strings.TrimPrefix(upstream, "file://")
+	cloner := Cloner{CachePath: t.TempDir()}
+	pushedAt := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	clone := func(pushedAt time.Time) string {
+		t.Helper()
+		target := filepath.Join(t.TempDir(), "mytest")
+		if err := cloner.Clone(context.Background(), "42", upstream, pushedAt, target); err != nil {
+			t.Fatalf("Clone returned error: %v", err)
+		}
+		head, err := Head(context.Background(), target)
+		if err != nil {
+			t.Fatalf("Head returned error: %v", err)
+		}
+		return head
+	}
+
+	first := runGit(t, upstreamPath, "rev-parse", "HEAD")
+	if head := clone(pushedAt); head != first {
+		t.Fatalf("head = %s, want %s", head, first)
+	}
+
+	commitFile(t, upstreamPath, "colors/mytest.lua", "vim.cmd('hi clear')")
+	second := runGit(t, upstreamPath, "rev-parse", "HEAD")
+
+	if head := clone(pushedAt); head != first {
+		t.Fatalf("head = %s, want the cached %s when pushed_at didn't change", head, first)
+	}
+	if head := clone(pushedAt.Add(time.Hour)); head != second {
+		t.Fatalf("head = %s, want the fetched %s", head, second)
+	}
+
+	if count := runGit(t, filepath.Join(cloner.CachePath, "42.git"), "rev-list", "--count", "HEAD"); count != "1" {
+		t.Fatalf("cached commit count = %s, want a shallow clone", count)
+	}
+}
+
+func TestCloneMaxSize(t *testing.T) {
+	upstream := setupUpstream(t)
+
+	for name, cloner := range map[string]Cloner{
+		"without cache": {MaxSize: 1},
+		"with cache":    {MaxSize: 1, CachePath: t.TempDir()},
+	} {
+		t.Run(name, func(t *testing.T) {
+			target := filepath.Join(t.TempDir(), "mytest")
+			err := cloner.Clone(context.Background(), "42", upstream, time.Now(), target)
+			if err == nil || !strings.Contains(err.Error(), "limit") {
+				t.Fatalf("Clone error = %v, want a size limit error", err)
+			}
+			if cloner.CachePath != "" {
+				if _, err := os.Stat(filepath.Join(cloner.CachePath, "42.git")); !os.IsNotExist(err) {
+					t.Fatal("a repository over the size limit should not be cached")
+				}
+			}
+		})
+	}
+}
+
+func TestClonePinned(t *testing.T) {
+	upstream := setupUpstream(t)
+	upstreamPath := strings.TrimPrefix(upstream, "file://")
+	pinned := runGit(t, upstreamPath, "rev-parse", "HEAD")
+	commitFile(t, upstreamPath, "colors/mytest.lua", "vim.cmd('hi clear')")
+
+	target := filepath.Join(t.TempDir(), "lush.nvim")
+	if err := (Cloner{}).ClonePinned(context.Background(), upstream, pinned, target); err != nil {
+		t.Fatalf("ClonePinned returned error: %v", err)
+	}
+	if head := runGit(t, target, "rev-parse", "HEAD"); head != pinned {
+		t.Fatalf("head = %s, want %s", head, pinned)
+	}
+}
+
+func TestIsNotFound(t *testing.T) {
+	upstream := setupUpstream(t)
+
+	err := Cloner{}.Clone(context.Background(), "missing", upstream+"-missing", time.Time{}, filepath.Join(t.TempDir(), "target"))
+	if !IsNotFound(err) {
+		t.Fatalf("IsNotFound(%v) = false, want true", err)
+	}
+
+	if IsNotFound(nil) || IsNotFound(context.DeadlineExceeded) {
+		t.Fatal("IsNotFound should be false for other errors")
+	}
+}
+
+func TestRunWithinSize(t *testing.T) {
+	path := t.TempDir()
+	cloner := Cloner{MaxSize: 1024}
+
+	// Stands for a download that keeps going until it's stopped
+	err := cloner.runWithinSize(context.Background(), path, func(ctx context.Context) error {
+		if err := os.WriteFile(filepath.Join(path, "pack"), make([]byte, 4096), 0o644); err != nil {
+			return err
+		}
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	if err == nil || !strings.Contains(err.Error(), "limit") {
+		t.Fatalf("runWithinSize error = %v, want a size limit error", err)
+	}
+
+	if err := cloner.runWithinSize(context.Background(), path, func(context.Context) error { return nil }); err != nil {
+		t.Fatalf("runWithinSize error = %v, want the error of the run", err)
+	}
+}
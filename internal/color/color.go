@@ -0,0 +1,202 @@
+package color
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// RGB represents an sRGB color with channels in the [0, 1] range
+type RGB struct {
+	R float64
+	G float64
+	B float64
+}
+
+// Deficiency represents a color vision deficiency
+type Deficiency string
+
+const (
+	// Protanopia represents the absence of red cones
+	Protanopia Deficiency = "protanopia"
+
+	// Deuteranopia represents the absence of green cones
+	Deuteranopia Deficiency = "deuteranopia"
+
+	// Tritanopia represents the absence of blue cones
+	Tritanopia Deficiency = "tritanopia"
+)
+
+// Deficiencies lists the simulated color vision deficiencies
+var Deficiencies = []Deficiency{Protanopia, Deuteranopia, Tritanopia}
+
+// deficiencyMatrices holds the full severity simulation matrices from
+// Machado, Oliveira and Fernandes (2009), applied to linear RGB
+var deficiencyMatrices = map[Deficiency][3][3]float64{
+	Protanopia: {
+		{0.152286, 1.052583, -0.204868},
+		{0.114503, 0.786281, 0.099216},
+		{-0.003882, -0.048116, 1.051998},
+	},
+	Deuteranopia: {
+		{0.367322, 0.860646, -0.227968},
+		{0.280085, 0.672501, 0.047413},
+		{-0.011820, 0.042940, 0.968881},
+	},
+	Tritanopia: {
+		{1.255528, -0.076749, -0.178779},
+		{-0.078411, 0.930809, 0.147602},
+		{0.004733, 0.691367, 0.303900},
+	},
+}
+
+// Lab represents a color in the CIELAB color space (D65 white point)
+type Lab struct {
+	L float64
+	A float64
+	B float64
+}
+
+// ParseHex parses a "#rrggbb" or "#rgb" hex code into an RGB color
+func ParseHex(hexCode string) (RGB, error) {
+	raw := strings.TrimPrefix(strings.TrimSpace(hexCode), "#")
+	if len(raw) == 3 {
+		raw = string([]byte{raw[0], raw[0], raw[1], raw[1], raw[2], raw[2]})
+	}
+	if len(raw) != 6 {
+		return RGB{}, fmt.Errorf("invalid hex code %q", hexCode)
+	}
+
+	value, err := strconv.ParseUint(raw, 16, 32)
+	if err != nil {
+		return RGB{}, fmt.Errorf("invalid hex code %q", hexCode)
+	}
+
+	return RGB{
+		R: float64((value>>16)&0xff) / 255,
+		G: float64((value>>8)&0xff) / 255,
+		B: float64(value&0xff) / 255,
+	}, nil
+}
+
+// Hex returns the "#rrggbb" representation of the color
+func (c RGB) Hex() string {
+	r, g, b := c.Bytes()
+	return fmt.Sprintf("#%02x%02x%02x", r, g, b)
+}
+
+// Bytes returns the 8-bit red, green and blue channels of the color
+func (c RGB) Bytes() (uint8, uint8, uint8) {
+	return toByte(c.R), toByte(c.G), toByte(c.B)
+}
+
+// Luminance returns the WCAG relative luminance of the color
+func (c RGB) Luminance() float64 {
+	return 0.2126*linearize(c.R) + 0.7152*linearize(c.G) + 0.0722*linearize(c.B)
+}
+
+// Lab converts the color to the CIELAB color space
+func (c RGB) Lab() Lab {
+	r, g, b := linearize(c.R), linearize(c.G), linearize(c.B)
+
+	x := (0.4124564*r + 0.3575761*g + 0.1804375*b) / 0.95047
+	y := 0.2126729*r + 0.7151522*g + 0.0721750*b
+	z := (0.0193339*r + 0.1191920*g + 0.9503041*b) / 1.08883
+
+	fx, fy, fz := labF(x), labF(y), labF(z)
+
+	return Lab{
+		L: 116*fy - 16,
+		A: 500 * (fx - fy),
+		B: 200 * (fy - fz),
+	}
+}
+
+// HSL returns the hue (in degrees, [0, 360)), saturation and lightness
+// ([0, 1]) of the color
+func (c RGB) HSL() (float64, float64, float64) {
+	maxChannel := math.Max(c.R, math.Max(c.G, c.B))
+	minChannel := math.Min(c.R, math.Min(c.G, c.B))
+	lightness := (maxChannel + minChannel) / 2
+
+	delta := maxChannel - minChannel
+	if delta == 0 {
+		return 0, 0, lightness
+	}
+
+	saturation := delta / (1 - math.Abs(2*lightness-1))
+
+	var hue float64
+	switch maxChannel {
+	case c.R:
+		hue = math.Mod((c.G-c.B)/delta, 6)
+	case c.G:
+		hue = (c.B-c.R)/delta + 2
+	default:
+		hue = (c.R-c.G)/delta + 4
+	}
+	hue *= 60
+	if hue < 0 {
+		hue += 360
+	}
+
+	return hue, saturation, lightness
+}
+
+// Simulate returns the color as perceived with the color vision deficiency
+func (c RGB) Simulate(deficiency Deficiency) RGB {
+	matrix, ok := deficiencyMatrices[deficiency]
+	if !ok {
+		return c
+	}
+
+	r, g, b := linearize(c.R), linearize(c.G), linearize(c.B)
+	return RGB{
+		R: delinearize(matrix[0][0]*r + matrix[0][1]*g + matrix[0][2]*b),
+		G: delinearize(matrix[1][0]*r + matrix[1][1]*g + matrix[1][2]*b),
+		B: delinearize(matrix[2][0]*r + matrix[2][1]*g + matrix[2][2]*b),
+	}
+}
+
+// ContrastRatio returns the WCAG contrast ratio between two colors, from 1 to 21
+func ContrastRatio(a RGB, b RGB) float64 {
+	lighter, darker := a.Luminance(), b.Luminance()
+	if darker > lighter {
+		lighter, darker = darker, lighter
+	}
+	return (lighter + 0.05) / (darker + 0.05)
+}
+
+// DeltaE returns the CIE76 perceptual distance between two Lab colors
+func DeltaE(a Lab, b Lab) float64 {
+	return math.Sqrt(math.Pow(a.L-b.L, 2) + math.Pow(a.A-b.A, 2) + math.Pow(a.B-b.B, 2))
+}
+
+func linearize(channel float64) float64 {
+	if channel <= 0.04045 {
+		return channel / 12.92
+	}
+	return math.Pow((channel+0.055)/1.055, 2.4)
+}
+
+func delinearize(channel float64) float64 {
+	channel = math.Max(0, math.Min(1, channel))
+	if channel <= 0.0031308 {
+		return channel * 12.92
+	}
+	return 1.055*math.Pow(channel, 1/2.4) - 0.055
+}
+
+func labF(t float64) float64 {
+	const epsilon = 216.0 / 24389.0
+	const kappa = 24389.0 / 27.0
+	if t > epsilon {
+		return math.Cbrt(t)
+	}
+	return (kappa*t + 16) / 116
+}
+
+func toByte(channel float64) uint8 {
+	return uint8(math.Round(math.Max(0, math.Min(1, channel)) * 255))
+}
@@ -0,0 +1,127 @@
+package color
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseHex(t *testing.T) {
+	t.Run("parses long hex codes", func(t *testing.T) {
+		c, err := ParseHex("#ff8000")
+		if err != nil {
+			t.Fatalf("ParseHex: %v", err)
+		}
+		if c.R != 1 || c.G != 128.0/255 || c.B != 0 {
+			t.Fatalf("ParseHex = %+v, want {1 0.50 0}", c)
+		}
+		if r, g, b := c.Bytes(); r != 0xff || g != 0x80 || b != 0 {
+			t.Fatalf("Bytes = %d, %d, %d, want 255, 128, 0", r, g, b)
+		}
+	})
+
+	t.Run("parses short hex codes", func(t *testing.T) {
+		c, err := ParseHex("#fff")
+		if err != nil {
+			t.Fatalf("ParseHex: %v", err)
+		}
+		if c.Hex() != "#ffffff" {
+			t.Fatalf("Hex = %q, want %q", c.Hex(), "#ffffff")
+		}
+	})
+
+	t.Run("rejects invalid hex codes", func(t *testing.T) {
+		for _, hexCode := range []string{"", "#12", "#gggggg", "NONE"} {
+			if _, err := ParseHex(hexCode); err == nil {
+				t.Fatalf("ParseHex(%q) returned no error", hexCode)
+			}
+		}
+	})
+}
+
+func TestContrastRatio(t *testing.T) {
+	black, _ := ParseHex("#000000")
+	white, _ := ParseHex("#ffffff")
+
+	if ratio := ContrastRatio(black, white); math.Abs(ratio-21) > 0.001 {
+		t.Fatalf("ContrastRatio(black, white) = %f, want 21", ratio)
+	}
+	if ratio := ContrastRatio(white, black); math.Abs(ratio-21) > 0.001 {
+		t.Fatalf("ContrastRatio(white, black) = %f, want 21", ratio)
+	}
+	if ratio := ContrastRatio(white, white); ratio != 1 {
+		t.Fatalf("ContrastRatio(white, white) = %f, want 1", ratio)
+	}
+}
+
+func TestLab(t *testing.T) {
+	white, _ := ParseHex("#ffffff")
+	lab := white.Lab()
+	if math.Abs(lab.L-100) > 0.01 || math.Abs(lab.A) > 0.01 || math.Abs(lab.B) > 0.01 {
+		t.Fatalf("white Lab = %+v, want {100 0 0}", lab)
+	}
+
+	red, _ := ParseHex("#ff0000")
+	lab = red.Lab()
+	if math.Abs(lab.L-53.24) > 0.05 || math.Abs(lab.A-80.09) > 0.05 || math.Abs(lab.B-67.20) > 0.05 {
+		t.Fatalf("red Lab = %+v, want {53.24 80.09 67.20}", lab)
+	}
+}
+
+func TestDeltaE(t *testing.T) {
+	black, _ := ParseHex("#000000")
+	white, _ := ParseHex("#ffffff")
+
+	if distance := DeltaE(black.Lab(), white.Lab()); math.Abs(distance-100) > 0.01 {
+		t.Fatalf("DeltaE(black, white) = %f, want 100", distance)
+	}
+	if distance := DeltaE(white.Lab(), white.Lab()); distance != 0 {
+		t.Fatalf("DeltaE(white, white) = %f, want 0", distance)
+	}
+}
+
+func TestHSL(t *testing.T) {
+	tests := []struct {
+		hexCode    string
+		hue        float64
+		saturation float64
+		lightness  float64
+	}{
+		{"#ff0000", 0, 1, 0.5},
+		{"#00ff00", 120, 1, 0.5},
+		{"#0000ff", 240, 1, 0.5},
+		{"#ff00ff", 300, 1, 0.5},
+		{"#808080", 0, 0, 128.0 / 255},
+	}
+	for _, tt := range tests {
+		t.Run(tt.hexCode, func(t *testing.T) {
+			c, _ := ParseHex(tt.hexCode)
+			hue, saturation, lightness := c.HSL()
+			if math.Abs(hue-tt.hue) > 0.01 || math.Abs(saturation-tt.saturation) > 0.01 || math.Abs(lightness-tt.lightness) > 0.01 {
+				t.Fatalf("HSL(%q) = %f, %f, %f, want %f, %f, %f", tt.hexCode, hue, saturation, lightness, tt.hue, tt.saturation, tt.lightness)
+			}
+		})
+	}
+}
+
+func TestSimulate(t *testing.T) {
+	t.Run("keeps grays unchanged", func(t *testing.T) {
+		gray, _ := ParseHex("#808080")
+		for _, deficiency := range Deficiencies {
+			if simulated := gray.Simulate(deficiency).Hex(); simulated != "#808080" {
+				t.Fatalf("Simulate(%s) = %q, want %q", deficiency, simulated, "#808080")
+			}
+		}
+	})
+
+	t.Run("makes red and green harder to tell apart", func(t *testing.T) {
+		red, _ := ParseHex("#ff0000")
+		green, _ := ParseHex("#00a000")
+		normalDistance := DeltaE(red.Lab(), green.Lab())
+		for _, deficiency := range []Deficiency{Protanopia, Deuteranopia} {
+			distance := DeltaE(red.Simulate(deficiency).Lab(), green.Simulate(deficiency).Lab())
+			if distance >= normalDistance/2 {
+				t.Fatalf("DeltaE under %s = %f, want less than %f", deficiency, distance, normalDistance/2)
+			}
+		}
+	})
+}
@@ -8,11 +8,34 @@ import (
 )
 
 func loadColorschemes(repositoryID int64) ([]repository.Colorscheme, error) {
+	schemes, err := queryColorschemes("WHERE cs.repository_id = ?", repositoryID)
+	if err != nil {
+		return nil, err
+	}
+	return schemes[repositoryID], nil
+}
+
+// GetColorschemesByRepository gets all stored colorschemes keyed by repository id.
+func GetColorschemesByRepository() (map[int64][]repository.Colorscheme, error) {
+	return queryColorschemes("")
+}
+
+func queryColorschemes(filter string, args ...any) (map[int64][]repository.Colorscheme, error) {
 	rows, err := db.Query(`
 		SELECT
 			cs.id,
+			cs.repository_id,
 			cs.name,
+			cs.runner,
+			cs.commit_sha,
+			cs.generation_id,
+			cs.variant,
+			COALESCE(parent.name, ''),
+			cs.is_hidden,
+			cs.quality_score,
+			cs.quality_reasons,
 			csg.background,
+			csg.language,
 			csg.name,
 			csg.hex_code,
 			csg.bold,
@@ -23,11 +46,13 @@ func loadColorschemes(repositoryID int64) ([]repository.Colorscheme, error) {
 			csg.underdotted,
 			csg.underdashed,
 			csg.strikethrough,
-			csg.reverse
+			csg.reverse,
+			csg.link
 		FROM colorschemes cs
+		LEFT JOIN colorschemes parent ON parent.id = cs.parent_id
 		LEFT JOIN colorscheme_groups csg ON csg.colorscheme_id = cs.id
-		WHERE cs.repository_id = ?
-		ORDER BY cs.id, csg.id`, repositoryID)
+		`+filter+`
+		ORDER BY cs.id, csg.id`, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -36,21 +61,39 @@ func loadColorschemes(repositoryID int64) ([]repository.Colorscheme, error) {
 	}()
 
 	type schemeEntry struct {
-		index int
+		repositoryID int64
+		index        int
 	}
 	schemeMap := make(map[int64]*schemeEntry)
-	var schemes []repository.Colorscheme
+	schemes := make(map[int64][]repository.Colorscheme)
 
 	for rows.Next() {
-		var schemeID int64
+		var schemeID, repositoryID int64
 		var schemeName string
-		var bg, groupName, hexCode sql.NullString
+		var runner repository.Runner
+		var commitSHA string
+		var generationID sql.NullInt64
+		var variant, parent string
+		var hidden bool
+		var qualityScore sql.NullFloat64
+		var qualityReasons string
+		var bg, language, groupName, hexCode, link sql.NullString
 		var bold, italic, underline, undercurl, underdouble, underdotted, underdashed, strikethrough, reverse sql.NullBool
 
 		if err := rows.Scan(
 			&schemeID,
+			&repositoryID,
 			&schemeName,
+			&runner,
+			&commitSHA,
+			&generationID,
+			&variant,
+			&parent,
+			&hidden,
+			&qualityScore,
+			&qualityReasons,
 			&bg,
+			&language,
 			&groupName,
 			&hexCode,
 			&bold,
@@ -62,14 +105,19 @@ func loadColorschemes(repositoryID int64) ([]repository.Colorscheme, error) {
 			&underdashed,
 			&strikethrough,
 			&reverse,
+			&link,
 		); err != nil {
 			return nil, err
 		}
 
 		entry, exists := schemeMap[schemeID]
 		if !exists {
-			schemes = append(schemes, repository.Colorscheme{Name: schemeName})
-			entry = &schemeEntry{index: len(schemes) - 1}
+			quality := repository.Quality{Score: qualityScore.Float64}
+			if err := json.Unmarshal([]byte(qualityReasons), &quality.Reasons); err != nil {
+				return nil, err
+			}
+			schemes[repositoryID] = append(schemes[repositoryID], repository.Colorscheme{ID: schemeID, Name: schemeName, Runner: runner, CommitSHA: commitSHA, GenerationID: generationID.Int64, Variant: variant, Parent: parent, Quality: quality, Hidden: hidden})
+			entry = &schemeEntry{repositoryID: repositoryID, index: len(schemes[repositoryID]) - 1}
 			schemeMap[schemeID] = entry
 		}
 
@@ -86,32 +134,81 @@ func loadColorschemes(repositoryID int64) ([]repository.Colorscheme, error) {
 				Underdashed:   underdashed.Bool,
 				Strikethrough: strikethrough.Bool,
 				Reverse:       reverse.Bool,
+				Link:          link.String,
 			}
-			s := &schemes[entry.index]
-			switch repository.BackgroundValue(bg.String) {
-			case repository.LightBackground:
-				s.Data.Light = append(s.Data.Light, group)
-			case repository.DarkBackground:
-				s.Data.Dark = append(s.Data.Dark, group)
+			s := &schemes[entry.repositoryID][entry.index]
+			background := repository.BackgroundValue(bg.String)
+			if language.String == repository.DefaultLanguage {
+				s.Data = appendColorschemeGroup(s.Data, background, group)
+			} else {
+				if s.Languages == nil {
+					s.Languages = map[string]repository.ColorschemeData{}
+				}
+				s.Languages[language.String] = appendColorschemeGroup(s.Languages[language.String], background, group)
 			}
 		}
 	}
 
-	// Derive backgrounds
-	for i := range schemes {
-		var backgrounds []repository.BackgroundValue
-		if len(schemes[i].Data.Light) > 0 {
-			backgrounds = append(backgrounds, repository.LightBackground)
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	previewRows, err := db.Query(`
+		SELECT p.colorscheme_id, p.background, p.format, p.url
+		FROM colorscheme_previews p
+		JOIN colorschemes cs ON cs.id = p.colorscheme_id
+		`+filter+`
+		ORDER BY p.id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = previewRows.Close()
+	}()
+
+	for previewRows.Next() {
+		var schemeID int64
+		var preview repository.Preview
+		if err := previewRows.Scan(&schemeID, &preview.Background, &preview.Format, &preview.URL); err != nil {
+			return nil, err
 		}
-		if len(schemes[i].Data.Dark) > 0 {
-			backgrounds = append(backgrounds, repository.DarkBackground)
+		if entry, exists := schemeMap[schemeID]; exists {
+			s := &schemes[entry.repositoryID][entry.index]
+			s.Previews = append(s.Previews, preview)
+		}
+	}
+
+	if err := previewRows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Derive backgrounds
+	for _, repositorySchemes := range schemes {
+		for i := range repositorySchemes {
+			var backgrounds []repository.BackgroundValue
+			if len(repositorySchemes[i].Data.Light) > 0 {
+				backgrounds = append(backgrounds, repository.LightBackground)
+			}
+			if len(repositorySchemes[i].Data.Dark) > 0 {
+				backgrounds = append(backgrounds, repository.DarkBackground)
+			}
+			repositorySchemes[i].Backgrounds = backgrounds
 		}
-		schemes[i].Backgrounds = backgrounds
 	}
 
 	return schemes, nil
 }
 
+func appendColorschemeGroup(data repository.ColorschemeData, background repository.BackgroundValue, group repository.ColorschemeGroup) repository.ColorschemeData {
+	switch background {
+	case repository.LightBackground:
+		data.Light = append(data.Light, group)
+	case repository.DarkBackground:
+		data.Dark = append(data.Dark, group)
+	}
+	return data
+}
+
 type scannable interface {
 	Scan(dest ...interface{}) error
 }
@@ -126,6 +223,7 @@ func scanRepository(s scannable) (repository.Repository, error) {
 		&repo.StargazersCount, &historyJSON, &repo.WeekStargazersCount,
 		&githubCreatedAt, &pushedAt,
 		&repo.IsEligible, &repo.IsDisabled, &updatedAt,
+		&repo.Runner,
 	)
 	if err != nil {
 		return repository.Repository{}, err
@@ -135,6 +135,36 @@ func TestLoadColorschemes(t *testing.T) {
 	})
 }
 
+func TestGetColorschemesByRepository(t *testing.T) {
+	setupTestDB(t)
+	insertTestRepo(t, 1, "owner", "repo1")
+	insertTestRepo(t, 2, "owner", "repo2")
+	if _, err := db.Exec(`INSERT INTO colorschemes (id, repository_id, name) VALUES (1, 1, 'first'), (2, 2, 'second'), (3, 2, 'third')`); err != nil {
+		t.Fatalf("insert colorschemes: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO colorscheme_groups (colorscheme_id, background, name, hex_code) VALUES (3, 'dark', 'NormalBg', '#000000')`); err != nil {
+		t.Fatalf("insert colorscheme_group: %v", err)
+	}
+
+	schemes, err := GetColorschemesByRepository()
+	if err != nil {
+		t.Fatalf("GetColorschemesByRepository: %v", err)
+	}
+	if len(schemes[1]) != 1 {
+		t.Fatalf("len(schemes[1]) = %d, want 1", len(schemes[1]))
+	}
+	if len(schemes[2]) != 2 {
+		t.Fatalf("len(schemes[2]) = %d, want 2", len(schemes[2]))
+	}
+	third := schemes[2][1]
+	if third.ID != 3 || third.Name != "third" {
+		t.Fatalf("schemes[2][1] = %d %q, want 3 %q", third.ID, third.Name, "third")
+	}
+	if len(third.Data.Dark) != 1 || len(third.Backgrounds) != 1 {
+		t.Fatalf("third scheme groups were not loaded: %+v", third)
+	}
+}
+
 func TestScanRepository(t *testing.T) {
 	t.Run("populates all nullable time fields", func(t *testing.T) {
 		setupTestDB(t)
@@ -82,7 +82,7 @@ func TestApplyMigrationsCreatesAllTables(t *testing.T) {
 		t.Fatalf("applyMigrations returned error: %v", err)
 	}
 
-	for _, tableName := range []string{"repositories", "repositories_search", "repository_job_events", "colorschemes", "colorscheme_groups", "reports", "goose_db_version"} {
+	for _, tableName := range []string{"repositories", "repositories_search", "repository_job_events", "colorschemes", "colorscheme_groups", "colorscheme_similar", "colorscheme_analytics", "colorscheme_accessibility_checks", "colorscheme_background_checks", "colorscheme_previews", "colorscheme_plugin_support", "repository_nvim_versions", "repository_generations", "repository_scans", "reports", "goose_db_version"} {
 		var actual string
 		err := db.QueryRow("SELECT name FROM sqlite_master WHERE type = 'table' AND name = ?", tableName).Scan(&actual)
 		if err != nil {
@@ -301,6 +301,20 @@ func TestApplyMigrationsCreatesExpectedIndexes(t *testing.T) {
 		"idx_colorscheme_groups_scheme_id_id",
 		"idx_colorscheme_groups_background_scheme_id",
 		"idx_repository_job_events_job_repository_created",
+		"idx_colorscheme_similar_similar_colorscheme_id",
+		"idx_colorscheme_analytics_hue_family",
+		"idx_colorscheme_analytics_temperature",
+		"idx_colorscheme_analytics_contrast_ratio",
+		"idx_colorscheme_accessibility_checks_scheme_id_background",
+		"idx_colorscheme_previews_colorscheme_id",
+		"idx_colorscheme_groups_scheme_id_language",
+		"idx_colorscheme_groups_namespace_scheme_id",
+		"idx_colorscheme_plugin_support_plugin_supported",
+		"idx_repository_nvim_versions_version_success",
+		"idx_repository_generations_repository_id_id",
+		"idx_repository_generations_changed_created_at",
+		"idx_repositories_quarantined_at",
+		"idx_colorschemes_parent_id",
 	} {
 		var actual string
 		err := db.QueryRow("SELECT name FROM sqlite_master WHERE type = 'index' AND name = ?", indexName).Scan(&actual)
@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE colorscheme_similar (
+    colorscheme_id         INTEGER NOT NULL REFERENCES colorschemes(id) ON DELETE CASCADE,
+    background             TEXT NOT NULL,
+    similar_colorscheme_id INTEGER NOT NULL REFERENCES colorschemes(id) ON DELETE CASCADE,
+    rank                   INTEGER NOT NULL,
+    distance               REAL NOT NULL,
+    PRIMARY KEY (colorscheme_id, background, rank)
+);
+
+CREATE INDEX idx_colorscheme_similar_similar_colorscheme_id
+    ON colorscheme_similar(similar_colorscheme_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_colorscheme_similar_similar_colorscheme_id;
+DROP TABLE IF EXISTS colorscheme_similar;
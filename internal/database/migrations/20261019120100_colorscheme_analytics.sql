@@ -0,0 +1,28 @@
+-- +goose Up
+CREATE TABLE colorscheme_analytics (
+    colorscheme_id       INTEGER NOT NULL REFERENCES colorschemes(id) ON DELETE CASCADE,
+    background           TEXT NOT NULL,
+    background_luminance REAL NOT NULL,
+    contrast_ratio       REAL NOT NULL,
+    color_count          INTEGER NOT NULL,
+    hue_family           TEXT NOT NULL,
+    temperature          TEXT NOT NULL,
+    saturation           REAL NOT NULL,
+    saturation_level     TEXT NOT NULL,
+    PRIMARY KEY (colorscheme_id, background)
+);
+
+CREATE INDEX idx_colorscheme_analytics_hue_family
+    ON colorscheme_analytics(hue_family, background);
+
+CREATE INDEX idx_colorscheme_analytics_temperature
+    ON colorscheme_analytics(temperature, background);
+
+CREATE INDEX idx_colorscheme_analytics_contrast_ratio
+    ON colorscheme_analytics(contrast_ratio DESC);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_colorscheme_analytics_contrast_ratio;
+DROP INDEX IF EXISTS idx_colorscheme_analytics_temperature;
+DROP INDEX IF EXISTS idx_colorscheme_analytics_hue_family;
+DROP TABLE IF EXISTS colorscheme_analytics;
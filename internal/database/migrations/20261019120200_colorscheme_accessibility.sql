@@ -0,0 +1,26 @@
+-- +goose Up
+CREATE TABLE colorscheme_accessibility_checks (
+    id               INTEGER PRIMARY KEY AUTOINCREMENT,
+    colorscheme_id   INTEGER NOT NULL REFERENCES colorschemes(id) ON DELETE CASCADE,
+    background       TEXT NOT NULL,
+    name             TEXT NOT NULL,
+    vision           TEXT NOT NULL,
+    foreground_color TEXT NOT NULL,
+    background_color TEXT NOT NULL,
+    contrast_ratio   REAL NOT NULL,
+    passes_aa        BOOLEAN NOT NULL DEFAULT 0,
+    passes_aaa       BOOLEAN NOT NULL DEFAULT 0
+);
+
+CREATE INDEX idx_colorscheme_accessibility_checks_scheme_id_background
+    ON colorscheme_accessibility_checks(colorscheme_id, background);
+
+ALTER TABLE repositories ADD COLUMN accessibility_level TEXT NOT NULL DEFAULT '';
+ALTER TABLE repositories ADD COLUMN accessibility_cvd_safe BOOLEAN NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE repositories DROP COLUMN accessibility_cvd_safe;
+ALTER TABLE repositories DROP COLUMN accessibility_level;
+
+DROP INDEX IF EXISTS idx_colorscheme_accessibility_checks_scheme_id_background;
+DROP TABLE IF EXISTS colorscheme_accessibility_checks;
@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE colorscheme_previews (
+    id             INTEGER PRIMARY KEY AUTOINCREMENT,
+    colorscheme_id INTEGER NOT NULL REFERENCES colorschemes(id) ON DELETE CASCADE,
+    background     TEXT NOT NULL,
+    format         TEXT NOT NULL,
+    url            TEXT NOT NULL
+);
+
+CREATE INDEX idx_colorscheme_previews_colorscheme_id ON colorscheme_previews(colorscheme_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_colorscheme_previews_colorscheme_id;
+DROP TABLE IF EXISTS colorscheme_previews;
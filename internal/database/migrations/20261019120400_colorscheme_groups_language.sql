@@ -0,0 +1,10 @@
+-- +goose Up
+ALTER TABLE colorscheme_groups ADD COLUMN language TEXT NOT NULL DEFAULT 'vim';
+
+CREATE INDEX idx_colorscheme_groups_scheme_id_language
+    ON colorscheme_groups(colorscheme_id, language);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_colorscheme_groups_scheme_id_language;
+
+ALTER TABLE colorscheme_groups DROP COLUMN language;
@@ -0,0 +1,13 @@
+-- +goose Up
+ALTER TABLE colorscheme_groups ADD COLUMN namespace TEXT NOT NULL DEFAULT 'syntax';
+
+UPDATE colorscheme_groups SET namespace = 'treesitter' WHERE name LIKE '@%';
+UPDATE colorscheme_groups SET namespace = 'lsp' WHERE name LIKE '@lsp.%';
+
+CREATE INDEX idx_colorscheme_groups_namespace_scheme_id
+    ON colorscheme_groups(namespace, colorscheme_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_colorscheme_groups_namespace_scheme_id;
+
+ALTER TABLE colorscheme_groups DROP COLUMN namespace;
@@ -0,0 +1,19 @@
+-- +goose Up
+CREATE TABLE colorscheme_plugin_support (
+    colorscheme_id      INTEGER NOT NULL REFERENCES colorschemes(id) ON DELETE CASCADE,
+    background          TEXT NOT NULL,
+    plugin              TEXT NOT NULL,
+    list_version        INTEGER NOT NULL,
+    defined_group_count INTEGER NOT NULL,
+    group_count         INTEGER NOT NULL,
+    coverage            REAL NOT NULL,
+    supported           BOOLEAN NOT NULL DEFAULT 0,
+    PRIMARY KEY (colorscheme_id, background, plugin)
+);
+
+CREATE INDEX idx_colorscheme_plugin_support_plugin_supported
+    ON colorscheme_plugin_support(plugin, supported);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_colorscheme_plugin_support_plugin_supported;
+DROP TABLE IF EXISTS colorscheme_plugin_support;
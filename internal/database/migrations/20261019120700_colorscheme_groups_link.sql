@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE colorscheme_groups ADD COLUMN link TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE colorscheme_groups DROP COLUMN link;
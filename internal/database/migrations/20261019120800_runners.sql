@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE repositories ADD COLUMN runner TEXT NOT NULL DEFAULT '';
+ALTER TABLE colorschemes ADD COLUMN runner TEXT NOT NULL DEFAULT 'nvim';
+
+-- +goose Down
+ALTER TABLE colorschemes DROP COLUMN runner;
+ALTER TABLE repositories DROP COLUMN runner;
@@ -0,0 +1,20 @@
+-- +goose Up
+CREATE TABLE repository_nvim_versions (
+    repository_id     INTEGER NOT NULL REFERENCES repositories(id) ON DELETE CASCADE,
+    binary_path       TEXT NOT NULL,
+    nvim_version      TEXT NOT NULL,
+    success           BOOLEAN NOT NULL DEFAULT 0,
+    error             TEXT NOT NULL DEFAULT '',
+    colorscheme_count INTEGER NOT NULL DEFAULT 0,
+    differs           BOOLEAN NOT NULL DEFAULT 0,
+    diff              TEXT NOT NULL DEFAULT '{}',
+    created_at        DATETIME NOT NULL,
+    PRIMARY KEY (repository_id, binary_path)
+);
+
+CREATE INDEX idx_repository_nvim_versions_version_success
+    ON repository_nvim_versions(nvim_version, success);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_repository_nvim_versions_version_success;
+DROP TABLE IF EXISTS repository_nvim_versions;
@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE colorschemes ADD COLUMN commit_sha TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE colorschemes DROP COLUMN commit_sha;
@@ -0,0 +1,21 @@
+-- +goose Up
+CREATE TABLE repository_generations (
+    id            INTEGER PRIMARY KEY AUTOINCREMENT,
+    repository_id INTEGER NOT NULL REFERENCES repositories(id) ON DELETE CASCADE,
+    commit_sha    TEXT NOT NULL DEFAULT '',
+    runner        TEXT NOT NULL DEFAULT 'nvim',
+    extractor_sha TEXT NOT NULL DEFAULT '',
+    lush_sha      TEXT NOT NULL DEFAULT '',
+    nvim_version  TEXT NOT NULL DEFAULT '',
+    vim_version   TEXT NOT NULL DEFAULT '',
+    created_at    DATETIME NOT NULL,
+    -- Last time a generate found the sources unchanged and skipped the repository
+    checked_at    DATETIME NOT NULL
+);
+
+CREATE INDEX idx_repository_generations_repository_id_id
+    ON repository_generations(repository_id, id DESC);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_repository_generations_repository_id_id;
+DROP TABLE IF EXISTS repository_generations;
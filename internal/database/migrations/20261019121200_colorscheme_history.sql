@@ -0,0 +1,20 @@
+-- +goose Up
+-- Colorscheme data of each generation keyed by colorscheme name, NULL for the
+-- generations stored before snapshots
+ALTER TABLE repository_generations ADD COLUMN snapshot TEXT;
+-- Differences with the snapshot of the previous generation
+ALTER TABLE repository_generations ADD COLUMN changes TEXT NOT NULL DEFAULT '{}';
+ALTER TABLE repository_generations ADD COLUMN change_summary TEXT NOT NULL DEFAULT '{}';
+ALTER TABLE repository_generations ADD COLUMN changed BOOLEAN NOT NULL DEFAULT 0;
+ALTER TABLE colorschemes ADD COLUMN generation_id INTEGER REFERENCES repository_generations(id);
+
+CREATE INDEX idx_repository_generations_changed_created_at
+    ON repository_generations(changed, created_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_repository_generations_changed_created_at;
+ALTER TABLE colorschemes DROP COLUMN generation_id;
+ALTER TABLE repository_generations DROP COLUMN changed;
+ALTER TABLE repository_generations DROP COLUMN change_summary;
+ALTER TABLE repository_generations DROP COLUMN changes;
+ALTER TABLE repository_generations DROP COLUMN snapshot;
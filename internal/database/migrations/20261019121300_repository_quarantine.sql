@@ -0,0 +1,18 @@
+-- +goose Up
+ALTER TABLE repositories ADD COLUMN generate_failure_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE repositories ADD COLUMN generate_retry_at DATETIME;
+ALTER TABLE repositories ADD COLUMN quarantined_at DATETIME;
+ALTER TABLE repositories ADD COLUMN quarantine_reason TEXT NOT NULL DEFAULT '';
+-- Failures before a quarantine release don't count towards the next quarantine
+ALTER TABLE repositories ADD COLUMN quarantine_released_at DATETIME;
+
+CREATE INDEX idx_repositories_quarantined_at
+    ON repositories(quarantined_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_repositories_quarantined_at;
+ALTER TABLE repositories DROP COLUMN quarantine_released_at;
+ALTER TABLE repositories DROP COLUMN quarantine_reason;
+ALTER TABLE repositories DROP COLUMN quarantined_at;
+ALTER TABLE repositories DROP COLUMN generate_retry_at;
+ALTER TABLE repositories DROP COLUMN generate_failure_count;
@@ -0,0 +1,6 @@
+-- +goose Up
+-- Error events recorded before error codes are left with an empty code
+ALTER TABLE repository_job_events ADD COLUMN error_code TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE repository_job_events DROP COLUMN error_code;
@@ -0,0 +1,6 @@
+-- +goose Up
+-- JSON excerpts of the editor output of a failed generate
+ALTER TABLE repository_job_events ADD COLUMN error_output TEXT;
+
+-- +goose Down
+ALTER TABLE repository_job_events DROP COLUMN error_output;
@@ -0,0 +1,7 @@
+-- +goose Up
+-- Name of the recipe variant a colorscheme was extracted with, empty for the
+-- main extraction
+ALTER TABLE colorschemes ADD COLUMN variant TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE colorschemes DROP COLUMN variant;
@@ -0,0 +1,11 @@
+-- +goose Up
+-- Colorscheme a variant belongs to, shipped in its own colors/ file or
+-- extracted with a recipe variant
+ALTER TABLE colorschemes ADD COLUMN parent_id INTEGER REFERENCES colorschemes(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_colorschemes_parent_id
+    ON colorschemes(parent_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_colorschemes_parent_id;
+ALTER TABLE colorschemes DROP COLUMN parent_id;
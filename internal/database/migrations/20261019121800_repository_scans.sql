@@ -0,0 +1,15 @@
+-- +goose Up
+-- Static analysis of the last cloned commit of each repository
+CREATE TABLE repository_scans (
+    repository_id    INTEGER PRIMARY KEY REFERENCES repositories(id) ON DELETE CASCADE,
+    commit_sha       TEXT NOT NULL DEFAULT '',
+    files            TEXT NOT NULL DEFAULT '[]',
+    frameworks       TEXT NOT NULL DEFAULT '[]',
+    dependencies     TEXT NOT NULL DEFAULT '[]',
+    backgrounds      TEXT NOT NULL DEFAULT '[]',
+    reads_background BOOLEAN NOT NULL DEFAULT 0,
+    scanned_at       DATETIME NOT NULL
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS repository_scans;
@@ -0,0 +1,6 @@
+-- +goose Up
+-- Dependencies installed from the curated table for the scanned commit
+ALTER TABLE repository_scans ADD COLUMN resolved_dependencies TEXT NOT NULL DEFAULT '[]';
+
+-- +goose Down
+ALTER TABLE repository_scans DROP COLUMN resolved_dependencies;
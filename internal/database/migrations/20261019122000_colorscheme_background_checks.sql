@@ -0,0 +1,14 @@
+-- +goose Up
+-- Validation of each extracted background against its Normal background color
+CREATE TABLE colorscheme_background_checks (
+    colorscheme_id       INTEGER NOT NULL REFERENCES colorschemes(id) ON DELETE CASCADE,
+    background           TEXT NOT NULL,
+    detected_background  TEXT NOT NULL,
+    background_luminance REAL NOT NULL DEFAULT 0,
+    confidence           REAL NOT NULL DEFAULT 0,
+    mismatch             TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (colorscheme_id, background)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS colorscheme_background_checks;
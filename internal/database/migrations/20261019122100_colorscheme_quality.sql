@@ -0,0 +1,14 @@
+-- +goose Up
+-- Colorschemes failing the quality checks are stored but hidden
+ALTER TABLE colorschemes ADD COLUMN is_hidden BOOLEAN NOT NULL DEFAULT 0;
+ALTER TABLE colorschemes ADD COLUMN quality_score REAL;
+ALTER TABLE colorschemes ADD COLUMN quality_reasons TEXT NOT NULL DEFAULT '[]';
+
+-- Repositories without any visible colorscheme
+ALTER TABLE repositories ADD COLUMN is_quality_eligible BOOLEAN NOT NULL DEFAULT 1;
+
+-- +goose Down
+ALTER TABLE repositories DROP COLUMN is_quality_eligible;
+ALTER TABLE colorschemes DROP COLUMN quality_reasons;
+ALTER TABLE colorschemes DROP COLUMN quality_score;
+ALTER TABLE colorschemes DROP COLUMN is_hidden;
@@ -0,0 +1,8 @@
+-- +goose Up
+-- Classification and editor output of the failed extractions of the version matrix
+ALTER TABLE repository_nvim_versions ADD COLUMN error_code TEXT NOT NULL DEFAULT '';
+ALTER TABLE repository_nvim_versions ADD COLUMN error_output TEXT;
+
+-- +goose Down
+ALTER TABLE repository_nvim_versions DROP COLUMN error_output;
+ALTER TABLE repository_nvim_versions DROP COLUMN error_code;
@@ -0,0 +1,6 @@
+-- +goose Up
+-- Checksum of the vim files, code samples and dependency table of the generation
+ALTER TABLE repository_generations ADD COLUMN inputs_hash TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE repository_generations DROP COLUMN inputs_hash;
@@ -0,0 +1,10 @@
+-- +goose Up
+-- Only the snapshot of the last generation of each repository is kept, the
+-- older generations keep their changes
+UPDATE repository_generations
+SET snapshot = NULL
+WHERE snapshot IS NOT NULL
+  AND id NOT IN (SELECT MAX(id) FROM repository_generations GROUP BY repository_id);
+
+-- +goose Down
+-- The pruned snapshots can't be restored
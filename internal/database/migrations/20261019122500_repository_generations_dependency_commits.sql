@@ -0,0 +1,6 @@
+-- +goose Up
+-- Commits of the dependencies installed for the generation, keyed by name
+ALTER TABLE repository_generations ADD COLUMN dependency_commits TEXT NOT NULL DEFAULT '{}';
+
+-- +goose Down
+ALTER TABLE repository_generations DROP COLUMN dependency_commits;
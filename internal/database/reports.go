@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/vimcolorschemes/worker/internal/date"
+	"github.com/vimcolorschemes/worker/internal/repository"
 )
 
 type JobReport struct {
@@ -19,6 +20,16 @@ type JobReport struct {
 	Data        map[string]interface{}
 }
 
+// RepositoryChange is a generation that changed the colorschemes of a repository.
+type RepositoryChange struct {
+	RepositoryID int64
+	OwnerName    string
+	Name         string
+	GenerationID int64
+	Date         time.Time
+	Summary      repository.ChangeSummary
+}
+
 // CreateReport stores a job report in the database.
 func CreateReport(job string, elapsedTime float64, data map[string]interface{}) error {
 	dataJSON, err := json.Marshal(data)
@@ -134,6 +145,45 @@ func CountRepositoryJobEvents(job string, day time.Time) (map[string]int, error)
 	return counts, nil
 }
 
+// CountRepositoryJobEventErrorCodes returns counts per error code of the failed
+// events of a repository job on the provided UTC day. Events recorded without
+// a code are counted as unknown.
+func CountRepositoryJobEventErrorCodes(job string, day time.Time) (map[string]int, error) {
+	day = date.RoundTimeToDate(day.UTC())
+	nextDay := day.Add(24 * time.Hour)
+
+	rows, err := db.Query(
+		"SELECT COALESCE(NULLIF(error_code, ''), ?), COUNT(*) FROM repository_job_events WHERE job = ? AND status = ? AND created_at >= ? AND created_at < ? GROUP BY 1",
+		string(repository.UnknownError),
+		job,
+		jobStatusError,
+		day,
+		nextDay,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query %s repository job event error codes: %w", job, err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	counts := map[string]int{}
+	for rows.Next() {
+		var code string
+		var count int
+		if err := rows.Scan(&code, &count); err != nil {
+			return nil, fmt.Errorf("scan %s repository job event error code: %w", job, err)
+		}
+		counts[code] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate %s repository job event error codes: %w", job, err)
+	}
+
+	return counts, nil
+}
+
 // ListRepositoryJobEventMessages returns up to limit latest error messages for a repository job on the provided UTC day.
 func ListRepositoryJobEventMessages(job string, status string, day time.Time, limit int) ([]string, error) {
 	if limit <= 0 {
@@ -191,3 +241,50 @@ func reportStatusFromPayload(payload map[string]interface{}) string {
 
 	return status
 }
+
+// ListRepositoryChanges returns up to limit latest generations that changed colorschemes on the provided UTC day.
+func ListRepositoryChanges(day time.Time, limit int) ([]RepositoryChange, error) {
+	if limit <= 0 {
+		return []RepositoryChange{}, nil
+	}
+
+	day = date.RoundTimeToDate(day.UTC())
+	nextDay := day.Add(24 * time.Hour)
+
+	rows, err := db.Query(`
+		SELECT r.id, r.owner_name, r.name, g.id, g.created_at, g.change_summary
+		FROM repository_generations g
+		JOIN repositories r ON r.id = g.repository_id
+		WHERE g.changed = 1 AND g.created_at >= ? AND g.created_at < ?
+		ORDER BY g.created_at DESC, g.id DESC
+		LIMIT ?`,
+		day,
+		nextDay,
+		limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query repository changes: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	changes := make([]RepositoryChange, 0, limit)
+	for rows.Next() {
+		var change RepositoryChange
+		var summaryJSON string
+		if err := rows.Scan(&change.RepositoryID, &change.OwnerName, &change.Name, &change.GenerationID, &change.Date, &summaryJSON); err != nil {
+			return nil, fmt.Errorf("scan repository change: %w", err)
+		}
+		if err := json.Unmarshal([]byte(summaryJSON), &change.Summary); err != nil {
+			return nil, fmt.Errorf("parse repository change summary: %w", err)
+		}
+		changes = append(changes, change)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate repository changes: %w", err)
+	}
+
+	return changes, nil
+}
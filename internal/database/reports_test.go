@@ -5,6 +5,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/vimcolorschemes/worker/internal/repository"
 )
 
 func TestCreateReport(t *testing.T) {
@@ -179,6 +181,32 @@ func TestCountRepositoryJobEvents(t *testing.T) {
 	})
 }
 
+func TestCountRepositoryJobEventErrorCodes(t *testing.T) {
+	t.Run("counts error events by code for the requested day", func(t *testing.T) {
+		setupTestDB(t)
+
+		day := time.Date(2026, time.March, 23, 0, 0, 0, 0, time.UTC)
+		insertRepositoryJobEvent(t, 1, "generate", "error", "boom", day.Add(time.Hour))
+		insertRepositoryJobEvent(t, 2, "generate", "error", "bang", day.Add(2*time.Hour))
+		insertRepositoryJobEvent(t, 3, "generate", "error", "legacy", day.Add(3*time.Hour))
+		insertRepositoryJobEvent(t, 4, "generate", "success", "", day.Add(4*time.Hour))
+		for id, code := range map[int64]string{1: "lua_error", 2: "lua_error"} {
+			if _, err := db.Exec("UPDATE repository_job_events SET error_code = ? WHERE repository_id = ?", code, id); err != nil {
+				t.Fatalf("update error_code: %v", err)
+			}
+		}
+
+		counts, err := CountRepositoryJobEventErrorCodes("generate", day)
+		if err != nil {
+			t.Fatalf("CountRepositoryJobEventErrorCodes returned error: %v", err)
+		}
+
+		if len(counts) != 2 || counts["lua_error"] != 2 || counts["unknown"] != 1 {
+			t.Fatalf("counts = %v, want 2 lua_error and 1 unknown", counts)
+		}
+	})
+}
+
 func TestListRepositoryJobEventMessages(t *testing.T) {
 	t.Run("returns latest messages for the requested day", func(t *testing.T) {
 		setupTestDB(t)
@@ -200,6 +228,54 @@ func TestListRepositoryJobEventMessages(t *testing.T) {
 	})
 }
 
+func TestListRepositoryChanges(t *testing.T) {
+	t.Run("returns the generations that changed colorschemes", func(t *testing.T) {
+		setupTestDB(t)
+		insertTestRepo(t, 1, "owner", "changed")
+		insertTestRepo(t, 2, "owner", "new")
+		insertTestRepo(t, 3, "owner", "unchanged")
+
+		scheme := func(hexCode string) []repository.Colorscheme {
+			return []repository.Colorscheme{{
+				Name: "myscheme",
+				Data: repository.ColorschemeData{Dark: []repository.ColorschemeGroup{{Name: "NormalBg", HexCode: hexCode}}},
+			}}
+		}
+		updateTestRepoFromGenerate(t, 1, GenerateData{Colorschemes: scheme("#000000")})
+		updateTestRepoFromGenerate(t, 1, GenerateData{Colorschemes: scheme("#111111")})
+		updateTestRepoFromGenerate(t, 2, GenerateData{Colorschemes: scheme("#000000")})
+		updateTestRepoFromGenerate(t, 3, GenerateData{Colorschemes: scheme("#000000")})
+		updateTestRepoFromGenerate(t, 3, GenerateData{Colorschemes: scheme("#000000")})
+
+		changes, err := ListRepositoryChanges(time.Now(), 5)
+		if err != nil {
+			t.Fatalf("ListRepositoryChanges returned error: %v", err)
+		}
+		if len(changes) != 1 {
+			t.Fatalf("changes = %+v, want 1 change", changes)
+		}
+		if changes[0].Name != "changed" || changes[0].Summary != (repository.ChangeSummary{ChangedColorschemes: 1, ChangedGroups: 1}) {
+			t.Fatalf("change = %+v, want 1 changed group of owner/changed", changes[0])
+		}
+
+		generation, _, err := GetLastRepositoryGeneration(1)
+		if err != nil {
+			t.Fatalf("GetLastRepositoryGeneration: %v", err)
+		}
+		if generation.ID != changes[0].GenerationID || generation.Changes.ChangedColorschemes["myscheme"] == nil {
+			t.Fatalf("generation = %+v, want the changes of myscheme", generation)
+		}
+
+		repo, err := GetRepository("owner/changed")
+		if err != nil {
+			t.Fatalf("GetRepository: %v", err)
+		}
+		if repo.Colorschemes[0].GenerationID != generation.ID {
+			t.Fatalf("GenerationID = %d, want %d", repo.Colorschemes[0].GenerationID, generation.ID)
+		}
+	})
+}
+
 func insertReport(t *testing.T, job string, reportTime time.Time, data string) {
 	t.Helper()
 
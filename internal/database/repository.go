@@ -5,7 +5,10 @@ import (
 	"database/sql"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log"
+	"maps"
+	"slices"
 	"strings"
 	"time"
 
@@ -38,6 +41,10 @@ type UpdateData struct {
 // GenerateData holds the fields set during a generate job.
 type GenerateData struct {
 	Colorschemes []repository.Colorscheme
+	// NvimVersionResults replace the previous results when the version matrix ran
+	NvimVersionResults []repository.NvimVersionResult
+	// Generation is what the colorschemes were generated from
+	Generation repository.Generation
 }
 
 const (
@@ -47,6 +54,7 @@ const (
 
 	jobStatusSuccess = "success"
 	jobStatusError   = "error"
+	jobStatusSkipped = "skipped"
 
 	maxJobEventErrorMessageLength = 2048
 	repositoryWriteBatchSize      = 25
@@ -64,6 +72,27 @@ func GetRepositories() ([]repository.Repository, error) {
 	return queryRepositoriesBasic(queryAllRepositories)
 }
 
+// GetRepositoriesWithColorschemes gets all repositories stored in the database
+// with their colorschemes, leaving out the ones without a colorscheme passing
+// the quality checks.
+func GetRepositoriesWithColorschemes() ([]repository.Repository, error) {
+	repositories, err := queryRepositoriesBasic(queryRepositoriesToExport)
+	if err != nil {
+		return nil, err
+	}
+
+	colorschemes, err := GetColorschemesByRepository()
+	if err != nil {
+		return nil, err
+	}
+
+	for index := range repositories {
+		repositories[index].Colorschemes = colorschemes[repositories[index].ID]
+	}
+
+	return repositories, nil
+}
+
 // GetRepository gets the repository matching the repository key.
 func GetRepository(repoKey string) (repository.Repository, error) {
 	matches := strings.Split(repoKey, "/")
@@ -88,9 +117,86 @@ func DeleteRepository(id int64) error {
 	return err
 }
 
-// GetRepositoriesToGenerate gets all repositories that are due for a preview generate.
-func GetRepositoriesToGenerate() ([]repository.Repository, error) {
-	return queryRepositoriesBasic(queryRepositoriesToGenerate)
+// GetRepositoriesToGenerate gets all repositories that are due for a preview
+// generate: never generated, pushed since, due for a retry after failing, or
+// last generated by another toolchain than the one of the run, including a
+// dependency installed at another commit than the run's. Quarantined
+// repositories are left out.
+func GetRepositoriesToGenerate(defaultRunner repository.Runner, toolchain repository.Toolchain) ([]repository.Repository, error) {
+	dependencyCommitsJSON, err := json.Marshal(dependencyCommitsValue(toolchain.DependencyCommits))
+	if err != nil {
+		return nil, err
+	}
+
+	return queryRepositoriesBasic(
+		queryRepositoriesToGenerate,
+		time.Now().UTC(),
+		defaultRunner,
+		toolchain.ExtractorSHA,
+		toolchain.LushSHA,
+		toolchain.InputsHash,
+		toolchain.VimVersion,
+		toolchain.NvimVersion,
+		string(dependencyCommitsJSON),
+	)
+}
+
+// Returns the dependency commits to store as a JSON object, never null
+func dependencyCommitsValue(commits map[string]string) map[string]string {
+	if commits == nil {
+		return map[string]string{}
+	}
+	return commits
+}
+
+// GetLastRepositoryGeneration gets the last successful generate of a
+// repository, if any.
+func GetLastRepositoryGeneration(repositoryID int64) (repository.Generation, bool, error) {
+	rows, err := queryWithTransientRetry(`
+		SELECT id, commit_sha, runner, extractor_sha, lush_sha, inputs_hash, nvim_version, vim_version, dependency_commits, created_at, changes
+		FROM repository_generations
+		WHERE repository_id = ?
+		ORDER BY id DESC
+		LIMIT 1`, repositoryID)
+	if err != nil {
+		return repository.Generation{}, false, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	if !rows.Next() {
+		return repository.Generation{}, false, rows.Err()
+	}
+
+	var generation repository.Generation
+	var dependencyCommitsJSON, changesJSON string
+	err = rows.Scan(
+		&generation.ID,
+		&generation.CommitSHA,
+		&generation.Runner,
+		&generation.Toolchain.ExtractorSHA,
+		&generation.Toolchain.LushSHA,
+		&generation.Toolchain.InputsHash,
+		&generation.Toolchain.NvimVersion,
+		&generation.Toolchain.VimVersion,
+		&dependencyCommitsJSON,
+		&generation.CreatedAt,
+		&changesJSON,
+	)
+	if err != nil {
+		return repository.Generation{}, false, err
+	}
+	if err := json.Unmarshal([]byte(dependencyCommitsJSON), &generation.Toolchain.DependencyCommits); err != nil {
+		return repository.Generation{}, false, err
+	}
+	if len(generation.Toolchain.DependencyCommits) == 0 {
+		generation.Toolchain.DependencyCommits = nil
+	}
+	if err := json.Unmarshal([]byte(changesJSON), &generation.Changes); err != nil {
+		return repository.Generation{}, false, err
+	}
+	return generation, true, nil
 }
 
 // SetRepositoryDisabled updates the manual/system scheduler override flag.
@@ -99,6 +205,13 @@ func SetRepositoryDisabled(id int64, disabled bool) error {
 	return err
 }
 
+// SetRepositoryRunner sets the runner the repository is always generated
+// with, or clears it with an empty runner to use the runner of each run.
+func SetRepositoryRunner(id int64, runner repository.Runner) error {
+	_, err := execWithTransientRetry("UPDATE repositories SET runner = ? WHERE id = ?", runner, id)
+	return err
+}
+
 // UpsertRepositoryFromImport inserts or updates a repository from import data.
 func UpsertRepositoryFromImport(data ImportData) {
 	UpsertRepositoriesFromImport([]ImportData{data})
@@ -216,7 +329,7 @@ func upsertRepositoriesFromImportBatch(data []ImportData, eventCreatedAt time.Ti
 			return err
 		}
 
-		return createRepositoryJobEventsContext(ctx, tx, values.repositoryIDs, jobImport, jobStatusSuccess, "", eventCreatedAt)
+		return createRepositoryJobEventsContext(ctx, tx, values.repositoryIDs, jobImport, jobStatusSuccess, jobEventError{}, eventCreatedAt)
 	})
 }
 
@@ -275,7 +388,7 @@ func updateRepositoriesFromUpdateBatch(updates []RepositoryUpdateData, eventCrea
 			return err
 		}
 
-		return createRepositoryJobEventsContext(ctx, tx, values.repositoryIDs, jobUpdate, jobStatusSuccess, "", eventCreatedAt)
+		return createRepositoryJobEventsContext(ctx, tx, values.repositoryIDs, jobUpdate, jobStatusSuccess, jobEventError{}, eventCreatedAt)
 	})
 }
 
@@ -384,47 +497,78 @@ func placeholders(count int) string {
 	return strings.Join(items, ", ")
 }
 
-// UpdateRepositoryFromGenerate updates a repository with generate job data.
-func UpdateRepositoryFromGenerate(id int64, data GenerateData) {
+// UpdateRepositoryFromGenerate updates a repository with generate job data,
+// rolling back every write when one fails.
+func UpdateRepositoryFromGenerate(id int64, data GenerateData) error {
 	tx, err := beginWithTransientRetry()
 	if err != nil {
-		panic(err)
+		return err
 	}
 	defer func() {
 		_ = tx.Rollback()
 	}()
 
-	_, err = tx.Exec("DELETE FROM colorschemes WHERE repository_id = ?", id)
+	// The generation and the job event share their date, see queryRepositoriesToGenerate
+	eventCreatedAt := time.Now().UTC()
+	generationID, err := insertRepositoryGeneration(tx, id, data.Generation, repository.Snapshot(data.Colorschemes), eventCreatedAt)
 	if err != nil {
-		log.Printf("Error deleting colorschemes: %s", err)
-		panic(err)
+		return fmt.Errorf("inserting repository generation: %w", err)
+	}
+
+	if err := deleteRemovedColorschemes(tx, id, data.Colorschemes); err != nil {
+		return fmt.Errorf("deleting colorschemes: %w", err)
 	}
 
+	// Hidden colorschemes don't count towards the backgrounds of the
+	// repository, the detected backgrounds of the others do
 	hasDark, hasLight := 0, 0
 	for _, scheme := range data.Colorschemes {
-		if len(scheme.Data.Dark) > 0 {
+		if scheme.Hidden {
+			continue
+		}
+		backgrounds := scheme.Backgrounds
+		if backgrounds == nil {
+			backgrounds = scheme.Data.Backgrounds()
+		}
+		if slices.Contains(backgrounds, repository.DarkBackground) {
 			hasDark = 1
 		}
-		if len(scheme.Data.Light) > 0 {
+		if slices.Contains(backgrounds, repository.LightBackground) {
 			hasLight = 1
 		}
 	}
-	_, err = tx.Exec("UPDATE repositories SET has_dark = ?, has_light = ? WHERE id = ?", hasDark, hasLight, id)
+	_, err = tx.Exec(
+		"UPDATE repositories SET has_dark = ?, has_light = ?, is_quality_eligible = ? WHERE id = ?",
+		hasDark, hasLight, repository.IsQualityEligible(data.Colorschemes), id,
+	)
 	if err != nil {
-		log.Printf("Error updating has_dark/has_light: %s", err)
-		panic(err)
+		return fmt.Errorf("updating has_dark/has_light: %w", err)
 	}
 
+	if err := clearRepositoryGenerateFailures(tx, id); err != nil {
+		return fmt.Errorf("clearing generate failures: %w", err)
+	}
+
+	var accessibilitySummaries []repository.AccessibilitySummary
+	schemeIDs := make(map[string]int64, len(data.Colorschemes))
 	for _, scheme := range data.Colorschemes {
-		result, err := tx.Exec("INSERT INTO colorschemes (repository_id, name) VALUES (?, ?)", id, scheme.Name)
+		runner := scheme.Runner
+		if runner == "" {
+			runner = repository.NeovimRunner
+		}
+		qualityReasons := scheme.Quality.Reasons
+		if qualityReasons == nil {
+			qualityReasons = []string{}
+		}
+		qualityReasonsJSON, err := json.Marshal(qualityReasons)
 		if err != nil {
-			log.Printf("Error inserting colorscheme: %s", err)
-			panic(err)
+			return err
 		}
-		schemeID, err := result.LastInsertId()
+		schemeID, err := upsertColorscheme(tx, id, scheme, runner, generationID, string(qualityReasonsJSON))
 		if err != nil {
-			panic(err)
+			return fmt.Errorf("upserting colorscheme: %w", err)
 		}
+		schemeIDs[scheme.Name] = schemeID
 		for _, bg := range []struct {
 			value  repository.BackgroundValue
 			groups []repository.ColorschemeGroup
@@ -432,197 +576,717 @@ func UpdateRepositoryFromGenerate(id int64, data GenerateData) {
 			{repository.LightBackground, scheme.Data.Light},
 			{repository.DarkBackground, scheme.Data.Dark},
 		} {
-			for _, group := range bg.groups {
-				_, err = tx.Exec(`
-					INSERT INTO colorscheme_groups (
-						colorscheme_id,
-						background,
-						name,
-						hex_code,
-						bold,
-						italic,
-						underline,
-						undercurl,
-						underdouble,
-						underdotted,
-						underdashed,
-						strikethrough,
-						reverse
-					) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
-					schemeID,
-					bg.value,
-					group.Name,
-					group.HexCode,
-					group.Bold,
-					group.Italic,
-					group.Underline,
-					group.Undercurl,
-					group.Underdouble,
-					group.Underdotted,
-					group.Underdashed,
-					group.Strikethrough,
-					group.Reverse,
-				)
-				if err != nil {
-					log.Printf("Error inserting colorscheme group: %s", err)
-					panic(err)
+			if err := insertColorschemeGroups(tx, schemeID, repository.DefaultLanguage, bg.value, bg.groups); err != nil {
+				return fmt.Errorf("inserting colorscheme group: %w", err)
+			}
+
+			if err := insertColorschemeAnalytics(tx, schemeID, bg.value, bg.groups); err != nil {
+				return fmt.Errorf("inserting colorscheme analytics: %w", err)
+			}
+
+			// Hidden colorschemes don't count towards the repository summary
+			checks := repository.AuditAccessibility(bg.groups)
+			if len(checks) > 0 && !scheme.Hidden {
+				accessibilitySummaries = append(accessibilitySummaries, repository.SummarizeAccessibility(checks))
+			}
+			if err := insertColorschemeAccessibilityChecks(tx, schemeID, bg.value, checks); err != nil {
+				return fmt.Errorf("inserting colorscheme accessibility checks: %w", err)
+			}
+		}
+
+		for _, language := range slices.Sorted(maps.Keys(scheme.Languages)) {
+			languageData := scheme.Languages[language]
+			for _, bg := range []struct {
+				value  repository.BackgroundValue
+				groups []repository.ColorschemeGroup
+			}{
+				{repository.LightBackground, languageData.Light},
+				{repository.DarkBackground, languageData.Dark},
+			} {
+				if err := insertColorschemeGroups(tx, schemeID, language, bg.value, bg.groups); err != nil {
+					return fmt.Errorf("inserting %s colorscheme group: %w", language, err)
 				}
 			}
 		}
+
+		for _, support := range scheme.PluginSupport {
+			_, err = tx.Exec(`
+				INSERT INTO colorscheme_plugin_support (
+					colorscheme_id,
+					background,
+					plugin,
+					list_version,
+					defined_group_count,
+					group_count,
+					coverage,
+					supported
+				) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+				schemeID,
+				support.Background,
+				support.Plugin,
+				support.ListVersion,
+				support.DefinedGroupCount,
+				support.GroupCount,
+				support.Coverage(),
+				support.Supported(),
+			)
+			if err != nil {
+				return fmt.Errorf("inserting colorscheme plugin support: %w", err)
+			}
+		}
+
+		for _, check := range scheme.BackgroundChecks {
+			_, err = tx.Exec(`
+				INSERT INTO colorscheme_background_checks (
+					colorscheme_id,
+					background,
+					detected_background,
+					background_luminance,
+					confidence,
+					mismatch
+				) VALUES (?, ?, ?, ?, ?, ?)`,
+				schemeID,
+				check.Background,
+				check.Detected,
+				check.BackgroundLuminance,
+				check.Confidence,
+				check.Mismatch,
+			)
+			if err != nil {
+				return fmt.Errorf("inserting colorscheme background check: %w", err)
+			}
+		}
+
+		for _, preview := range scheme.Previews {
+			_, err = tx.Exec(
+				"INSERT INTO colorscheme_previews (colorscheme_id, background, format, url) VALUES (?, ?, ?, ?)",
+				schemeID, preview.Background, preview.Format, preview.URL,
+			)
+			if err != nil {
+				return fmt.Errorf("inserting colorscheme preview: %w", err)
+			}
+		}
+	}
+
+	// Variants point to their parent once every colorscheme is inserted
+	for _, scheme := range data.Colorschemes {
+		parentID, exists := schemeIDs[scheme.Parent]
+		if scheme.Parent == "" || !exists {
+			continue
+		}
+		_, err = tx.Exec("UPDATE colorschemes SET parent_id = ? WHERE id = ?", parentID, schemeIDs[scheme.Name])
+		if err != nil {
+			return fmt.Errorf("updating colorscheme parent: %w", err)
+		}
 	}
 
-	err = createRepositoryJobEvent(tx, id, jobGenerate, jobStatusSuccess, "", time.Now().UTC())
+	accessibility := repository.BestAccessibilitySummary(accessibilitySummaries)
+	_, err = tx.Exec("UPDATE repositories SET accessibility_level = ?, accessibility_cvd_safe = ? WHERE id = ?", accessibility.Level, accessibility.CVDSafe, id)
 	if err != nil {
-		log.Printf("Error creating repository job event: %s", err)
-		panic(err)
+		return fmt.Errorf("updating accessibility summary: %w", err)
 	}
 
-	if err := tx.Commit(); err != nil {
-		panic(err)
+	if err := replaceNvimVersionResults(tx, id, data.NvimVersionResults, time.Now().UTC()); err != nil {
+		return fmt.Errorf("replacing nvim version results: %w", err)
 	}
-}
 
-type repositoryJobEventExecutor interface {
-	Exec(query string, args ...any) (sql.Result, error)
-}
+	err = createRepositoryJobEventsContext(context.Background(), tx, []int64{id}, jobGenerate, jobStatusSuccess, jobEventError{}, eventCreatedAt)
+	if err != nil {
+		return fmt.Errorf("creating repository job event: %w", err)
+	}
 
-type repositoryJobEventContextExecutor interface {
-	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	return tx.Commit()
 }
 
-// CreateRepositoryGenerateErrorEvent stores a failed generate attempt for a repository.
-func CreateRepositoryGenerateErrorEvent(repositoryID int64, errorMessage string) error {
-	return createRepositoryJobEvent(db, repositoryID, jobGenerate, jobStatusError, errorMessage, time.Now().UTC())
+// colorschemeDataTables hold the data of a colorscheme that is replaced on
+// each generate. colorscheme_similar is rebuilt by its own job.
+var colorschemeDataTables = []string{
+	"colorscheme_groups",
+	"colorscheme_analytics",
+	"colorscheme_accessibility_checks",
+	"colorscheme_background_checks",
+	"colorscheme_previews",
+	"colorscheme_plugin_support",
 }
 
-func createRepositoryJobEvent(exec repositoryJobEventExecutor, repositoryID int64, job string, status string, errorMessage string, createdAt time.Time) error {
-	if execDB, ok := exec.(*sql.DB); ok && execDB == db {
-		return createRepositoryJobEventWithRetry(repositoryID, job, status, errorMessage, createdAt)
+// Deletes the colorschemes of the repository that the generate didn't find
+// anymore, the related rows being removed by ON DELETE CASCADE
+func deleteRemovedColorschemes(tx *sql.Tx, repositoryID int64, colorschemes []repository.Colorscheme) error {
+	if len(colorschemes) == 0 {
+		_, err := tx.Exec("DELETE FROM colorschemes WHERE repository_id = ?", repositoryID)
+		return err
 	}
 
-	return createRepositoryJobEventOnce(exec, repositoryID, job, status, errorMessage, createdAt)
+	args := []any{repositoryID}
+	for _, scheme := range colorschemes {
+		args = append(args, scheme.Name)
+	}
+	_, err := tx.Exec(
+		"DELETE FROM colorschemes WHERE repository_id = ? AND name NOT IN ("+placeholders(len(colorschemes))+")",
+		args...,
+	)
+	return err
 }
 
-func createRepositoryJobEventWithRetry(repositoryID int64, job string, status string, errorMessage string, createdAt time.Time) error {
-	return runRepositoryWriteTransaction(func(ctx context.Context, tx *sql.Tx) error {
-		return createRepositoryJobEventsContext(ctx, tx, []int64{repositoryID}, job, status, errorMessage, createdAt)
-	})
+// Updates the colorscheme of the repository with the same name, clearing its
+// data, or inserts it, so colorschemes keep their ID across generations
+func upsertColorscheme(tx *sql.Tx, repositoryID int64, scheme repository.Colorscheme, runner repository.Runner, generationID int64, qualityReasonsJSON string) (int64, error) {
+	var schemeID int64
+	err := tx.QueryRow("SELECT id FROM colorschemes WHERE repository_id = ? AND name = ?", repositoryID, scheme.Name).Scan(&schemeID)
+	if errors.Is(err, sql.ErrNoRows) {
+		result, err := tx.Exec(
+			"INSERT INTO colorschemes (repository_id, name, runner, commit_sha, generation_id, variant, is_hidden, quality_score, quality_reasons) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+			repositoryID, scheme.Name, runner, scheme.CommitSHA, generationID, scheme.Variant, scheme.Hidden, scheme.Quality.Score, qualityReasonsJSON,
+		)
+		if err != nil {
+			return 0, err
+		}
+		return result.LastInsertId()
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	// The parent is set again once every colorscheme is saved
+	_, err = tx.Exec(
+		"UPDATE colorschemes SET runner = ?, commit_sha = ?, generation_id = ?, variant = ?, is_hidden = ?, quality_score = ?, quality_reasons = ?, parent_id = NULL WHERE id = ?",
+		runner, scheme.CommitSHA, generationID, scheme.Variant, scheme.Hidden, scheme.Quality.Score, qualityReasonsJSON, schemeID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	for _, table := range colorschemeDataTables {
+		if _, err := tx.Exec("DELETE FROM "+table+" WHERE colorscheme_id = ?", schemeID); err != nil {
+			return 0, err
+		}
+	}
+	return schemeID, nil
 }
 
-func createRepositoryJobEvents(exec repositoryJobEventExecutor, repositoryIDs []int64, job string, status string, errorMessage string, createdAt time.Time) error {
-	if execDB, ok := exec.(*sql.DB); ok && execDB == db {
-		return runWithTransientRetry("repository job events", func() error {
-			return createRepositoryJobEventsOnce(execDB, repositoryIDs, job, status, errorMessage, createdAt)
-		})
+// Inserts the generation with its snapshot, and the changes since the
+// snapshot of the previous generation, whose snapshot is then dropped
+func insertRepositoryGeneration(tx *sql.Tx, repositoryID int64, generation repository.Generation, snapshot map[string]repository.ColorschemeData, createdAt time.Time) (int64, error) {
+	runner := generation.Runner
+	if runner == "" {
+		runner = repository.NeovimRunner
 	}
 
-	return createRepositoryJobEventsOnce(exec, repositoryIDs, job, status, errorMessage, createdAt)
+	var previousSnapshot sql.NullString
+	err := tx.QueryRow(
+		"SELECT snapshot FROM repository_generations WHERE repository_id = ? ORDER BY id DESC LIMIT 1",
+		repositoryID,
+	).Scan(&previousSnapshot)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return 0, err
+	}
+
+	// The first generation, or the first one since snapshots are stored, has
+	// nothing to compare to
+	var changes repository.DataDiff
+	if previousSnapshot.Valid {
+		var previous map[string]repository.ColorschemeData
+		if err := json.Unmarshal([]byte(previousSnapshot.String), &previous); err != nil {
+			return 0, err
+		}
+		changes = repository.DiffData(previous, snapshot)
+	}
+
+	snapshotJSON, err := json.Marshal(snapshot)
+	if err != nil {
+		return 0, err
+	}
+	changesJSON, err := json.Marshal(changes)
+	if err != nil {
+		return 0, err
+	}
+	summaryJSON, err := json.Marshal(changes.Summary())
+	if err != nil {
+		return 0, err
+	}
+	dependencyCommitsJSON, err := json.Marshal(dependencyCommitsValue(generation.Toolchain.DependencyCommits))
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := tx.Exec(`
+		INSERT INTO repository_generations (
+			repository_id,
+			commit_sha,
+			runner,
+			extractor_sha,
+			lush_sha,
+			inputs_hash,
+			nvim_version,
+			vim_version,
+			dependency_commits,
+			created_at,
+			checked_at,
+			snapshot,
+			changes,
+			change_summary,
+			changed
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		repositoryID,
+		generation.CommitSHA,
+		runner,
+		generation.Toolchain.ExtractorSHA,
+		generation.Toolchain.LushSHA,
+		generation.Toolchain.InputsHash,
+		generation.Toolchain.NvimVersion,
+		generation.Toolchain.VimVersion,
+		string(dependencyCommitsJSON),
+		createdAt,
+		createdAt,
+		string(snapshotJSON),
+		string(changesJSON),
+		string(summaryJSON),
+		!changes.IsEmpty(),
+	)
+	if err != nil {
+		return 0, err
+	}
+	generationID, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	// Only the latest snapshot is needed to diff the next generation, the
+	// older generations keep their changes
+	_, err = tx.Exec(
+		"UPDATE repository_generations SET snapshot = NULL WHERE repository_id = ? AND id < ? AND snapshot IS NOT NULL",
+		repositoryID,
+		generationID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return generationID, nil
 }
 
-func createRepositoryJobEventsContext(ctx context.Context, exec repositoryJobEventContextExecutor, repositoryIDs []int64, job string, status string, errorMessage string, createdAt time.Time) error {
-	if len(repositoryIDs) == 0 {
+func replaceNvimVersionResults(tx *sql.Tx, repositoryID int64, results []repository.NvimVersionResult, createdAt time.Time) error {
+	if len(results) == 0 {
 		return nil
 	}
 
-	trimmedErrorMessage := errorMessage
-	if len(trimmedErrorMessage) > maxJobEventErrorMessageLength {
-		trimmedErrorMessage = trimmedErrorMessage[:maxJobEventErrorMessageLength]
+	if _, err := tx.Exec("DELETE FROM repository_nvim_versions WHERE repository_id = ?", repositoryID); err != nil {
+		return err
 	}
 
-	idArgs := make([]any, 0, len(repositoryIDs))
-	for _, repositoryID := range repositoryIDs {
-		idArgs = append(idArgs, repositoryID)
+	for _, result := range results {
+		diff, err := json.Marshal(result.Diff)
+		if err != nil {
+			return err
+		}
+
+		output, err := jobEventError{Output: result.ErrorOutput}.outputValue()
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(`
+			INSERT INTO repository_nvim_versions (
+				repository_id,
+				binary_path,
+				nvim_version,
+				success,
+				error,
+				error_code,
+				error_output,
+				colorscheme_count,
+				differs,
+				diff,
+				created_at
+			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			repositoryID,
+			result.BinaryPath,
+			result.Version,
+			result.Success,
+			result.Error,
+			result.ErrorCode,
+			output,
+			result.ColorschemeCount,
+			result.Success && !result.Diff.IsEmpty(),
+			string(diff),
+			createdAt,
+		)
+		if err != nil {
+			return err
+		}
 	}
-	idPlaceholders := placeholders(len(repositoryIDs))
 
-	if job == jobGenerate {
-		args := append([]any{createdAt}, idArgs...)
-		_, err := exec.ExecContext(
-			ctx,
-			"UPDATE repositories SET last_generate_event_at = ? WHERE id IN ("+idPlaceholders+")",
-			args...,
+	return nil
+}
+
+func insertColorschemeGroups(tx *sql.Tx, schemeID int64, language string, background repository.BackgroundValue, groups []repository.ColorschemeGroup) error {
+	for _, group := range groups {
+		_, err := tx.Exec(`
+			INSERT INTO colorscheme_groups (
+				colorscheme_id,
+				background,
+				language,
+				namespace,
+				name,
+				hex_code,
+				bold,
+				italic,
+				underline,
+				undercurl,
+				underdouble,
+				underdotted,
+				underdashed,
+				strikethrough,
+				reverse,
+				link
+			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			schemeID,
+			background,
+			language,
+			group.Namespace(),
+			group.Name,
+			group.HexCode,
+			group.Bold,
+			group.Italic,
+			group.Underline,
+			group.Undercurl,
+			group.Underdouble,
+			group.Underdotted,
+			group.Underdashed,
+			group.Strikethrough,
+			group.Reverse,
+			group.Link,
 		)
 		if err != nil {
 			return err
 		}
 	}
+	return nil
+}
 
-	insertArgs := make([]any, 0, len(repositoryIDs)*5)
-	for _, repositoryID := range repositoryIDs {
-		insertArgs = append(insertArgs, repositoryID, job, status, trimmedErrorMessage, createdAt)
+func insertColorschemeAnalytics(tx *sql.Tx, schemeID int64, background repository.BackgroundValue, groups []repository.ColorschemeGroup) error {
+	analytics, ok := repository.ComputePaletteAnalytics(groups)
+	if !ok {
+		return nil
 	}
 
-	_, err := exec.ExecContext(
-		ctx,
-		repositoryJobEventInsertQuery(len(repositoryIDs)),
-		insertArgs...,
+	_, err := tx.Exec(`
+		INSERT INTO colorscheme_analytics (
+			colorscheme_id,
+			background,
+			background_luminance,
+			contrast_ratio,
+			color_count,
+			hue_family,
+			temperature,
+			saturation,
+			saturation_level
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		schemeID,
+		background,
+		analytics.BackgroundLuminance,
+		analytics.ContrastRatio,
+		analytics.ColorCount,
+		analytics.HueFamily,
+		analytics.Temperature,
+		analytics.Saturation,
+		analytics.SaturationLevel,
 	)
 	return err
 }
 
-func createRepositoryJobEventsOnce(exec repositoryJobEventExecutor, repositoryIDs []int64, job string, status string, errorMessage string, createdAt time.Time) error {
-	if len(repositoryIDs) == 0 {
+func insertColorschemeAccessibilityChecks(tx *sql.Tx, schemeID int64, background repository.BackgroundValue, checks []repository.AccessibilityCheck) error {
+	if len(checks) == 0 {
 		return nil
 	}
 
-	trimmedErrorMessage := errorMessage
-	if len(trimmedErrorMessage) > maxJobEventErrorMessageLength {
-		trimmedErrorMessage = trimmedErrorMessage[:maxJobEventErrorMessageLength]
+	args := make([]any, 0, len(checks)*9)
+	for _, check := range checks {
+		args = append(args,
+			schemeID,
+			background,
+			check.Name,
+			check.Vision,
+			check.ForegroundColor,
+			check.BackgroundColor,
+			check.ContrastRatio,
+			check.PassesAA,
+			check.PassesAAA,
+		)
 	}
 
-	idArgs := make([]any, 0, len(repositoryIDs))
-	for _, repositoryID := range repositoryIDs {
-		idArgs = append(idArgs, repositoryID)
+	_, err := tx.Exec(`
+		INSERT INTO colorscheme_accessibility_checks (
+			colorscheme_id,
+			background,
+			name,
+			vision,
+			foreground_color,
+			background_color,
+			contrast_ratio,
+			passes_aa,
+			passes_aaa
+		) VALUES `+rowPlaceholders(len(checks), 9),
+		args...,
+	)
+	return err
+}
+
+type repositoryJobEventContextExecutor interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// RecordRepositoryGenerateFailure stores a failed generate attempt for a
+// repository with the code and editor output of the error, and schedules its
+// retry or quarantines it following the policy. Consecutive failures are
+// counted from the generate events since the last success or quarantine
+// release.
+func RecordRepositoryGenerateFailure(repositoryID int64, generateError error, policy repository.RetryPolicy) (repository.GenerateFailure, error) {
+	createdAt := time.Now().UTC()
+	eventError := jobEventError{
+		Message: generateError.Error(),
+		Code:    repository.ErrorCodeOf(generateError),
+		Output:  repository.ErrorOutputOf(generateError),
 	}
-	idPlaceholders := placeholders(len(repositoryIDs))
+	failure := repository.GenerateFailure{Reason: eventError.Code.FailureReason()}
 
-	if job == jobGenerate {
-		args := append([]any{createdAt}, idArgs...)
-		_, err := exec.Exec(
-			"UPDATE repositories SET last_generate_event_at = ? WHERE id IN ("+idPlaceholders+")",
-			args...,
-		)
+	err := runRepositoryWriteTransaction(func(ctx context.Context, tx *sql.Tx) error {
+		if err := createRepositoryJobEventsContext(ctx, tx, []int64{repositoryID}, jobGenerate, jobStatusError, eventError, createdAt); err != nil {
+			return err
+		}
+
+		err := tx.QueryRowContext(ctx, `
+			SELECT COUNT(*)
+			FROM repository_job_events e
+			JOIN repositories r ON r.id = e.repository_id
+			WHERE e.repository_id = ?
+			  AND e.job = ?
+			  AND e.status = ?
+			  AND e.created_at > COALESCE(r.quarantine_released_at, 0)
+			  AND e.created_at > COALESCE((
+				SELECT MAX(created_at)
+				FROM repository_job_events
+				WHERE repository_id = e.repository_id AND job = e.job AND status != ?
+			  ), 0)`,
+			repositoryID, jobGenerate, jobStatusError, jobStatusError,
+		).Scan(&failure.Count)
 		if err != nil {
 			return err
 		}
-	}
 
-	insertArgs := make([]any, 0, len(repositoryIDs)*5)
-	for _, repositoryID := range repositoryIDs {
-		insertArgs = append(insertArgs, repositoryID, job, status, trimmedErrorMessage, createdAt)
+		failure.RetryAt = createdAt.Add(policy.RetryDelay(failure.Count))
+		failure.Quarantined = policy.ShouldQuarantine(failure.Count)
+
+		var quarantinedAt any
+		quarantineReason := ""
+		if failure.Quarantined {
+			quarantinedAt = createdAt
+			quarantineReason = string(failure.Reason)
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			UPDATE repositories
+			SET generate_failure_count = ?,
+				generate_retry_at = ?,
+				quarantined_at = ?,
+				quarantine_reason = ?
+			WHERE id = ?`,
+			failure.Count, failure.RetryAt, quarantinedAt, quarantineReason, repositoryID,
+		)
+		return err
+	})
+	if err != nil {
+		return repository.GenerateFailure{}, err
 	}
+	return failure, nil
+}
 
-	_, err := exec.Exec(repositoryJobEventInsertQuery(len(repositoryIDs)), insertArgs...)
+// A successful generate resets the retries and releases a quarantine
+func clearRepositoryGenerateFailures(tx *sql.Tx, repositoryID int64) error {
+	_, err := tx.Exec(`
+		UPDATE repositories
+		SET generate_failure_count = 0,
+			generate_retry_at = NULL,
+			quarantined_at = NULL,
+			quarantine_reason = ''
+		WHERE id = ?`,
+		repositoryID,
+	)
 	return err
 }
 
-func createRepositoryJobEventOnce(exec repositoryJobEventExecutor, repositoryID int64, job string, status string, errorMessage string, createdAt time.Time) error {
-	trimmedErrorMessage := errorMessage
+// ReleaseQuarantinedRepositories releases the quarantined repositories pushed
+// since their quarantine, returning how many were released.
+func ReleaseQuarantinedRepositories() (int64, error) {
+	result, err := execWithTransientRetry(`
+		UPDATE repositories
+		SET quarantined_at = NULL,
+			quarantine_reason = '',
+			quarantine_released_at = ?,
+			generate_failure_count = 0,
+			generate_retry_at = NULL
+		WHERE quarantined_at IS NOT NULL
+		  AND pushed_at > quarantined_at`,
+		time.Now().UTC(),
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// QuarantinedRepository is a repository that stopped being generated after
+// failing too many times in a row.
+type QuarantinedRepository struct {
+	Repository       repository.Repository
+	FailureCount     int
+	Reason           repository.FailureReason
+	QuarantinedAt    time.Time
+	LastErrorMessage string
+}
+
+// GetQuarantinedRepositories gets the quarantined repositories, latest first.
+func GetQuarantinedRepositories() ([]QuarantinedRepository, error) {
+	rows, err := queryWithTransientRetry(`
+		SELECT ` + repositorySelectColumns + `,
+			generate_failure_count,
+			quarantine_reason,
+			quarantined_at,
+			COALESCE((
+				SELECT error_message
+				FROM repository_job_events
+				WHERE repository_id = repositories.id AND job = 'generate' AND status = 'error'
+				ORDER BY created_at DESC, id DESC
+				LIMIT 1
+			), '')
+		FROM repositories
+		WHERE quarantined_at IS NOT NULL
+		ORDER BY quarantined_at DESC, id`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var quarantined []QuarantinedRepository
+	for rows.Next() {
+		var entry QuarantinedRepository
+		repo, err := scanRepository(quarantineScanner{rows: rows, entry: &entry})
+		if err != nil {
+			return nil, err
+		}
+		entry.Repository = repo
+		quarantined = append(quarantined, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return quarantined, nil
+}
+
+// quarantineScanner scans the quarantine columns following the repository
+// columns into entry
+type quarantineScanner struct {
+	rows  *sql.Rows
+	entry *QuarantinedRepository
+}
+
+func (s quarantineScanner) Scan(dest ...interface{}) error {
+	return s.rows.Scan(append(dest,
+		&s.entry.FailureCount,
+		&s.entry.Reason,
+		&s.entry.QuarantinedAt,
+		&s.entry.LastErrorMessage,
+	)...)
+}
+
+// CreateRepositoryGenerateSkippedEvent records a generate skipped because the
+// repository is unchanged since its last generation.
+func CreateRepositoryGenerateSkippedEvent(repositoryID int64, generationID int64) error {
+	createdAt := time.Now().UTC()
+	return runRepositoryWriteTransaction(func(ctx context.Context, tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, "UPDATE repository_generations SET checked_at = ? WHERE id = ?", createdAt, generationID)
+		if err != nil {
+			return err
+		}
+		if err := clearRepositoryGenerateFailures(tx, repositoryID); err != nil {
+			return err
+		}
+		return createRepositoryJobEventsContext(ctx, tx, []int64{repositoryID}, jobGenerate, jobStatusSkipped, jobEventError{}, createdAt)
+	})
+}
+
+// jobEventError is the error stored with a failed repository job event
+type jobEventError struct {
+	Message string
+	Code    repository.ErrorCode
+	Output  repository.ErrorOutput
+}
+
+// Returns the JSON excerpts of the output, or nil when there's none
+func (eventError jobEventError) outputValue() (any, error) {
+	if eventError.Output.IsEmpty() {
+		return nil, nil
+	}
+	output, err := json.Marshal(eventError.Output.Excerpt())
+	if err != nil {
+		return nil, err
+	}
+	return string(output), nil
+}
+
+func createRepositoryJobEventsContext(ctx context.Context, exec repositoryJobEventContextExecutor, repositoryIDs []int64, job string, status string, eventError jobEventError, createdAt time.Time) error {
+	if len(repositoryIDs) == 0 {
+		return nil
+	}
+
+	trimmedErrorMessage := eventError.Message
 	if len(trimmedErrorMessage) > maxJobEventErrorMessageLength {
 		trimmedErrorMessage = trimmedErrorMessage[:maxJobEventErrorMessageLength]
 	}
 
+	errorOutput, err := eventError.outputValue()
+	if err != nil {
+		return err
+	}
+
+	idArgs := make([]any, 0, len(repositoryIDs))
+	for _, repositoryID := range repositoryIDs {
+		idArgs = append(idArgs, repositoryID)
+	}
+	idPlaceholders := placeholders(len(repositoryIDs))
+
 	if job == jobGenerate {
-		_, err := exec.Exec(
-			"UPDATE repositories SET last_generate_event_at = ? WHERE id = ?",
-			createdAt,
-			repositoryID,
+		args := append([]any{createdAt}, idArgs...)
+		_, err := exec.ExecContext(
+			ctx,
+			"UPDATE repositories SET last_generate_event_at = ? WHERE id IN ("+idPlaceholders+")",
+			args...,
 		)
 		if err != nil {
 			return err
 		}
 	}
 
-	_, err := exec.Exec(repositoryJobEventInsertQuery(1), repositoryID, job, status, trimmedErrorMessage, createdAt)
+	insertArgs := make([]any, 0, len(repositoryIDs)*7)
+	for _, repositoryID := range repositoryIDs {
+		insertArgs = append(insertArgs, repositoryID, job, status, trimmedErrorMessage, eventError.Code, errorOutput, createdAt)
+	}
+
+	_, err = exec.ExecContext(
+		ctx,
+		repositoryJobEventInsertQuery(len(repositoryIDs)),
+		insertArgs...,
+	)
 	return err
 }
 
 func repositoryJobEventInsertQuery(rowCount int) string {
-	return `WITH event_values(repository_id, job, status, error_message, created_at) AS (
-			VALUES ` + rowPlaceholders(rowCount, 5) + `
+	return `WITH event_values(repository_id, job, status, error_message, error_code, error_output, created_at) AS (
+			VALUES ` + rowPlaceholders(rowCount, 7) + `
 		)
-		INSERT INTO repository_job_events (repository_id, job, status, error_message, created_at)
-		SELECT DISTINCT repository_id, job, status, error_message, created_at
+		INSERT INTO repository_job_events (repository_id, job, status, error_message, error_code, error_output, created_at)
+		SELECT DISTINCT repository_id, job, status, error_message, error_code, error_output, created_at
 		FROM event_values
 		WHERE NOT EXISTS (
 			SELECT 1
@@ -17,7 +17,8 @@ const (
 		pushed_at,
 		is_eligible,
 		is_disabled,
-		updated_at
+		updated_at,
+		runner
 	`
 
 	queryRepositoryByOwnerAndName = `
@@ -33,12 +34,49 @@ const (
 		WHERE is_disabled = 0
 	`
 
+	// Repositories whose colorschemes are all hidden for failing the quality
+	// checks have nothing to export
+	queryRepositoriesToExport = `
+		SELECT ` + repositorySelectColumns + `
+		FROM repositories
+		WHERE is_disabled = 0
+		  AND is_quality_eligible = 1
+	`
+
+	// Repositories that aren't quality eligible are still generated, to
+	// become eligible again once their colorschemes pass the quality checks
 	queryRepositoriesToGenerate = `
 		SELECT ` + repositorySelectColumns + `
 		FROM repositories
 		WHERE is_disabled = 0
 		  AND is_eligible = 1
-		  AND (last_generate_event_at IS NULL OR pushed_at > last_generate_event_at)
+		  AND quarantined_at IS NULL
+		  AND (
+			last_generate_event_at IS NULL
+			OR pushed_at > last_generate_event_at
+			-- The retry delay after the last failures is over
+			OR generate_retry_at <= ?
+			-- The last generate succeeded, with another toolchain
+			OR EXISTS (
+				SELECT 1
+				FROM repository_generations g
+				WHERE g.id = (SELECT MAX(id) FROM repository_generations WHERE repository_id = repositories.id)
+				  AND g.checked_at >= repositories.last_generate_event_at
+				  AND (
+					g.runner != COALESCE(NULLIF(repositories.runner, ''), ?)
+					OR g.extractor_sha != ?
+					OR g.lush_sha != ?
+					OR g.inputs_hash != ?
+					OR CASE g.runner WHEN 'vim' THEN g.vim_version != ? ELSE g.nvim_version != ? END
+					OR EXISTS (
+						SELECT 1
+						FROM json_each(g.dependency_commits) installed
+						JOIN json_each(?) current ON current.key = installed.key
+						WHERE current.value != installed.value
+					)
+				  )
+			)
+		  )
 	`
 )
 
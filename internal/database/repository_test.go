@@ -3,8 +3,11 @@ package database
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -20,6 +23,13 @@ func insertTestRepo(t *testing.T, id int64, ownerName, name string) {
 	}
 }
 
+func updateTestRepoFromGenerate(t *testing.T, id int64, data GenerateData) {
+	t.Helper()
+	if err := UpdateRepositoryFromGenerate(id, data); err != nil {
+		t.Fatalf("UpdateRepositoryFromGenerate: %v", err)
+	}
+}
+
 func countSearchMatches(t *testing.T, term string) int {
 	t.Helper()
 
@@ -298,6 +308,17 @@ func TestRepositoriesSearchTriggers(t *testing.T) {
 			t.Fatalf("create colorschemes table: %v", err)
 		}
 
+		if _, err := unmigratedDB.Exec(`CREATE TABLE repository_job_events (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			repository_id INTEGER NOT NULL REFERENCES repositories(id) ON DELETE CASCADE,
+			job TEXT NOT NULL,
+			status TEXT NOT NULL DEFAULT 'success',
+			error_message TEXT,
+			created_at DATETIME NOT NULL
+		)`); err != nil {
+			t.Fatalf("create repository_job_events table: %v", err)
+		}
+
 		if _, err := unmigratedDB.Exec(`CREATE TABLE colorscheme_groups (
 			id INTEGER PRIMARY KEY AUTOINCREMENT,
 			colorscheme_id INTEGER NOT NULL REFERENCES colorschemes(id) ON DELETE CASCADE,
@@ -417,6 +438,25 @@ func TestGetRepositories(t *testing.T) {
 	})
 }
 
+func TestGetRepositoriesWithColorschemes(t *testing.T) {
+	t.Run("excludes repositories that aren't quality eligible", func(t *testing.T) {
+		setupTestDB(t)
+		insertTestRepo(t, 1, "owner1", "repo1")
+		insertTestRepo(t, 2, "owner2", "repo2")
+
+		updateTestRepoFromGenerate(t, 1, GenerateData{Colorschemes: []repository.Colorscheme{{Name: "passing"}}})
+		updateTestRepoFromGenerate(t, 2, GenerateData{Colorschemes: []repository.Colorscheme{{Name: "failing", Hidden: true}}})
+
+		repos, err := GetRepositoriesWithColorschemes()
+		if err != nil {
+			t.Fatalf("GetRepositoriesWithColorschemes returned error: %v", err)
+		}
+		if len(repos) != 1 || repos[0].ID != 1 || len(repos[0].Colorschemes) != 1 {
+			t.Fatalf("repos = %+v, want repo1 with its colorscheme", repos)
+		}
+	})
+}
+
 func TestCreateRepositoryJobEventsContext(t *testing.T) {
 	t.Run("does not duplicate events for the same logical retry", func(t *testing.T) {
 		setupTestDB(t)
@@ -430,7 +470,7 @@ func TestCreateRepositoryJobEventsContext(t *testing.T) {
 				t.Fatalf("begin tx attempt %d: %v", attempt, err)
 			}
 
-			err = createRepositoryJobEventsContext(ctx, tx, []int64{1, 1}, jobImport, jobStatusSuccess, "", createdAt)
+			err = createRepositoryJobEventsContext(ctx, tx, []int64{1, 1}, jobImport, jobStatusSuccess, jobEventError{}, createdAt)
 			if err != nil {
 				_ = tx.Rollback()
 				t.Fatalf("create events attempt %d: %v", attempt, err)
@@ -554,7 +594,7 @@ func TestGetRepositoriesToGenerate(t *testing.T) {
 		insertRepoForGenerate(t, 1, 1, base.Add(time.Hour))
 		insertGenerateEvent(t, 1, base)
 
-		repos, err := GetRepositoriesToGenerate()
+		repos, err := GetRepositoriesToGenerate(repository.NeovimRunner, repository.Toolchain{})
 		if err != nil {
 			t.Fatalf("GetRepositoriesToGenerate returned error: %v", err)
 		}
@@ -571,7 +611,7 @@ func TestGetRepositoriesToGenerate(t *testing.T) {
 		insertRepoForGenerate(t, 1, 0, base.Add(time.Hour))
 		insertGenerateEvent(t, 1, base)
 
-		repos, err := GetRepositoriesToGenerate()
+		repos, err := GetRepositoriesToGenerate(repository.NeovimRunner, repository.Toolchain{})
 		if err != nil {
 			t.Fatalf("GetRepositoriesToGenerate returned error: %v", err)
 		}
@@ -585,7 +625,7 @@ func TestGetRepositoriesToGenerate(t *testing.T) {
 		insertRepoForGenerate(t, 1, 1, base)
 		insertGenerateEvent(t, 1, base.Add(time.Hour))
 
-		repos, err := GetRepositoriesToGenerate()
+		repos, err := GetRepositoriesToGenerate(repository.NeovimRunner, repository.Toolchain{})
 		if err != nil {
 			t.Fatalf("GetRepositoriesToGenerate returned error: %v", err)
 		}
@@ -603,7 +643,7 @@ func TestGetRepositoriesToGenerate(t *testing.T) {
 			t.Fatalf("SetRepositoryDisabled: %v", err)
 		}
 
-		repos, err := GetRepositoriesToGenerate()
+		repos, err := GetRepositoriesToGenerate(repository.NeovimRunner, repository.Toolchain{})
 		if err != nil {
 			t.Fatalf("GetRepositoriesToGenerate returned error: %v", err)
 		}
@@ -616,7 +656,7 @@ func TestGetRepositoriesToGenerate(t *testing.T) {
 		setupTestDB(t)
 		insertRepoForGenerate(t, 1, 1, base)
 
-		repos, err := GetRepositoriesToGenerate()
+		repos, err := GetRepositoriesToGenerate(repository.NeovimRunner, repository.Toolchain{})
 		if err != nil {
 			t.Fatalf("GetRepositoriesToGenerate returned error: %v", err)
 		}
@@ -624,6 +664,75 @@ func TestGetRepositoriesToGenerate(t *testing.T) {
 			t.Fatalf("len(repos) = %d, want 1", len(repos))
 		}
 	})
+
+	t.Run("includes repos last generated with another toolchain", func(t *testing.T) {
+		setupTestDB(t)
+		insertRepoForGenerate(t, 1, 1, base)
+
+		toolchain := repository.Toolchain{ExtractorSHA: "e1", LushSHA: "l1", NvimVersion: "NVIM v0.10.4", InputsHash: "i1", DependencyCommits: map[string]string{"lush.nvim": "d1"}}
+		updateTestRepoFromGenerate(t, 1, GenerateData{
+			Generation: repository.Generation{CommitSHA: "c1", Runner: repository.NeovimRunner, Toolchain: toolchain},
+		})
+
+		countRepos := func(toolchain repository.Toolchain) int {
+			t.Helper()
+			repos, err := GetRepositoriesToGenerate(repository.NeovimRunner, toolchain)
+			if err != nil {
+				t.Fatalf("GetRepositoriesToGenerate returned error: %v", err)
+			}
+			return len(repos)
+		}
+
+		newToolchain := toolchain
+		newToolchain.NvimVersion = "NVIM v0.11.0"
+		otherEditor := toolchain
+		otherEditor.VimVersion = "VIM - Vi IMproved 9.1"
+		newInputs := toolchain
+		newInputs.InputsHash = "i2"
+		newDependency := toolchain
+		newDependency.DependencyCommits = map[string]string{"lush.nvim": "d2"}
+		otherDependency := toolchain
+		otherDependency.DependencyCommits = map[string]string{"plenary.nvim": "p1"}
+
+		if count := countRepos(toolchain); count != 0 {
+			t.Fatalf("len(repos) = %d with the same toolchain, want 0", count)
+		}
+		if count := countRepos(otherEditor); count != 0 {
+			t.Fatalf("len(repos) = %d with another vim version, want 0", count)
+		}
+		if count := countRepos(newToolchain); count != 1 {
+			t.Fatalf("len(repos) = %d with a new nvim version, want 1", count)
+		}
+		if count := countRepos(newInputs); count != 1 {
+			t.Fatalf("len(repos) = %d with new extraction inputs, want 1", count)
+		}
+		if count := countRepos(newDependency); count != 1 {
+			t.Fatalf("len(repos) = %d with a new dependency commit, want 1", count)
+		}
+		if count := countRepos(otherDependency); count != 0 {
+			t.Fatalf("len(repos) = %d with another dependency, want 0", count)
+		}
+
+		generation, exists, err := GetLastRepositoryGeneration(1)
+		if err != nil || !exists {
+			t.Fatalf("GetLastRepositoryGeneration = %v, %v", exists, err)
+		}
+		time.Sleep(time.Millisecond)
+		if err := CreateRepositoryGenerateSkippedEvent(1, generation.ID); err != nil {
+			t.Fatalf("CreateRepositoryGenerateSkippedEvent: %v", err)
+		}
+		if count := countRepos(newToolchain); count != 1 {
+			t.Fatalf("len(repos) = %d after a skipped generate, want 1", count)
+		}
+
+		time.Sleep(time.Millisecond)
+		if _, err := RecordRepositoryGenerateFailure(1, errors.New("nvim crashed"), repository.RetryPolicy{BaseDelay: time.Hour, MaxDelay: time.Hour}); err != nil {
+			t.Fatalf("RecordRepositoryGenerateFailure: %v", err)
+		}
+		if count := countRepos(newToolchain); count != 0 {
+			t.Fatalf("len(repos) = %d after a failed generate, want 0", count)
+		}
+	})
 }
 
 func TestUpdateRepositoryFromUpdate(t *testing.T) {
@@ -792,7 +901,7 @@ func TestUpdateRepositoryFromGenerate(t *testing.T) {
 		setupTestDB(t)
 		insertTestRepo(t, 1, "owner", "repo")
 
-		UpdateRepositoryFromGenerate(1, GenerateData{
+		updateTestRepoFromGenerate(t, 1, GenerateData{
 			Colorschemes: []repository.Colorscheme{
 				{
 					Name: "myscheme",
@@ -846,14 +955,548 @@ func TestUpdateRepositoryFromGenerate(t *testing.T) {
 		}
 	})
 
+	t.Run("saves palette analytics", func(t *testing.T) {
+		setupTestDB(t)
+		insertTestRepo(t, 1, "owner", "repo")
+
+		updateTestRepoFromGenerate(t, 1, GenerateData{
+			Colorschemes: []repository.Colorscheme{
+				{
+					Name: "myscheme",
+					Data: repository.ColorschemeData{
+						Light: []repository.ColorschemeGroup{{Name: "NormalFg", HexCode: "#000000"}},
+						Dark: []repository.ColorschemeGroup{
+							{Name: "NormalBg", HexCode: "#000000"},
+							{Name: "NormalFg", HexCode: "#ffffff"},
+						},
+					},
+				},
+			},
+		})
+
+		var background string
+		var contrastRatio float64
+		var colorCount int
+		err := db.QueryRow(`SELECT background, contrast_ratio, color_count FROM colorscheme_analytics`).Scan(&background, &contrastRatio, &colorCount)
+		if err != nil {
+			t.Fatalf("query row: %v", err)
+		}
+		if background != "dark" {
+			t.Fatalf("background = %q, want %q", background, "dark")
+		}
+		if contrastRatio < 20.9 {
+			t.Fatalf("contrastRatio = %f, want 21", contrastRatio)
+		}
+		if colorCount != 2 {
+			t.Fatalf("colorCount = %d, want 2", colorCount)
+		}
+	})
+
+	t.Run("sets the repository backgrounds from the detected backgrounds", func(t *testing.T) {
+		setupTestDB(t)
+		insertTestRepo(t, 1, "owner", "repo")
+
+		updateTestRepoFromGenerate(t, 1, GenerateData{
+			Colorschemes: []repository.Colorscheme{
+				{
+					Name:        "mislabeled",
+					Data:        repository.ColorschemeData{Light: []repository.ColorschemeGroup{{Name: "NormalBg", HexCode: "#000000"}}},
+					Backgrounds: []repository.BackgroundValue{repository.DarkBackground},
+				},
+			},
+		})
+
+		var hasDark, hasLight bool
+		if err := db.QueryRow(`SELECT has_dark, has_light FROM repositories WHERE id = 1`).Scan(&hasDark, &hasLight); err != nil {
+			t.Fatalf("query row: %v", err)
+		}
+		if !hasDark || hasLight {
+			t.Fatalf("has_dark = %v, has_light = %v, want only dark", hasDark, hasLight)
+		}
+	})
+
+	t.Run("hides colorschemes failing quality checks", func(t *testing.T) {
+		setupTestDB(t)
+		insertTestRepo(t, 1, "owner", "repo")
+
+		updateTestRepoFromGenerate(t, 1, GenerateData{
+			Colorschemes: []repository.Colorscheme{
+				{
+					Name:    "broken",
+					Data:    repository.ColorschemeData{Dark: []repository.ColorschemeGroup{{Name: "NormalFg", HexCode: "#ffffff"}}},
+					Quality: repository.Quality{Score: 0.5, Reasons: []string{"dark: 1 groups, below 10"}},
+					Hidden:  true,
+				},
+			},
+		})
+
+		var hasDark, isQualityEligible bool
+		if err := db.QueryRow(`SELECT has_dark, is_quality_eligible FROM repositories WHERE id = 1`).Scan(&hasDark, &isQualityEligible); err != nil {
+			t.Fatalf("query row: %v", err)
+		}
+		if hasDark || isQualityEligible {
+			t.Fatalf("has_dark = %v, is_quality_eligible = %v, want both false", hasDark, isQualityEligible)
+		}
+
+		schemes, err := loadColorschemes(1)
+		if err != nil {
+			t.Fatalf("loadColorschemes: %v", err)
+		}
+		if len(schemes) != 1 || !schemes[0].Hidden || schemes[0].Quality.Score != 0.5 || len(schemes[0].Quality.Reasons) != 1 {
+			t.Fatalf("colorschemes = %+v, want the hidden colorscheme with its quality", schemes)
+		}
+	})
+
+	t.Run("saves background checks", func(t *testing.T) {
+		setupTestDB(t)
+		insertTestRepo(t, 1, "owner", "repo")
+
+		data := repository.ColorschemeData{
+			Light: []repository.ColorschemeGroup{{Name: "NormalBg", HexCode: "#1d2021"}},
+			Dark:  []repository.ColorschemeGroup{{Name: "NormalBg", HexCode: "#282828"}},
+		}
+		updateTestRepoFromGenerate(t, 1, GenerateData{
+			Colorschemes: []repository.Colorscheme{
+				{Name: "myscheme", Data: data, BackgroundChecks: repository.ValidateBackgrounds(data)},
+			},
+		})
+
+		var detected, mismatch string
+		var confidence float64
+		err := db.QueryRow(`SELECT detected_background, confidence, mismatch FROM colorscheme_background_checks WHERE background = 'light'`).Scan(&detected, &confidence, &mismatch)
+		if err != nil {
+			t.Fatalf("query row: %v", err)
+		}
+		if detected != "dark" || mismatch != "luminance" || confidence <= 0 {
+			t.Fatalf("light check = %s, %s, %f, want a dark luminance mismatch", detected, mismatch, confidence)
+		}
+	})
+
+	t.Run("saves accessibility checks and repository summary", func(t *testing.T) {
+		setupTestDB(t)
+		insertTestRepo(t, 1, "owner", "repo")
+
+		updateTestRepoFromGenerate(t, 1, GenerateData{
+			Colorschemes: []repository.Colorscheme{
+				{
+					Name: "myscheme",
+					Data: repository.ColorschemeData{
+						Dark: []repository.ColorschemeGroup{
+							{Name: "NormalBg", HexCode: "#000000"},
+							{Name: "NormalFg", HexCode: "#ffffff"},
+						},
+					},
+				},
+			},
+		})
+
+		var checkCount int
+		err := db.QueryRow(`SELECT COUNT(*) FROM colorscheme_accessibility_checks WHERE background = 'dark' AND name = 'normal' AND passes_aaa = 1`).Scan(&checkCount)
+		if err != nil {
+			t.Fatalf("query row: %v", err)
+		}
+		if checkCount != 4 {
+			t.Fatalf("checkCount = %d, want 4", checkCount)
+		}
+
+		var level string
+		var cvdSafe bool
+		err = db.QueryRow(`SELECT accessibility_level, accessibility_cvd_safe FROM repositories WHERE id = 1`).Scan(&level, &cvdSafe)
+		if err != nil {
+			t.Fatalf("query row: %v", err)
+		}
+		if level != "aaa" || !cvdSafe {
+			t.Fatalf("accessibility = %q, %v, want %q, true", level, cvdSafe, "aaa")
+		}
+	})
+
+	t.Run("leaves hidden colorschemes out of the repository summary", func(t *testing.T) {
+		setupTestDB(t)
+		insertTestRepo(t, 1, "owner", "repo")
+
+		updateTestRepoFromGenerate(t, 1, GenerateData{
+			Colorschemes: []repository.Colorscheme{
+				{
+					Name: "visible",
+					Data: repository.ColorschemeData{
+						Dark: []repository.ColorschemeGroup{
+							{Name: "NormalBg", HexCode: "#000000"},
+							{Name: "NormalFg", HexCode: "#333333"},
+						},
+					},
+				},
+				{
+					Name:   "hidden",
+					Hidden: true,
+					Data: repository.ColorschemeData{
+						Dark: []repository.ColorschemeGroup{
+							{Name: "NormalBg", HexCode: "#000000"},
+							{Name: "NormalFg", HexCode: "#ffffff"},
+						},
+					},
+				},
+			},
+		})
+
+		var level string
+		if err := db.QueryRow(`SELECT accessibility_level FROM repositories WHERE id = 1`).Scan(&level); err != nil {
+			t.Fatalf("query row: %v", err)
+		}
+		if level != string(repository.AccessibilityLevelNone) {
+			t.Fatalf("accessibility_level = %q, want %q", level, repository.AccessibilityLevelNone)
+		}
+	})
+
+	t.Run("preserves group links", func(t *testing.T) {
+		setupTestDB(t)
+		insertTestRepo(t, 1, "owner", "repo")
+
+		groups := []repository.ColorschemeGroup{
+			{Name: "ConstantFg", HexCode: "#ff0000"},
+			{Name: "StringFg", HexCode: "#ff0000", Link: "Constant"},
+			{Name: "@stringFg", Link: "String"},
+		}
+		updateTestRepoFromGenerate(t, 1, GenerateData{
+			Colorschemes: []repository.Colorscheme{
+				{Name: "myscheme", Data: repository.ColorschemeData{Dark: groups}},
+			},
+		})
+
+		schemes, err := loadColorschemes(1)
+		if err != nil {
+			t.Fatalf("loadColorschemes: %v", err)
+		}
+		if !reflect.DeepEqual(schemes[0].Data.Dark, groups) {
+			t.Fatalf("Data.Dark = %#v, want %#v", schemes[0].Data.Dark, groups)
+		}
+	})
+
+	t.Run("saves group namespaces", func(t *testing.T) {
+		setupTestDB(t)
+		insertTestRepo(t, 1, "owner", "repo")
+
+		updateTestRepoFromGenerate(t, 1, GenerateData{
+			Colorschemes: []repository.Colorscheme{
+				{
+					Name: "myscheme",
+					Data: repository.ColorschemeData{
+						Dark: []repository.ColorschemeGroup{
+							{Name: "CommentFg", HexCode: "#777777"},
+							{Name: "@function.builtinFg", HexCode: "#ff0000"},
+							{Name: "@lsp.type.functionFg", HexCode: "#00ff00"},
+						},
+					},
+				},
+			},
+		})
+
+		rows, err := db.Query(`SELECT name, namespace FROM colorscheme_groups ORDER BY id`)
+		if err != nil {
+			t.Fatalf("query: %v", err)
+		}
+		defer func() {
+			_ = rows.Close()
+		}()
+
+		namespaces := map[string]string{}
+		for rows.Next() {
+			var name, namespace string
+			if err := rows.Scan(&name, &namespace); err != nil {
+				t.Fatalf("scan: %v", err)
+			}
+			namespaces[name] = namespace
+		}
+
+		expected := map[string]string{
+			"CommentFg":            "syntax",
+			"@function.builtinFg":  "treesitter",
+			"@lsp.type.functionFg": "lsp",
+		}
+		if !reflect.DeepEqual(namespaces, expected) {
+			t.Fatalf("namespaces = %v, want %v", namespaces, expected)
+		}
+	})
+
+	t.Run("saves language group sets", func(t *testing.T) {
+		setupTestDB(t)
+		insertTestRepo(t, 1, "owner", "repo")
+
+		languages := map[string]repository.ColorschemeData{
+			"lua":    {Dark: []repository.ColorschemeGroup{{Name: "@keywordFg", HexCode: "#ff0000"}}},
+			"python": {Light: []repository.ColorschemeGroup{{Name: "@functionFg", HexCode: "#00ff00"}}},
+		}
+		updateTestRepoFromGenerate(t, 1, GenerateData{
+			Colorschemes: []repository.Colorscheme{
+				{
+					Name:      "myscheme",
+					Data:      repository.ColorschemeData{Dark: []repository.ColorschemeGroup{{Name: "NormalBg", HexCode: "#000000"}}},
+					Languages: languages,
+				},
+			},
+		})
+
+		repo, err := GetRepository("owner/repo")
+		if err != nil {
+			t.Fatalf("GetRepository: %v", err)
+		}
+		scheme := repo.Colorschemes[0]
+		if len(scheme.Data.Dark) != 1 || scheme.Data.Dark[0].Name != "NormalBg" {
+			t.Fatalf("Data.Dark = %#v, want only NormalBg", scheme.Data.Dark)
+		}
+		if !reflect.DeepEqual(scheme.Languages, languages) {
+			t.Fatalf("Languages = %#v, want %#v", scheme.Languages, languages)
+		}
+		if !reflect.DeepEqual(scheme.Backgrounds, []repository.BackgroundValue{repository.DarkBackground}) {
+			t.Fatalf("Backgrounds = %v, want [dark]", scheme.Backgrounds)
+		}
+	})
+
+	t.Run("saves plugin support", func(t *testing.T) {
+		setupTestDB(t)
+		insertTestRepo(t, 1, "owner", "repo")
+
+		updateTestRepoFromGenerate(t, 1, GenerateData{
+			Colorschemes: []repository.Colorscheme{
+				{
+					Name: "myscheme",
+					PluginSupport: []repository.PluginSupport{
+						{Background: repository.DarkBackground, Plugin: "telescope.nvim", ListVersion: 1, DefinedGroupCount: 6, GroupCount: 8},
+						{Background: repository.DarkBackground, Plugin: "nvim-cmp", ListVersion: 1, DefinedGroupCount: 1, GroupCount: 8},
+					},
+				},
+			},
+		})
+
+		var supported []string
+		rows, err := db.Query(`SELECT plugin FROM colorscheme_plugin_support WHERE background = 'dark' AND supported = 1`)
+		if err != nil {
+			t.Fatalf("query: %v", err)
+		}
+		defer func() {
+			_ = rows.Close()
+		}()
+		for rows.Next() {
+			var plugin string
+			if err := rows.Scan(&plugin); err != nil {
+				t.Fatalf("scan: %v", err)
+			}
+			supported = append(supported, plugin)
+		}
+		if !reflect.DeepEqual(supported, []string{"telescope.nvim"}) {
+			t.Fatalf("supported = %v, want [telescope.nvim]", supported)
+		}
+
+		var coverage float64
+		if err := db.QueryRow(`SELECT coverage FROM colorscheme_plugin_support WHERE plugin = 'telescope.nvim'`).Scan(&coverage); err != nil {
+			t.Fatalf("query row: %v", err)
+		}
+		if coverage != 0.75 {
+			t.Fatalf("coverage = %v, want 0.75", coverage)
+		}
+	})
+
+	t.Run("replaces nvim version results", func(t *testing.T) {
+		setupTestDB(t)
+		insertTestRepo(t, 1, "owner", "repo")
+
+		updateTestRepoFromGenerate(t, 1, GenerateData{
+			NvimVersionResults: []repository.NvimVersionResult{
+				{Version: "NVIM v0.9.5", BinaryPath: "/opt/nvim-0.9/bin/nvim", Error: "preview generation timed out after 30s"},
+			},
+		})
+		updateTestRepoFromGenerate(t, 1, GenerateData{
+			NvimVersionResults: []repository.NvimVersionResult{
+				{
+					Version:          "NVIM v0.10.2",
+					BinaryPath:       "/opt/nvim-0.10/bin/nvim",
+					Success:          true,
+					ColorschemeCount: 1,
+					Diff:             repository.DataDiff{RemovedColorschemes: []string{"myscheme-lua"}},
+				},
+			},
+		})
+		// Runs without the version matrix keep the previous results
+		updateTestRepoFromGenerate(t, 1, GenerateData{})
+
+		var count int
+		if err := db.QueryRow(`SELECT COUNT(*) FROM repository_nvim_versions WHERE repository_id = 1`).Scan(&count); err != nil {
+			t.Fatalf("query row: %v", err)
+		}
+		if count != 1 {
+			t.Fatalf("count = %d, want 1", count)
+		}
+
+		var version, diff string
+		var success, differs bool
+		err := db.QueryRow(`SELECT nvim_version, success, differs, diff FROM repository_nvim_versions WHERE repository_id = 1`).Scan(&version, &success, &differs, &diff)
+		if err != nil {
+			t.Fatalf("query row: %v", err)
+		}
+		if version != "NVIM v0.10.2" || !success || !differs || diff != `{"removedColorschemes":["myscheme-lua"]}` {
+			t.Fatalf("result = %q, %v, %v, %s", version, success, differs, diff)
+		}
+	})
+
+	t.Run("saves the output of failed nvim versions", func(t *testing.T) {
+		setupTestDB(t)
+		insertTestRepo(t, 1, "owner", "repo")
+
+		updateTestRepoFromGenerate(t, 1, GenerateData{
+			NvimVersionResults: []repository.NvimVersionResult{
+				{
+					Version:     "NVIM v0.9.5",
+					BinaryPath:  "/opt/nvim-0.9/bin/nvim",
+					Error:       "module 'lush' not found",
+					ErrorCode:   repository.LuaError,
+					ErrorOutput: repository.ErrorOutput{ErrorMessage: "E5108: module 'lush' not found"},
+				},
+			},
+		})
+
+		var errorCode, errorOutput string
+		err := db.QueryRow(`SELECT error_code, error_output FROM repository_nvim_versions WHERE repository_id = 1`).Scan(&errorCode, &errorOutput)
+		if err != nil {
+			t.Fatalf("query row: %v", err)
+		}
+		if errorCode != "lua_error" || errorOutput != `{"errorMessage":"E5108: module 'lush' not found"}` {
+			t.Fatalf("error = %q, %s", errorCode, errorOutput)
+		}
+	})
+
+	t.Run("saves colorscheme runners", func(t *testing.T) {
+		setupTestDB(t)
+		insertTestRepo(t, 1, "owner", "repo")
+
+		updateTestRepoFromGenerate(t, 1, GenerateData{
+			Colorschemes: []repository.Colorscheme{
+				{Name: "vimscheme", Runner: repository.VimRunner},
+				{Name: "defaultscheme"},
+			},
+		})
+
+		repo, err := GetRepository("owner/repo")
+		if err != nil {
+			t.Fatalf("GetRepository: %v", err)
+		}
+		runners := map[string]repository.Runner{}
+		for _, scheme := range repo.Colorschemes {
+			runners[scheme.Name] = scheme.Runner
+		}
+		expected := map[string]repository.Runner{"vimscheme": repository.VimRunner, "defaultscheme": repository.NeovimRunner}
+		if !reflect.DeepEqual(runners, expected) {
+			t.Fatalf("runners = %v, want %v", runners, expected)
+		}
+	})
+
+	t.Run("saves the generation", func(t *testing.T) {
+		setupTestDB(t)
+		insertTestRepo(t, 1, "owner", "repo")
+
+		if _, exists, err := GetLastRepositoryGeneration(1); err != nil || exists {
+			t.Fatalf("GetLastRepositoryGeneration = %v, %v, want no generation", exists, err)
+		}
+
+		expected := repository.Generation{
+			CommitSHA: "c2",
+			Runner:    repository.VimRunner,
+			Toolchain: repository.Toolchain{ExtractorSHA: "e1", LushSHA: "l1", NvimVersion: "NVIM v0.10.4", VimVersion: "VIM - Vi IMproved 9.0", DependencyCommits: map[string]string{"lush.nvim": "d1"}},
+		}
+		updateTestRepoFromGenerate(t, 1, GenerateData{Generation: repository.Generation{CommitSHA: "c1"}})
+		updateTestRepoFromGenerate(t, 1, GenerateData{Generation: expected})
+
+		generation, exists, err := GetLastRepositoryGeneration(1)
+		if err != nil || !exists {
+			t.Fatalf("GetLastRepositoryGeneration = %v, %v", exists, err)
+		}
+		if generation.ID == 0 || generation.CreatedAt.IsZero() {
+			t.Fatalf("generation = %+v, want an ID and a date", generation)
+		}
+		generation.ID = 0
+		generation.CreatedAt = time.Time{}
+		if !reflect.DeepEqual(generation, expected) {
+			t.Fatalf("generation = %+v, want %+v", generation, expected)
+		}
+	})
+
+	t.Run("saves colorscheme commit SHAs", func(t *testing.T) {
+		setupTestDB(t)
+		insertTestRepo(t, 1, "owner", "repo")
+
+		commitSHA := "0f6a1e2a8c3b9d4e5f60718293a4b5c6d7e8f901"
+		updateTestRepoFromGenerate(t, 1, GenerateData{
+			Colorschemes: []repository.Colorscheme{{Name: "myscheme", CommitSHA: commitSHA}},
+		})
+
+		repo, err := GetRepository("owner/repo")
+		if err != nil {
+			t.Fatalf("GetRepository: %v", err)
+		}
+		if len(repo.Colorschemes) != 1 || repo.Colorschemes[0].CommitSHA != commitSHA {
+			t.Fatalf("colorschemes = %+v, want commit SHA %s", repo.Colorschemes, commitSHA)
+		}
+	})
+
+	t.Run("saves colorscheme variants", func(t *testing.T) {
+		setupTestDB(t)
+		insertTestRepo(t, 1, "owner", "repo")
+
+		updateTestRepoFromGenerate(t, 1, GenerateData{
+			Colorschemes: []repository.Colorscheme{{Name: "myscheme-hard", Variant: "hard", Parent: "myscheme"}, {Name: "myscheme"}},
+		})
+
+		repo, err := GetRepository("owner/repo")
+		if err != nil {
+			t.Fatalf("GetRepository: %v", err)
+		}
+		if len(repo.Colorschemes) != 2 || repo.Colorschemes[0].Variant != "hard" || repo.Colorschemes[0].Parent != "myscheme" ||
+			repo.Colorschemes[1].Variant != "" || repo.Colorschemes[1].Parent != "" {
+			t.Fatalf("colorschemes = %+v, want the hard variant of myscheme", repo.Colorschemes)
+		}
+
+		var parentID, expectedParentID int64
+		if err := db.QueryRow(`SELECT parent_id FROM colorschemes WHERE name = 'myscheme-hard'`).Scan(&parentID); err != nil {
+			t.Fatalf("query parent_id: %v", err)
+		}
+		if err := db.QueryRow(`SELECT id FROM colorschemes WHERE name = 'myscheme'`).Scan(&expectedParentID); err != nil {
+			t.Fatalf("query id: %v", err)
+		}
+		if parentID != expectedParentID {
+			t.Fatalf("parent_id = %d, want %d", parentID, expectedParentID)
+		}
+	})
+
+	t.Run("saves colorscheme previews", func(t *testing.T) {
+		setupTestDB(t)
+		insertTestRepo(t, 1, "owner", "repo")
+
+		previews := []repository.Preview{
+			{Background: repository.DarkBackground, Format: "svg", URL: "https://previews.example.com/owner/repo/myscheme-dark.svg"},
+			{Background: repository.DarkBackground, Format: "png", URL: "https://previews.example.com/owner/repo/myscheme-dark.png"},
+		}
+		updateTestRepoFromGenerate(t, 1, GenerateData{
+			Colorschemes: []repository.Colorscheme{{Name: "myscheme", Previews: previews}},
+		})
+
+		repo, err := GetRepository("owner/repo")
+		if err != nil {
+			t.Fatalf("GetRepository: %v", err)
+		}
+		if len(repo.Colorschemes) != 1 {
+			t.Fatalf("Colorschemes len = %d, want 1", len(repo.Colorschemes))
+		}
+		if !reflect.DeepEqual(repo.Colorschemes[0].Previews, previews) {
+			t.Fatalf("Previews = %#v, want %#v", repo.Colorschemes[0].Previews, previews)
+		}
+	})
+
 	t.Run("replaces existing colorschemes", func(t *testing.T) {
 		setupTestDB(t)
 		insertTestRepo(t, 1, "owner", "repo")
 
-		UpdateRepositoryFromGenerate(1, GenerateData{
+		updateTestRepoFromGenerate(t, 1, GenerateData{
 			Colorschemes: []repository.Colorscheme{{Name: "scheme1"}},
 		})
-		UpdateRepositoryFromGenerate(1, GenerateData{
+		updateTestRepoFromGenerate(t, 1, GenerateData{
 			Colorschemes: []repository.Colorscheme{{Name: "scheme2"}},
 		})
 
@@ -869,11 +1512,94 @@ func TestUpdateRepositoryFromGenerate(t *testing.T) {
 		}
 	})
 
+	t.Run("keeps the IDs of colorschemes found again", func(t *testing.T) {
+		setupTestDB(t)
+		insertTestRepo(t, 1, "owner", "repo")
+
+		dark := func(hexCode string) repository.ColorschemeData {
+			return repository.ColorschemeData{Dark: []repository.ColorschemeGroup{{Name: "NormalBg", HexCode: hexCode}}}
+		}
+		updateTestRepoFromGenerate(t, 1, GenerateData{
+			Colorschemes: []repository.Colorscheme{{Name: "scheme1", Data: dark("#000000")}, {Name: "scheme2"}},
+		})
+		first, err := GetRepository("owner/repo")
+		if err != nil {
+			t.Fatalf("GetRepository: %v", err)
+		}
+
+		updateTestRepoFromGenerate(t, 1, GenerateData{
+			Colorschemes: []repository.Colorscheme{{Name: "scheme1", Data: dark("#111111")}},
+		})
+		second, err := GetRepository("owner/repo")
+		if err != nil {
+			t.Fatalf("GetRepository: %v", err)
+		}
+
+		if len(second.Colorschemes) != 1 || second.Colorschemes[0].ID != first.Colorschemes[0].ID {
+			t.Fatalf("colorschemes = %+v, want scheme1 with ID %d", second.Colorschemes, first.Colorschemes[0].ID)
+		}
+		if groups := second.Colorschemes[0].Data.Dark; len(groups) != 1 || groups[0].HexCode != "#111111" {
+			t.Fatalf("Data.Dark = %+v, want the groups of the last generate", groups)
+		}
+	})
+
+	t.Run("keeps only the snapshot of the last generation", func(t *testing.T) {
+		setupTestDB(t)
+		insertTestRepo(t, 1, "owner", "repo")
+
+		for _, name := range []string{"scheme1", "scheme2", "scheme3"} {
+			updateTestRepoFromGenerate(t, 1, GenerateData{Colorschemes: []repository.Colorscheme{{Name: name}}})
+		}
+
+		var generationCount, snapshotCount int
+		err := db.QueryRow(`SELECT COUNT(*), COUNT(snapshot) FROM repository_generations WHERE repository_id = 1`).Scan(&generationCount, &snapshotCount)
+		if err != nil {
+			t.Fatalf("query generations: %v", err)
+		}
+		if generationCount != 3 || snapshotCount != 1 {
+			t.Fatalf("generations = %d, snapshots = %d, want 3 and 1", generationCount, snapshotCount)
+		}
+
+		generation, _, err := GetLastRepositoryGeneration(1)
+		if err != nil {
+			t.Fatalf("GetLastRepositoryGeneration: %v", err)
+		}
+		if generation.Changes.IsEmpty() {
+			t.Fatal("Changes is empty, want the changes since the pruned snapshot")
+		}
+	})
+
+	t.Run("returns the write error and rolls back", func(t *testing.T) {
+		setupTestDB(t)
+		insertTestRepo(t, 1, "owner", "repo")
+		if _, err := db.Exec(`DROP TABLE colorscheme_previews`); err != nil {
+			t.Fatalf("drop colorscheme_previews: %v", err)
+		}
+
+		err := UpdateRepositoryFromGenerate(1, GenerateData{
+			Colorschemes: []repository.Colorscheme{{
+				Name:     "myscheme",
+				Previews: []repository.Preview{{Background: repository.DarkBackground, Format: "svg", URL: "https://previews.example.com/myscheme-dark.svg"}},
+			}},
+		})
+		if err == nil {
+			t.Fatal("UpdateRepositoryFromGenerate returned no error")
+		}
+
+		var colorschemeCount, generationCount int
+		if err := db.QueryRow(`SELECT (SELECT COUNT(*) FROM colorschemes), (SELECT COUNT(*) FROM repository_generations)`).Scan(&colorschemeCount, &generationCount); err != nil {
+			t.Fatalf("query counts: %v", err)
+		}
+		if colorschemeCount != 0 || generationCount != 0 {
+			t.Fatalf("colorschemes = %d, generations = %d, want the writes rolled back", colorschemeCount, generationCount)
+		}
+	})
+
 	t.Run("creates a generate job event", func(t *testing.T) {
 		setupTestDB(t)
 		insertTestRepo(t, 1, "owner", "repo")
 
-		UpdateRepositoryFromGenerate(1, GenerateData{})
+		updateTestRepoFromGenerate(t, 1, GenerateData{})
 
 		var eventCount int
 		err := db.QueryRow(`SELECT COUNT(*) FROM repository_job_events WHERE repository_id = 1 AND job = 'generate' AND status = 'success'`).Scan(&eventCount)
@@ -889,8 +1615,8 @@ func TestUpdateRepositoryFromGenerate(t *testing.T) {
 		setupTestDB(t)
 		insertTestRepo(t, 1, "owner", "repo")
 
-		UpdateRepositoryFromGenerate(1, GenerateData{})
-		UpdateRepositoryFromGenerate(1, GenerateData{})
+		updateTestRepoFromGenerate(t, 1, GenerateData{})
+		updateTestRepoFromGenerate(t, 1, GenerateData{})
 
 		var eventCount int
 		err := db.QueryRow(`SELECT COUNT(*) FROM repository_job_events WHERE repository_id = 1 AND job = 'generate' AND status = 'success'`).Scan(&eventCount)
@@ -907,9 +1633,9 @@ func TestUpdateRepositoryFromGenerate(t *testing.T) {
 		insertTestRepo(t, 1, "owner", "repo")
 
 		longError := strings.Repeat("x", 3000)
-		err := CreateRepositoryGenerateErrorEvent(1, longError)
+		_, err := RecordRepositoryGenerateFailure(1, errors.New(longError), repository.RetryPolicy{})
 		if err != nil {
-			t.Fatalf("CreateRepositoryGenerateErrorEvent: %v", err)
+			t.Fatalf("RecordRepositoryGenerateFailure: %v", err)
 		}
 
 		var status, errorMessage string
@@ -929,14 +1655,14 @@ func TestUpdateRepositoryFromGenerate(t *testing.T) {
 		setupTestDB(t)
 		insertTestRepo(t, 1, "owner", "repo")
 
-		if err := CreateRepositoryGenerateErrorEvent(1, "first"); err != nil {
-			t.Fatalf("CreateRepositoryGenerateErrorEvent: %v", err)
+		if _, err := RecordRepositoryGenerateFailure(1, errors.New("first"), repository.RetryPolicy{}); err != nil {
+			t.Fatalf("RecordRepositoryGenerateFailure: %v", err)
 		}
-		if err := CreateRepositoryGenerateErrorEvent(1, "second"); err != nil {
-			t.Fatalf("CreateRepositoryGenerateErrorEvent: %v", err)
+		if _, err := RecordRepositoryGenerateFailure(1, errors.New("second"), repository.RetryPolicy{}); err != nil {
+			t.Fatalf("RecordRepositoryGenerateFailure: %v", err)
 		}
-		UpdateRepositoryFromGenerate(1, GenerateData{})
-		UpdateRepositoryFromGenerate(1, GenerateData{})
+		updateTestRepoFromGenerate(t, 1, GenerateData{})
+		updateTestRepoFromGenerate(t, 1, GenerateData{})
 
 		var errorCount int
 		err := db.QueryRow(`SELECT COUNT(*) FROM repository_job_events WHERE repository_id = 1 AND job = 'generate' AND status = 'error'`).Scan(&errorCount)
@@ -957,3 +1683,191 @@ func TestUpdateRepositoryFromGenerate(t *testing.T) {
 		}
 	})
 }
+
+func TestSetRepositoryRunner(t *testing.T) {
+	setupTestDB(t)
+	insertTestRepo(t, 1, "owner", "repo")
+
+	if err := SetRepositoryRunner(1, repository.VimRunner); err != nil {
+		t.Fatalf("SetRepositoryRunner: %v", err)
+	}
+
+	repo, err := GetRepository("owner/repo")
+	if err != nil {
+		t.Fatalf("GetRepository: %v", err)
+	}
+	if repo.Runner != repository.VimRunner {
+		t.Fatalf("Runner = %q, want %q", repo.Runner, repository.VimRunner)
+	}
+}
+
+func TestRecordRepositoryGenerateFailure(t *testing.T) {
+	insertEligibleRepo := func(t *testing.T, pushedAt time.Time) {
+		t.Helper()
+		_, err := db.Exec(`INSERT INTO repositories (id, owner_name, name, is_eligible, pushed_at) VALUES (1, 'owner', 'repo', 1, ?)`, pushedAt)
+		if err != nil {
+			t.Fatalf("insert repo: %v", err)
+		}
+	}
+
+	countReposToGenerate := func(t *testing.T) int {
+		t.Helper()
+		repos, err := GetRepositoriesToGenerate(repository.NeovimRunner, repository.Toolchain{})
+		if err != nil {
+			t.Fatalf("GetRepositoriesToGenerate returned error: %v", err)
+		}
+		return len(repos)
+	}
+
+	recordFailure := func(t *testing.T, code repository.ErrorCode, message string, policy repository.RetryPolicy) repository.GenerateFailure {
+		t.Helper()
+		failure, err := RecordRepositoryGenerateFailure(1, repository.NewGenerateError(code, errors.New(message)), policy)
+		if err != nil {
+			t.Fatalf("RecordRepositoryGenerateFailure: %v", err)
+		}
+		return failure
+	}
+
+	pushedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("stores the error code", func(t *testing.T) {
+		setupTestDB(t)
+		insertEligibleRepo(t, pushedAt)
+
+		generateError := repository.NewGenerateError(repository.CloneNotFoundError, errors.New("installing owner__repo failed"))
+		failure, err := RecordRepositoryGenerateFailure(1, generateError, repository.RetryPolicy{})
+		if err != nil {
+			t.Fatalf("RecordRepositoryGenerateFailure: %v", err)
+		}
+		if failure.Reason != repository.CloneFailure {
+			t.Fatalf("failure.Reason = %s, want %s", failure.Reason, repository.CloneFailure)
+		}
+
+		var errorCode string
+		var errorOutput sql.NullString
+		if err := db.QueryRow(`SELECT error_code, error_output FROM repository_job_events WHERE repository_id = 1`).Scan(&errorCode, &errorOutput); err != nil {
+			t.Fatalf("query error_code: %v", err)
+		}
+		if errorCode != string(repository.CloneNotFoundError) {
+			t.Fatalf("error_code = %q, want %q", errorCode, repository.CloneNotFoundError)
+		}
+		if errorOutput.Valid {
+			t.Fatalf("error_output = %q, want NULL without editor output", errorOutput.String)
+		}
+	})
+
+	t.Run("stores excerpts of the editor output", func(t *testing.T) {
+		setupTestDB(t)
+		insertEligibleRepo(t, pushedAt)
+
+		output := repository.ErrorOutput{
+			Colorscheme:  "mytest",
+			Stderr:       strings.Repeat("x", repository.MaxOutputExcerptLength) + "E5113: Error while calling lua chunk",
+			ErrorMessage: "E5113: Error while calling lua chunk",
+		}
+		generateError := repository.WithErrorOutput(repository.NewGenerateError(repository.NvimCrashError, errors.New("preview generation failed: exit status 1")), output)
+		if _, err := RecordRepositoryGenerateFailure(1, generateError, repository.RetryPolicy{}); err != nil {
+			t.Fatalf("RecordRepositoryGenerateFailure: %v", err)
+		}
+
+		var errorCode string
+		var errorOutput string
+		if err := db.QueryRow(`SELECT error_code, error_output FROM repository_job_events WHERE repository_id = 1`).Scan(&errorCode, &errorOutput); err != nil {
+			t.Fatalf("query error_output: %v", err)
+		}
+		var stored repository.ErrorOutput
+		if err := json.Unmarshal([]byte(errorOutput), &stored); err != nil {
+			t.Fatalf("unmarshal error_output: %v", err)
+		}
+		if errorCode != string(repository.NvimCrashError) || stored.Colorscheme != "mytest" || stored.ErrorMessage != output.ErrorMessage {
+			t.Fatalf("error_code = %q, error_output = %+v", errorCode, stored)
+		}
+		if len(stored.Stderr) != repository.MaxOutputExcerptLength || !strings.HasSuffix(stored.Stderr, "lua chunk") {
+			t.Fatalf("len(stderr) = %d, want the last %d bytes", len(stored.Stderr), repository.MaxOutputExcerptLength)
+		}
+	})
+
+	t.Run("backs off exponentially until the retry is due", func(t *testing.T) {
+		setupTestDB(t)
+		insertEligibleRepo(t, pushedAt)
+		policy := repository.RetryPolicy{BaseDelay: time.Hour, MaxDelay: 24 * time.Hour}
+
+		first := recordFailure(t, repository.NvimTimeoutError, "preview generation timed out after 30s", policy)
+		second := recordFailure(t, repository.NvimTimeoutError, "preview generation timed out after 30s", policy)
+		if first.Count != 1 || second.Count != 2 || second.Reason != repository.TimeoutFailure {
+			t.Fatalf("failures = %+v, %+v, want 2 consecutive timeouts", first, second)
+		}
+		if delay := second.RetryAt.Sub(time.Now()); delay < time.Hour || delay > 2*time.Hour {
+			t.Fatalf("retry in %s, want 2 hours", delay)
+		}
+		if count := countReposToGenerate(t); count != 0 {
+			t.Fatalf("len(repos) = %d before the retry is due, want 0", count)
+		}
+
+		recordFailure(t, repository.NvimTimeoutError, "preview generation timed out after 30s", repository.RetryPolicy{})
+		if count := countReposToGenerate(t); count != 1 {
+			t.Fatalf("len(repos) = %d once the retry is due, want 1", count)
+		}
+	})
+
+	t.Run("quarantines until a push releases the repository", func(t *testing.T) {
+		setupTestDB(t)
+		insertEligibleRepo(t, pushedAt)
+		policy := repository.RetryPolicy{QuarantineAfter: 2}
+
+		if failure := recordFailure(t, repository.CloneNotFoundError, "installing owner__repo failed: exit status 128", policy); failure.Quarantined {
+			t.Fatal("the first failure should not quarantine")
+		}
+		failure := recordFailure(t, repository.CloneNotFoundError, "installing owner__repo failed: exit status 128", policy)
+		if !failure.Quarantined || failure.Reason != repository.CloneFailure {
+			t.Fatalf("failure = %+v, want a clone_error quarantine", failure)
+		}
+		if count := countReposToGenerate(t); count != 0 {
+			t.Fatalf("len(repos) = %d while quarantined, want 0", count)
+		}
+
+		quarantined, err := GetQuarantinedRepositories()
+		if err != nil {
+			t.Fatalf("GetQuarantinedRepositories: %v", err)
+		}
+		if len(quarantined) != 1 || quarantined[0].Repository.ID != 1 || quarantined[0].FailureCount != 2 ||
+			quarantined[0].Reason != repository.CloneFailure || quarantined[0].LastErrorMessage != "installing owner__repo failed: exit status 128" {
+			t.Fatalf("quarantined = %+v", quarantined)
+		}
+
+		if released, err := ReleaseQuarantinedRepositories(); err != nil || released != 0 {
+			t.Fatalf("ReleaseQuarantinedRepositories = %d, %v, want nothing released before a push", released, err)
+		}
+
+		if _, err := db.Exec(`UPDATE repositories SET pushed_at = ? WHERE id = 1`, time.Now().UTC().Add(time.Hour)); err != nil {
+			t.Fatalf("update pushed_at: %v", err)
+		}
+		if released, err := ReleaseQuarantinedRepositories(); err != nil || released != 1 {
+			t.Fatalf("ReleaseQuarantinedRepositories = %d, %v, want 1", released, err)
+		}
+		if count := countReposToGenerate(t); count != 1 {
+			t.Fatalf("len(repos) = %d after a push, want 1", count)
+		}
+
+		if failure := recordFailure(t, repository.NvimCrashError, "preview generation failed: exit status 1", policy); failure.Count != 1 || failure.Quarantined {
+			t.Fatalf("failure = %+v, want the count to restart after a release", failure)
+		}
+	})
+
+	t.Run("resets the failures after a success", func(t *testing.T) {
+		setupTestDB(t)
+		insertEligibleRepo(t, pushedAt)
+		policy := repository.RetryPolicy{QuarantineAfter: 1}
+
+		recordFailure(t, repository.InvalidJSONError, "unexpected end of JSON input", policy)
+		updateTestRepoFromGenerate(t, 1, GenerateData{})
+
+		quarantined, err := GetQuarantinedRepositories()
+		if err != nil || len(quarantined) != 0 {
+			t.Fatalf("GetQuarantinedRepositories = %v, %v, want none", quarantined, err)
+		}
+		if failure := recordFailure(t, repository.InvalidJSONError, "unexpected end of JSON input", repository.RetryPolicy{}); failure.Count != 1 || failure.Reason != repository.EmptyDataFailure {
+			t.Fatalf("failure = %+v, want the first empty_data failure", failure)
+		}
+	})
+}
@@ -0,0 +1,117 @@
+package database
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/vimcolorschemes/worker/internal/dependencies"
+	"github.com/vimcolorschemes/worker/internal/scan"
+)
+
+// UpdateRepositoryScan stores the static analysis of a commit of a repository,
+// replacing the previous one.
+func UpdateRepositoryScan(repositoryID int64, commitSHA string, result scan.Result) error {
+	values := make([]any, 0, 4)
+	for _, value := range []any{result.Files, result.Frameworks, result.Dependencies, result.Backgrounds} {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		values = append(values, string(encoded))
+	}
+
+	_, err := execWithTransientRetry(`
+		INSERT INTO repository_scans (repository_id, commit_sha, files, frameworks, dependencies, backgrounds, reads_background, scanned_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(repository_id) DO UPDATE SET
+			commit_sha = excluded.commit_sha,
+			files = excluded.files,
+			frameworks = excluded.frameworks,
+			dependencies = excluded.dependencies,
+			backgrounds = excluded.backgrounds,
+			reads_background = excluded.reads_background,
+			scanned_at = excluded.scanned_at`,
+		repositoryID, commitSHA, values[0], values[1], values[2], values[3], result.ReadsBackground, time.Now().UTC(),
+	)
+	return err
+}
+
+// GetRepositoryScan gets the last static analysis of a repository, if any.
+func GetRepositoryScan(repositoryID int64) (scan.Result, bool, error) {
+	rows, err := queryWithTransientRetry(`
+		SELECT files, frameworks, dependencies, backgrounds, reads_background
+		FROM repository_scans
+		WHERE repository_id = ?`, repositoryID)
+	if err != nil {
+		return scan.Result{}, false, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	if !rows.Next() {
+		return scan.Result{}, false, rows.Err()
+	}
+
+	var result scan.Result
+	var files, frameworks, dependencies, backgrounds string
+	if err := rows.Scan(&files, &frameworks, &dependencies, &backgrounds, &result.ReadsBackground); err != nil {
+		return scan.Result{}, false, err
+	}
+	for _, column := range []struct {
+		value  string
+		target any
+	}{
+		{files, &result.Files},
+		{frameworks, &result.Frameworks},
+		{dependencies, &result.Dependencies},
+		{backgrounds, &result.Backgrounds},
+	} {
+		if err := json.Unmarshal([]byte(column.value), column.target); err != nil {
+			return scan.Result{}, false, errors.Join(errors.New("error parsing repository scan"), err)
+		}
+	}
+	return result, true, nil
+}
+
+// UpdateRepositoryDependencies stores the dependencies installed to extract
+// the scanned commit of a repository
+func UpdateRepositoryDependencies(repositoryID int64, resolved []dependencies.Resolved) error {
+	if resolved == nil {
+		resolved = []dependencies.Resolved{}
+	}
+	encoded, err := json.Marshal(resolved)
+	if err != nil {
+		return err
+	}
+
+	_, err = execWithTransientRetry(`UPDATE repository_scans SET resolved_dependencies = ? WHERE repository_id = ?`, string(encoded), repositoryID)
+	return err
+}
+
+// GetRepositoryDependencies gets the dependencies installed for the last
+// scanned commit of a repository
+func GetRepositoryDependencies(repositoryID int64) ([]dependencies.Resolved, error) {
+	rows, err := queryWithTransientRetry(`SELECT resolved_dependencies FROM repository_scans WHERE repository_id = ?`, repositoryID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	if !rows.Next() {
+		return nil, rows.Err()
+	}
+
+	var value string
+	if err := rows.Scan(&value); err != nil {
+		return nil, err
+	}
+	var resolved []dependencies.Resolved
+	if err := json.Unmarshal([]byte(value), &resolved); err != nil {
+		return nil, errors.Join(errors.New("error parsing repository dependencies"), err)
+	}
+	return resolved, nil
+}
@@ -0,0 +1,76 @@
+package database
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/vimcolorschemes/worker/internal/dependencies"
+	"github.com/vimcolorschemes/worker/internal/scan"
+)
+
+func TestUpdateRepositoryScan(t *testing.T) {
+	setupTestDB(t)
+	insertTestRepo(t, 1, "owner", "repo")
+
+	if _, found, err := GetRepositoryScan(1); err != nil || found {
+		t.Fatalf("GetRepositoryScan = found %v, error %v, want nothing", found, err)
+	}
+
+	if err := UpdateRepositoryScan(1, "abc", scan.Result{Files: []string{"colors/old.vim"}}); err != nil {
+		t.Fatalf("UpdateRepositoryScan: %v", err)
+	}
+	want := scan.Result{
+		Files:           []string{"colors/new.lua"},
+		Frameworks:      []scan.Framework{scan.LushFramework},
+		Dependencies:    []string{"lush"},
+		Backgrounds:     []string{"dark"},
+		ReadsBackground: true,
+	}
+	if err := UpdateRepositoryScan(1, "def", want); err != nil {
+		t.Fatalf("UpdateRepositoryScan: %v", err)
+	}
+
+	got, found, err := GetRepositoryScan(1)
+	if err != nil || !found {
+		t.Fatalf("GetRepositoryScan = found %v, error %v, want the scan", found, err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("GetRepositoryScan = %+v, want %+v", got, want)
+	}
+
+	var commitSHA string
+	if err := db.QueryRow(`SELECT commit_sha FROM repository_scans WHERE repository_id = 1`).Scan(&commitSHA); err != nil {
+		t.Fatalf("select commit_sha: %v", err)
+	}
+	if commitSHA != "def" {
+		t.Fatalf("commit_sha = %q, want def", commitSHA)
+	}
+}
+
+func TestUpdateRepositoryDependencies(t *testing.T) {
+	setupTestDB(t)
+	insertTestRepo(t, 1, "owner", "repo")
+
+	if err := UpdateRepositoryScan(1, "abc", scan.Result{Files: []string{"colors/theme.lua"}, Dependencies: []string{"colorbuddy"}}); err != nil {
+		t.Fatalf("UpdateRepositoryScan: %v", err)
+	}
+	want := []dependencies.Resolved{{Name: "colorbuddy.nvim", Repository: "tjdevries/colorbuddy.nvim", Commit: "def"}}
+	if err := UpdateRepositoryDependencies(1, want); err != nil {
+		t.Fatalf("UpdateRepositoryDependencies: %v", err)
+	}
+
+	got, err := GetRepositoryDependencies(1)
+	if err != nil {
+		t.Fatalf("GetRepositoryDependencies: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("GetRepositoryDependencies = %+v, want %+v", got, want)
+	}
+
+	if err := UpdateRepositoryDependencies(1, nil); err != nil {
+		t.Fatalf("UpdateRepositoryDependencies: %v", err)
+	}
+	if got, err := GetRepositoryDependencies(1); err != nil || len(got) != 0 {
+		t.Fatalf("GetRepositoryDependencies = %+v, %v, want none", got, err)
+	}
+}
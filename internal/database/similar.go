@@ -0,0 +1,137 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/vimcolorschemes/worker/internal/repository"
+)
+
+const similarColorschemeWriteBatchSize = 500
+
+// GetColorschemeGroupsByRepository gets the visible colorschemes keyed by
+// repository id, with only the named groups of the default language and the
+// groups they link to.
+func GetColorschemeGroupsByRepository(names []string) (map[int64][]repository.Colorscheme, error) {
+	if len(names) == 0 {
+		return map[int64][]repository.Colorscheme{}, nil
+	}
+
+	args := []any{repository.DefaultLanguage}
+	for _, name := range names {
+		args = append(args, name)
+	}
+	args = append(args, repository.DefaultLanguage)
+
+	// Links keep the Fg, Bg or Sp suffix of the group they start from, and
+	// UNION stops on link loops
+	rows, err := queryWithTransientRetry(`
+		WITH RECURSIVE linked_groups(colorscheme_id, background, name, hex_code, link, suffix) AS (
+			SELECT csg.colorscheme_id, csg.background, csg.name, csg.hex_code, csg.link, substr(csg.name, -2)
+			FROM colorscheme_groups csg
+			JOIN colorschemes cs ON cs.id = csg.colorscheme_id
+			WHERE cs.is_hidden = 0 AND csg.language = ? AND csg.name IN (`+placeholders(len(names))+`)
+			UNION
+			SELECT csg.colorscheme_id, csg.background, csg.name, csg.hex_code, csg.link, lg.suffix
+			FROM linked_groups lg
+			JOIN colorscheme_groups csg
+				ON csg.colorscheme_id = lg.colorscheme_id
+				AND csg.background = lg.background
+				AND csg.language = ?
+				AND csg.name = lg.link || lg.suffix
+			WHERE lg.hex_code = '' AND lg.link != ''
+		)
+		SELECT DISTINCT cs.repository_id, lg.colorscheme_id, lg.background, lg.name, lg.hex_code, lg.link
+		FROM linked_groups lg
+		JOIN colorschemes cs ON cs.id = lg.colorscheme_id
+		ORDER BY lg.colorscheme_id, lg.name`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	indexes := map[int64]int{}
+	schemes := map[int64][]repository.Colorscheme{}
+	for rows.Next() {
+		var repositoryID, colorschemeID int64
+		var background repository.BackgroundValue
+		var group repository.ColorschemeGroup
+		if err := rows.Scan(&repositoryID, &colorschemeID, &background, &group.Name, &group.HexCode, &group.Link); err != nil {
+			return nil, err
+		}
+
+		index, exists := indexes[colorschemeID]
+		if !exists {
+			schemes[repositoryID] = append(schemes[repositoryID], repository.Colorscheme{ID: colorschemeID})
+			index = len(schemes[repositoryID]) - 1
+			indexes[colorschemeID] = index
+		}
+		scheme := &schemes[repositoryID][index]
+		scheme.Data = appendColorschemeGroup(scheme.Data, background, group)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return schemes, nil
+}
+
+// ReplaceSimilarColorschemes replaces all stored colorscheme neighbors.
+func ReplaceSimilarColorschemes(similar []repository.SimilarColorscheme) error {
+	return runRepositoryWriteTransaction(func(ctx context.Context, tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM colorscheme_similar"); err != nil {
+			return err
+		}
+
+		for start := 0; start < len(similar); start += similarColorschemeWriteBatchSize {
+			end := min(start+similarColorschemeWriteBatchSize, len(similar))
+			batch := similar[start:end]
+
+			args := make([]any, 0, len(batch)*5)
+			for _, item := range batch {
+				args = append(args, item.ColorschemeID, item.Background, item.SimilarColorschemeID, item.Rank, item.Distance)
+			}
+
+			_, err := tx.ExecContext(ctx, `INSERT INTO colorscheme_similar (colorscheme_id, background, similar_colorscheme_id, rank, distance)
+				VALUES `+rowPlaceholders(len(batch), 5), args...)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// GetSimilarColorschemes gets the stored neighbors of a colorscheme background, nearest first.
+func GetSimilarColorschemes(colorschemeID int64, background repository.BackgroundValue) ([]repository.SimilarColorscheme, error) {
+	rows, err := queryWithTransientRetry(`
+		SELECT similar_colorscheme_id, rank, distance
+		FROM colorscheme_similar
+		WHERE colorscheme_id = ? AND background = ?
+		ORDER BY rank`, colorschemeID, background)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var similar []repository.SimilarColorscheme
+	for rows.Next() {
+		item := repository.SimilarColorscheme{ColorschemeID: colorschemeID, Background: background}
+		if err := rows.Scan(&item.SimilarColorschemeID, &item.Rank, &item.Distance); err != nil {
+			return nil, err
+		}
+		similar = append(similar, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return similar, nil
+}
@@ -0,0 +1,126 @@
+package database
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/vimcolorschemes/worker/internal/repository"
+)
+
+func TestReplaceSimilarColorschemes(t *testing.T) {
+	setupSimilarColorschemes := func(t *testing.T) {
+		t.Helper()
+		setupTestDB(t)
+		insertTestRepo(t, 1, "owner", "repo1")
+		insertTestRepo(t, 2, "owner", "repo2")
+		if _, err := db.Exec(`INSERT INTO colorschemes (id, repository_id, name) VALUES (1, 1, 'first'), (2, 2, 'second'), (3, 2, 'third')`); err != nil {
+			t.Fatalf("insert colorschemes: %v", err)
+		}
+	}
+
+	t.Run("stores neighbors ordered by rank", func(t *testing.T) {
+		setupSimilarColorschemes(t)
+
+		err := ReplaceSimilarColorschemes([]repository.SimilarColorscheme{
+			{ColorschemeID: 1, Background: repository.DarkBackground, SimilarColorschemeID: 3, Rank: 2, Distance: 4.5},
+			{ColorschemeID: 1, Background: repository.DarkBackground, SimilarColorschemeID: 2, Rank: 1, Distance: 1.5},
+			{ColorschemeID: 1, Background: repository.LightBackground, SimilarColorschemeID: 3, Rank: 1, Distance: 2},
+		})
+		if err != nil {
+			t.Fatalf("ReplaceSimilarColorschemes: %v", err)
+		}
+
+		similar, err := GetSimilarColorschemes(1, repository.DarkBackground)
+		if err != nil {
+			t.Fatalf("GetSimilarColorschemes: %v", err)
+		}
+		if len(similar) != 2 {
+			t.Fatalf("len(similar) = %d, want 2", len(similar))
+		}
+		if similar[0].SimilarColorschemeID != 2 || similar[1].SimilarColorschemeID != 3 {
+			t.Fatalf("similar = %+v, want neighbors 2 then 3", similar)
+		}
+	})
+
+	t.Run("replaces previous neighbors", func(t *testing.T) {
+		setupSimilarColorschemes(t)
+
+		if err := ReplaceSimilarColorschemes([]repository.SimilarColorscheme{
+			{ColorschemeID: 1, Background: repository.DarkBackground, SimilarColorschemeID: 2, Rank: 1, Distance: 1},
+		}); err != nil {
+			t.Fatalf("ReplaceSimilarColorschemes: %v", err)
+		}
+		if err := ReplaceSimilarColorschemes([]repository.SimilarColorscheme{
+			{ColorschemeID: 1, Background: repository.DarkBackground, SimilarColorschemeID: 3, Rank: 1, Distance: 1},
+		}); err != nil {
+			t.Fatalf("ReplaceSimilarColorschemes: %v", err)
+		}
+
+		similar, err := GetSimilarColorschemes(1, repository.DarkBackground)
+		if err != nil {
+			t.Fatalf("GetSimilarColorschemes: %v", err)
+		}
+		if len(similar) != 1 || similar[0].SimilarColorschemeID != 3 {
+			t.Fatalf("similar = %+v, want only neighbor 3", similar)
+		}
+	})
+
+	t.Run("removes neighbors when a colorscheme is deleted", func(t *testing.T) {
+		setupSimilarColorschemes(t)
+
+		if err := ReplaceSimilarColorschemes([]repository.SimilarColorscheme{
+			{ColorschemeID: 1, Background: repository.DarkBackground, SimilarColorschemeID: 2, Rank: 1, Distance: 1},
+		}); err != nil {
+			t.Fatalf("ReplaceSimilarColorschemes: %v", err)
+		}
+
+		updateTestRepoFromGenerate(t, 2, GenerateData{})
+
+		similar, err := GetSimilarColorschemes(1, repository.DarkBackground)
+		if err != nil {
+			t.Fatalf("GetSimilarColorschemes: %v", err)
+		}
+		if len(similar) != 0 {
+			t.Fatalf("len(similar) = %d, want 0", len(similar))
+		}
+	})
+}
+
+func TestGetColorschemeGroupsByRepository(t *testing.T) {
+	setupTestDB(t)
+	insertTestRepo(t, 1, "owner", "repo1")
+	if _, err := db.Exec(`INSERT INTO colorschemes (id, repository_id, name, is_hidden) VALUES (1, 1, 'visible', 0), (2, 1, 'hidden', 1)`); err != nil {
+		t.Fatalf("insert colorschemes: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO colorscheme_groups (colorscheme_id, background, language, name, hex_code, link) VALUES
+		(1, 'dark', 'vim', 'NormalBg', '#000000', ''),
+		(1, 'dark', 'vim', 'StringFg', '', 'Constant'),
+		(1, 'dark', 'vim', 'ConstantFg', '', 'Number'),
+		(1, 'dark', 'vim', 'NumberFg', '#ff0000', ''),
+		(1, 'dark', 'vim', 'ConstantBg', '#00ff00', ''),
+		(1, 'dark', 'vim', 'TodoFg', '#0000ff', ''),
+		(1, 'dark', 'lua', 'NormalFg', '#ffffff', ''),
+		(2, 'dark', 'vim', 'NormalBg', '#111111', '')`); err != nil {
+		t.Fatalf("insert groups: %v", err)
+	}
+
+	schemes, err := GetColorschemeGroupsByRepository([]string{"NormalBg", "NormalFg", "StringFg"})
+	if err != nil {
+		t.Fatalf("GetColorschemeGroupsByRepository: %v", err)
+	}
+	if len(schemes[1]) != 1 || schemes[1][0].ID != 1 {
+		t.Fatalf("schemes = %+v, want only the visible colorscheme", schemes)
+	}
+
+	var names []string
+	for _, group := range schemes[1][0].Data.Dark {
+		names = append(names, group.Name)
+	}
+	expected := []string{"ConstantFg", "NormalBg", "NumberFg", "StringFg"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Fatalf("groups = %v, want %v", names, expected)
+	}
+	if hexCode, ok := repository.GroupHexCode(schemes[1][0].Data.Dark, "StringFg"); !ok || hexCode != "#ff0000" {
+		t.Fatalf("StringFg = %q, %v, want #ff0000 through its links", hexCode, ok)
+	}
+}
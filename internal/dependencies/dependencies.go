@@ -0,0 +1,128 @@
+package dependencies
+
+import (
+	"crypto/sha256"
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+)
+
+// dependencies.json is the curated table of the plugins colorschemes require,
+// keyed by the top level Lua modules they provide
+//
+//go:embed dependencies.json
+var content []byte
+
+// dependencies.lock.json pins every dependency of the table to a commit, see
+// bin/pin-plugins
+//
+//go:embed dependencies.lock.json
+var lockContent []byte
+
+// Full commit SHAs the dependencies of the lockfile are pinned to
+var commitPattern = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// ErrUnpinned is returned when a dependency of the table isn't pinned in the
+// lockfile
+var ErrUnpinned = errors.New("not pinned to a commit SHA, run bin/pin-plugins")
+
+// Dependency represents a plugin colorschemes load with require()
+type Dependency struct {
+	Name       string   `json:"name"`
+	Repository string   `json:"repository"`
+	Modules    []string `json:"modules"`
+}
+
+// Table represents the curated dependency table
+type Table struct {
+	Dependencies []Dependency `json:"dependencies"`
+}
+
+// Resolved represents a dependency installed for a repository, at the commit
+// it was installed at
+type Resolved struct {
+	Name       string `json:"name"`
+	Repository string `json:"repository"`
+	Commit     string `json:"commit"`
+}
+
+// Commits returns the commits of the resolved dependencies keyed by name
+func Commits(resolved []Resolved) map[string]string {
+	commits := make(map[string]string, len(resolved))
+	for _, dependency := range resolved {
+		commits[dependency.Name] = dependency.Commit
+	}
+	return commits
+}
+
+// Load parses the curated dependency table
+func Load() (Table, error) {
+	var table Table
+	if err := json.Unmarshal(content, &table); err != nil {
+		return Table{}, fmt.Errorf("parse dependency table: %w", err)
+	}
+	return table, nil
+}
+
+// Commits returns the commits the dependencies of the table are pinned to in
+// dependencies.lock.json keyed by name, failing when one isn't pinned
+func (table Table) Commits() (map[string]string, error) {
+	return table.lockedCommits(lockContent)
+}
+
+func (table Table) lockedCommits(lock []byte) (map[string]string, error) {
+	var locked []Resolved
+	if err := json.Unmarshal(lock, &locked); err != nil {
+		return nil, fmt.Errorf("parse dependency lockfile: %w", err)
+	}
+
+	commits := make(map[string]string, len(table.Dependencies))
+	for _, dependency := range table.Dependencies {
+		index := slices.IndexFunc(locked, func(entry Resolved) bool { return entry.Name == dependency.Name })
+		if index == -1 || locked[index].Repository != dependency.Repository || !commitPattern.MatchString(locked[index].Commit) {
+			return nil, fmt.Errorf("dependencies.lock.json entry %s: %w", dependency.Name, ErrUnpinned)
+		}
+		commits[dependency.Name] = locked[index].Commit
+	}
+	return commits, nil
+}
+
+// Checksum returns the SHA-256 of the curated dependency table
+func Checksum() string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// URL returns the URL the dependency is cloned from
+func (dependency Dependency) URL() string {
+	return fmt.Sprintf("https://github.com/%s", dependency.Repository)
+}
+
+// Resolve maps the required top level modules to the dependencies providing
+// them, in table order, and returns the modules no dependency provides
+func (table Table) Resolve(modules []string) ([]Dependency, []string) {
+	var resolved []Dependency
+	provided := map[string]bool{}
+	for _, dependency := range table.Dependencies {
+		for _, module := range dependency.Modules {
+			if slices.Contains(modules, module) {
+				provided[module] = true
+				if !slices.ContainsFunc(resolved, func(other Dependency) bool { return other.Name == dependency.Name }) {
+					resolved = append(resolved, dependency)
+				}
+			}
+		}
+	}
+
+	var unresolved []string
+	for _, module := range modules {
+		if !provided[module] {
+			unresolved = append(unresolved, module)
+		}
+	}
+	return resolved, unresolved
+}
@@ -0,0 +1,112 @@
+package dependencies
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	table, err := Load()
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+
+	names := map[string]bool{}
+	modules := map[string]string{}
+	for _, dependency := range table.Dependencies {
+		if names[dependency.Name] {
+			t.Errorf("dependency %q is listed twice", dependency.Name)
+		}
+		names[dependency.Name] = true
+		if dependency.Repository == "" || len(dependency.Modules) == 0 {
+			t.Errorf("dependency %q needs a repository and modules", dependency.Name)
+		}
+		for _, module := range dependency.Modules {
+			if other, ok := modules[module]; ok {
+				t.Errorf("module %q is provided by %q and %q", module, other, dependency.Name)
+			}
+			modules[module] = dependency.Name
+		}
+	}
+
+	for module, name := range map[string]string{"colorbuddy": "colorbuddy.nvim", "plenary": "plenary.nvim", "mini": "mini.nvim", "lush": "lush.nvim"} {
+		if modules[module] != name {
+			t.Errorf("module %q is provided by %q, want %q", module, modules[module], name)
+		}
+	}
+}
+
+func TestTableResolve(t *testing.T) {
+	table := Table{Dependencies: []Dependency{
+		{Name: "a.nvim", Repository: "owner/a.nvim", Modules: []string{"a", "a_extra"}},
+		{Name: "b.nvim", Repository: "owner/b.nvim", Modules: []string{"b"}},
+	}}
+
+	resolved, unresolved := table.Resolve([]string{"b", "a_extra", "unknown", "a"})
+
+	var names []string
+	for _, dependency := range resolved {
+		names = append(names, dependency.Name)
+	}
+	if !slices.Equal(names, []string{"a.nvim", "b.nvim"}) {
+		t.Errorf("resolved = %v, want [a.nvim b.nvim]", names)
+	}
+	if !slices.Equal(unresolved, []string{"unknown"}) {
+		t.Errorf("unresolved = %v, want [unknown]", unresolved)
+	}
+	if url := resolved[0].URL(); url != "https://github.com/owner/a.nvim" {
+		t.Errorf("URL() = %q", url)
+	}
+}
+
+func TestLockfile(t *testing.T) {
+	table, err := Load()
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+
+	var locked []Resolved
+	if err := json.Unmarshal(lockContent, &locked); err != nil {
+		t.Fatalf("parse lockfile: %v", err)
+	}
+	var lockedNames, names []string
+	for _, entry := range locked {
+		lockedNames = append(lockedNames, entry.Name+" "+entry.Repository)
+	}
+	for _, dependency := range table.Dependencies {
+		names = append(names, dependency.Name+" "+dependency.Repository)
+	}
+	if !slices.Equal(lockedNames, names) {
+		t.Errorf("lockfile lists %v, want the table dependencies %v", lockedNames, names)
+	}
+}
+
+func TestTableLockedCommits(t *testing.T) {
+	table := Table{Dependencies: []Dependency{{Name: "a.nvim", Repository: "owner/a.nvim", Modules: []string{"a"}}}}
+	commit := strings.Repeat("a", 40)
+
+	t.Run("returns the pinned commits", func(t *testing.T) {
+		commits, err := table.lockedCommits([]byte(`[{"name": "a.nvim", "repository": "owner/a.nvim", "commit": "` + commit + `"}]`))
+		if err != nil {
+			t.Fatalf("lockedCommits returned error: %v", err)
+		}
+		if commits["a.nvim"] != commit {
+			t.Errorf("commits = %v, want a.nvim at %s", commits, commit)
+		}
+	})
+
+	for name, lock := range map[string]string{
+		"rejects entries without a commit": `[{"name": "a.nvim", "repository": "owner/a.nvim", "commit": ""}]`,
+		"rejects missing entries":          `[]`,
+		"rejects another repository":       `[{"name": "a.nvim", "repository": "fork/a.nvim", "commit": "` + commit + `"}]`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := table.lockedCommits([]byte(lock)); !errors.Is(err, ErrUnpinned) {
+				t.Errorf("lockedCommits error = %v, want an unpinned error", err)
+			}
+		})
+	}
+}
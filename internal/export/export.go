@@ -0,0 +1,156 @@
+package export
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/vimcolorschemes/worker/internal/color"
+	"github.com/vimcolorschemes/worker/internal/repository"
+)
+
+// Palette represents the terminal colors derived from a colorscheme background
+type Palette struct {
+	Name                string
+	Background          repository.BackgroundValue
+	BackgroundColor     color.RGB
+	ForegroundColor     color.RGB
+	CursorColor         color.RGB
+	CursorTextColor     color.RGB
+	SelectionBackground color.RGB
+	SelectionForeground color.RGB
+	ANSI                [16]color.RGB
+}
+
+// Artifact represents a rendered file, with a path relative to the export directory
+type Artifact struct {
+	Path    string
+	Content string
+}
+
+// Format represents a terminal or editor theme format
+type Format struct {
+	Name      string
+	Extension string
+	Render    func(Palette) (string, error)
+}
+
+// TerminalFormats lists the supported terminal theme formats
+var TerminalFormats = []Format{
+	{Name: "alacritty", Extension: "toml", Render: renderAlacritty},
+	{Name: "kitty", Extension: "conf", Render: renderKitty},
+	{Name: "wezterm", Extension: "lua", Render: renderWezTerm},
+	{Name: "windows-terminal", Extension: "json", Render: renderWindowsTerminal},
+	{Name: "iterm2", Extension: "itermcolors", Render: renderITerm2},
+	{Name: "xresources", Extension: "Xresources", Render: renderXresources},
+}
+
+// ansiGroupNames maps the first 8 ANSI colors to the groups they are taken
+// from, in order of preference. Bright colors reuse the same groups, except
+// bright black which uses comments and bright white which uses Normal.
+var ansiGroupNames = [8][]string{
+	{"NormalBg"},
+	{"DiagnosticErrorFg", "ErrorMsgFg", "ErrorFg"},
+	{"StringFg", "DiffAddFg"},
+	{"DiagnosticWarnFg", "TypeFg", "WarningMsgFg"},
+	{"FunctionFg", "DiagnosticInfoFg", "IdentifierFg"},
+	{"KeywordFg", "StatementFg", "ConstantFg"},
+	{"SpecialFg", "DiagnosticHintFg", "PreProcFg"},
+	{"NormalFg"},
+}
+
+// NewPalette derives terminal colors from the groups of a colorscheme
+// background, or returns false if Normal colors are missing
+func NewPalette(name string, background repository.BackgroundValue, groups []repository.ColorschemeGroup) (Palette, bool) {
+	normalBg, ok := groupColor(groups, "NormalBg")
+	if !ok {
+		return Palette{}, false
+	}
+	normalFg, ok := groupColor(groups, "NormalFg")
+	if !ok {
+		return Palette{}, false
+	}
+
+	palette := Palette{
+		Name:                name,
+		Background:          background,
+		BackgroundColor:     normalBg,
+		ForegroundColor:     normalFg,
+		CursorColor:         firstGroupColor(groups, normalFg, "CursorBg"),
+		CursorTextColor:     firstGroupColor(groups, normalBg, "CursorFg"),
+		SelectionBackground: firstGroupColor(groups, normalFg, "VisualBg"),
+		SelectionForeground: firstGroupColor(groups, normalFg, "VisualFg"),
+	}
+
+	for index, names := range ansiGroupNames {
+		value := firstGroupColor(groups, normalFg, names...)
+		palette.ANSI[index] = value
+		palette.ANSI[index+8] = value
+	}
+	palette.ANSI[8] = firstGroupColor(groups, normalFg, "CommentFg", "LineNrFg")
+	palette.ANSI[15] = normalFg
+
+	return palette, true
+}
+
+// TerminalArtifacts renders every terminal format for every background of the colorscheme
+func TerminalArtifacts(colorscheme repository.Colorscheme) ([]Artifact, error) {
+	var artifacts []Artifact
+	for _, background := range []repository.BackgroundValue{repository.LightBackground, repository.DarkBackground} {
+		palette, ok := NewPalette(colorscheme.Name, background, colorscheme.Data.Groups(background))
+		if !ok {
+			continue
+		}
+
+		for _, format := range TerminalFormats {
+			content, err := format.Render(palette)
+			if err != nil {
+				return nil, fmt.Errorf("render %s for %s (%s): %w", format.Name, colorscheme.Name, background, err)
+			}
+			artifacts = append(artifacts, Artifact{
+				Path:    path.Join(format.Name, fmt.Sprintf("%s-%s.%s", FileName(colorscheme.Name), background, format.Extension)),
+				Content: content,
+			})
+		}
+	}
+	return artifacts, nil
+}
+
+// FileName returns a colorscheme name safe to use as a file name
+func FileName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', '*', '?', '"', '<', '>', '|':
+			return '_'
+		}
+		return r
+	}, name)
+}
+
+// Title returns the display name of the palette, e.g. "gruvbox (dark)"
+func (palette Palette) Title() string {
+	return fmt.Sprintf("%s (%s)", palette.Name, palette.Background)
+}
+
+func groupColor(groups []repository.ColorschemeGroup, name string) (color.RGB, bool) {
+	hexCode, ok := repository.GroupHexCode(groups, name)
+	if !ok {
+		return color.RGB{}, false
+	}
+
+	value, err := color.ParseHex(hexCode)
+	if err != nil {
+		return color.RGB{}, false
+	}
+
+	return value, true
+}
+
+func firstGroupColor(groups []repository.ColorschemeGroup, fallback color.RGB, names ...string) color.RGB {
+	for _, name := range names {
+		if value, ok := groupColor(groups, name); ok {
+			return value
+		}
+	}
+	return fallback
+}
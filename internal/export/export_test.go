@@ -0,0 +1,130 @@
+package export
+
+import (
+	"encoding/xml"
+	"flag"
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+
+	"github.com/vimcolorschemes/worker/internal/repository"
+)
+
+var updateGolden = flag.Bool("update", false, "update golden files")
+
+var testColorscheme = repository.Colorscheme{
+	Name: "testscheme",
+	Data: repository.ColorschemeData{
+		Dark: []repository.ColorschemeGroup{
+			{Name: "NormalBg", HexCode: "#282828"},
+			{Name: "NormalFg", HexCode: "#ebdbb2"},
+			{Name: "CursorBg", HexCode: "#a89984"},
+			{Name: "VisualBg", HexCode: "#504945"},
+			{Name: "CommentFg", HexCode: "#928374", Italic: true},
+			{Name: "DiagnosticErrorFg", HexCode: "#fb4934"},
+			{Name: "StringFg", HexCode: "#b8bb26"},
+			{Name: "DiagnosticWarnFg", HexCode: "#fabd2f"},
+			{Name: "FunctionFg", HexCode: "#83a598", Bold: true},
+			{Name: "KeywordFg", HexCode: "#d3869b"},
+			{Name: "SpecialFg", HexCode: "#8ec07c"},
+		},
+	},
+}
+
+func assertGolden(t *testing.T, name string, actual string) {
+	t.Helper()
+
+	goldenPath := filepath.Join("testdata", name+".golden")
+	if *updateGolden {
+		if err := os.WriteFile(goldenPath, []byte(actual), 0644); err != nil {
+			t.Fatalf("write golden file: %v", err)
+		}
+	}
+
+	expected, err := os.ReadFile(goldenPath)
+	if err != nil {
+		t.Fatalf("read golden file: %v", err)
+	}
+	if string(expected) != actual {
+		t.Fatalf("%s does not match golden file %s, got:\n%s", name, goldenPath, actual)
+	}
+}
+
+func TestNewPalette(t *testing.T) {
+	t.Run("requires Normal colors", func(t *testing.T) {
+		_, ok := NewPalette("testscheme", repository.DarkBackground, []repository.ColorschemeGroup{{Name: "NormalBg", HexCode: "#000000"}})
+		if ok {
+			t.Fatal("NewPalette returned ok without NormalFg")
+		}
+	})
+
+	t.Run("falls back to Normal foreground", func(t *testing.T) {
+		palette, ok := NewPalette("testscheme", repository.DarkBackground, []repository.ColorschemeGroup{
+			{Name: "NormalBg", HexCode: "#000000"},
+			{Name: "NormalFg", HexCode: "#ffffff"},
+		})
+		if !ok {
+			t.Fatal("NewPalette returned not ok")
+		}
+		if palette.ANSI[0].Hex() != "#000000" {
+			t.Fatalf("ANSI[0] = %q, want %q", palette.ANSI[0].Hex(), "#000000")
+		}
+		for index := 1; index < 16; index++ {
+			if palette.ANSI[index].Hex() != "#ffffff" {
+				t.Fatalf("ANSI[%d] = %q, want %q", index, palette.ANSI[index].Hex(), "#ffffff")
+			}
+		}
+	})
+}
+
+func TestTerminalArtifacts(t *testing.T) {
+	artifacts, err := TerminalArtifacts(testColorscheme)
+	if err != nil {
+		t.Fatalf("TerminalArtifacts: %v", err)
+	}
+	if len(artifacts) != len(TerminalFormats) {
+		t.Fatalf("len(artifacts) = %d, want %d", len(artifacts), len(TerminalFormats))
+	}
+
+	for index, format := range TerminalFormats {
+		artifact := artifacts[index]
+		t.Run(format.Name, func(t *testing.T) {
+			wantPath := path.Join(format.Name, "testscheme-dark."+format.Extension)
+			if artifact.Path != wantPath {
+				t.Fatalf("Path = %q, want %q", artifact.Path, wantPath)
+			}
+			assertGolden(t, format.Name, artifact.Content)
+		})
+	}
+}
+
+func TestITerm2Comment(t *testing.T) {
+	palette, ok := NewPalette("my--theme", repository.DarkBackground, testColorscheme.Data.Dark)
+	if !ok {
+		t.Fatal("NewPalette returned not ok")
+	}
+	content, err := renderITerm2(palette)
+	if err != nil {
+		t.Fatalf("renderITerm2: %v", err)
+	}
+	if err := xml.Unmarshal([]byte(content), new(struct{})); err != nil {
+		t.Fatalf("iTerm2 theme is not valid XML: %v", err)
+	}
+
+	for value, expected := range map[string]string{
+		"my--theme": "my- -theme",
+		"a---b":     "a- - -b",
+		"theme-":    "theme- ",
+	} {
+		if comment := xmlComment(value); comment != expected {
+			t.Errorf("xmlComment(%q) = %q, want %q", value, comment, expected)
+		}
+	}
+}
+
+func TestFileName(t *testing.T) {
+	if name := FileName("base16/ocean"); name != "base16_ocean" {
+		t.Fatalf("FileName = %q, want %q", name, "base16_ocean")
+	}
+}
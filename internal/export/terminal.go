@@ -0,0 +1,218 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/vimcolorschemes/worker/internal/color"
+)
+
+const generatedHeader = "Generated by vimcolorschemes from the %s colorscheme"
+
+var ansiNames = [8]string{"black", "red", "green", "yellow", "blue", "magenta", "cyan", "white"}
+
+func renderAlacritty(palette Palette) (string, error) {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# "+generatedHeader+"\n\n", palette.Title())
+
+	b.WriteString("[colors.primary]\n")
+	fmt.Fprintf(&b, "background = %q\n", palette.BackgroundColor.Hex())
+	fmt.Fprintf(&b, "foreground = %q\n", palette.ForegroundColor.Hex())
+
+	b.WriteString("\n[colors.cursor]\n")
+	fmt.Fprintf(&b, "cursor = %q\n", palette.CursorColor.Hex())
+	fmt.Fprintf(&b, "text = %q\n", palette.CursorTextColor.Hex())
+
+	b.WriteString("\n[colors.selection]\n")
+	fmt.Fprintf(&b, "background = %q\n", palette.SelectionBackground.Hex())
+	fmt.Fprintf(&b, "text = %q\n", palette.SelectionForeground.Hex())
+
+	for _, section := range []struct {
+		name   string
+		offset int
+	}{{"normal", 0}, {"bright", 8}} {
+		fmt.Fprintf(&b, "\n[colors.%s]\n", section.name)
+		for index, name := range ansiNames {
+			fmt.Fprintf(&b, "%s = %q\n", name, palette.ANSI[section.offset+index].Hex())
+		}
+	}
+
+	return b.String(), nil
+}
+
+func renderKitty(palette Palette) (string, error) {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# "+generatedHeader+"\n\n", palette.Title())
+
+	rows := [][2]string{
+		{"background", palette.BackgroundColor.Hex()},
+		{"foreground", palette.ForegroundColor.Hex()},
+		{"cursor", palette.CursorColor.Hex()},
+		{"cursor_text_color", palette.CursorTextColor.Hex()},
+		{"selection_background", palette.SelectionBackground.Hex()},
+		{"selection_foreground", palette.SelectionForeground.Hex()},
+	}
+	for index, value := range palette.ANSI {
+		rows = append(rows, [2]string{fmt.Sprintf("color%d", index), value.Hex()})
+	}
+
+	for _, row := range rows {
+		fmt.Fprintf(&b, "%-20s %s\n", row[0], row[1])
+	}
+
+	return b.String(), nil
+}
+
+func renderWezTerm(palette Palette) (string, error) {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "-- "+generatedHeader+"\n", palette.Title())
+	b.WriteString("return {\n")
+	fmt.Fprintf(&b, "  background = %q,\n", palette.BackgroundColor.Hex())
+	fmt.Fprintf(&b, "  foreground = %q,\n", palette.ForegroundColor.Hex())
+	fmt.Fprintf(&b, "  cursor_bg = %q,\n", palette.CursorColor.Hex())
+	fmt.Fprintf(&b, "  cursor_fg = %q,\n", palette.CursorTextColor.Hex())
+	fmt.Fprintf(&b, "  cursor_border = %q,\n", palette.CursorColor.Hex())
+	fmt.Fprintf(&b, "  selection_bg = %q,\n", palette.SelectionBackground.Hex())
+	fmt.Fprintf(&b, "  selection_fg = %q,\n", palette.SelectionForeground.Hex())
+	fmt.Fprintf(&b, "  ansi = { %s },\n", quoteHexCodes(palette.ANSI[:8]))
+	fmt.Fprintf(&b, "  brights = { %s },\n", quoteHexCodes(palette.ANSI[8:]))
+	b.WriteString("}\n")
+
+	return b.String(), nil
+}
+
+func renderWindowsTerminal(palette Palette) (string, error) {
+	scheme := struct {
+		Name                string `json:"name"`
+		Background          string `json:"background"`
+		Foreground          string `json:"foreground"`
+		CursorColor         string `json:"cursorColor"`
+		SelectionBackground string `json:"selectionBackground"`
+		Black               string `json:"black"`
+		Red                 string `json:"red"`
+		Green               string `json:"green"`
+		Yellow              string `json:"yellow"`
+		Blue                string `json:"blue"`
+		Purple              string `json:"purple"`
+		Cyan                string `json:"cyan"`
+		White               string `json:"white"`
+		BrightBlack         string `json:"brightBlack"`
+		BrightRed           string `json:"brightRed"`
+		BrightGreen         string `json:"brightGreen"`
+		BrightYellow        string `json:"brightYellow"`
+		BrightBlue          string `json:"brightBlue"`
+		BrightPurple        string `json:"brightPurple"`
+		BrightCyan          string `json:"brightCyan"`
+		BrightWhite         string `json:"brightWhite"`
+	}{
+		Name:                palette.Title(),
+		Background:          palette.BackgroundColor.Hex(),
+		Foreground:          palette.ForegroundColor.Hex(),
+		CursorColor:         palette.CursorColor.Hex(),
+		SelectionBackground: palette.SelectionBackground.Hex(),
+		Black:               palette.ANSI[0].Hex(),
+		Red:                 palette.ANSI[1].Hex(),
+		Green:               palette.ANSI[2].Hex(),
+		Yellow:              palette.ANSI[3].Hex(),
+		Blue:                palette.ANSI[4].Hex(),
+		Purple:              palette.ANSI[5].Hex(),
+		Cyan:                palette.ANSI[6].Hex(),
+		White:               palette.ANSI[7].Hex(),
+		BrightBlack:         palette.ANSI[8].Hex(),
+		BrightRed:           palette.ANSI[9].Hex(),
+		BrightGreen:         palette.ANSI[10].Hex(),
+		BrightYellow:        palette.ANSI[11].Hex(),
+		BrightBlue:          palette.ANSI[12].Hex(),
+		BrightPurple:        palette.ANSI[13].Hex(),
+		BrightCyan:          palette.ANSI[14].Hex(),
+		BrightWhite:         palette.ANSI[15].Hex(),
+	}
+
+	content, err := json.MarshalIndent(scheme, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	return string(content) + "\n", nil
+}
+
+// xmlComment returns value without the "--" XML comments can't contain, and
+// without a trailing "-" that would run into the closing "-->"
+func xmlComment(value string) string {
+	for strings.Contains(value, "--") {
+		value = strings.ReplaceAll(value, "--", "- -")
+	}
+	if strings.HasSuffix(value, "-") {
+		value += " "
+	}
+	return value
+}
+
+func renderITerm2(palette Palette) (string, error) {
+	var b strings.Builder
+
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	b.WriteString(`<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">` + "\n")
+	fmt.Fprintf(&b, "<!-- %s -->\n", xmlComment(fmt.Sprintf(generatedHeader, palette.Title())))
+	b.WriteString(`<plist version="1.0">` + "\n")
+	b.WriteString("<dict>\n")
+
+	type itermColor struct {
+		key   string
+		value color.RGB
+	}
+
+	var entries []itermColor
+	for index, value := range palette.ANSI {
+		entries = append(entries, itermColor{fmt.Sprintf("Ansi %d Color", index), value})
+	}
+	entries = append(entries,
+		itermColor{"Background Color", palette.BackgroundColor},
+		itermColor{"Cursor Color", palette.CursorColor},
+		itermColor{"Cursor Text Color", palette.CursorTextColor},
+		itermColor{"Foreground Color", palette.ForegroundColor},
+		itermColor{"Selected Text Color", palette.SelectionForeground},
+		itermColor{"Selection Color", palette.SelectionBackground},
+	)
+
+	for _, entry := range entries {
+		fmt.Fprintf(&b, "\t<key>%s</key>\n", entry.key)
+		b.WriteString("\t<dict>\n")
+		b.WriteString("\t\t<key>Color Space</key>\n\t\t<string>sRGB</string>\n")
+		fmt.Fprintf(&b, "\t\t<key>Blue Component</key>\n\t\t<real>%.6f</real>\n", entry.value.B)
+		fmt.Fprintf(&b, "\t\t<key>Green Component</key>\n\t\t<real>%.6f</real>\n", entry.value.G)
+		fmt.Fprintf(&b, "\t\t<key>Red Component</key>\n\t\t<real>%.6f</real>\n", entry.value.R)
+		b.WriteString("\t</dict>\n")
+	}
+
+	b.WriteString("</dict>\n")
+	b.WriteString("</plist>\n")
+
+	return b.String(), nil
+}
+
+func renderXresources(palette Palette) (string, error) {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "! "+generatedHeader+"\n\n", palette.Title())
+	fmt.Fprintf(&b, "*.background: %s\n", palette.BackgroundColor.Hex())
+	fmt.Fprintf(&b, "*.foreground: %s\n", palette.ForegroundColor.Hex())
+	fmt.Fprintf(&b, "*.cursorColor: %s\n", palette.CursorColor.Hex())
+	for index, value := range palette.ANSI {
+		fmt.Fprintf(&b, "*.color%d: %s\n", index, value.Hex())
+	}
+
+	return b.String(), nil
+}
+
+func quoteHexCodes(colors []color.RGB) string {
+	quoted := make([]string, len(colors))
+	for index, value := range colors {
+		quoted[index] = fmt.Sprintf("%q", value.Hex())
+	}
+	return strings.Join(quoted, ", ")
+}
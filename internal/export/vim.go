@@ -0,0 +1,353 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/vimcolorschemes/worker/internal/color"
+	"github.com/vimcolorschemes/worker/internal/repository"
+)
+
+// highlightNamePattern matches the highlight group names accepted by Neovim
+var highlightNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.@]+$`)
+
+// Vim rejects highlight group names with "@" or "." (e.g. Tree-sitter
+// captures), which the Vim script export only sets in Neovim
+var neovimOnlyNamePattern = regexp.MustCompile(`[.@]`)
+
+// Attributes older Vim versions reject, which the Vim script export replaces
+// with underline outside of Neovim
+var neovimOnlyAttributes = []string{"underdouble", "underdotted", "underdashed"}
+
+// Highlight represents a highlight group rebuilt from its extracted Fg, Bg and Sp groups
+type Highlight struct {
+	Name       string
+	Foreground string
+	Background string
+	Special    string
+	Attributes []string
+}
+
+// VimArtifacts renders a dependency free colors/<name>.vim and colors/<name>.lua for the colorscheme
+func VimArtifacts(colorscheme repository.Colorscheme) []Artifact {
+	highlights := map[repository.BackgroundValue][]Highlight{}
+	for _, background := range []repository.BackgroundValue{repository.LightBackground, repository.DarkBackground} {
+		if groups := colorscheme.Data.Groups(background); len(groups) > 0 {
+			highlights[background] = BuildHighlights(groups)
+		}
+	}
+	if len(highlights) == 0 {
+		return nil
+	}
+
+	name := FileName(colorscheme.Name)
+	return []Artifact{
+		{Path: "colors/" + name + ".vim", Content: renderVimColorscheme(colorscheme.Name, highlights)},
+		{Path: "colors/" + name + ".lua", Content: renderLuaColorscheme(colorscheme.Name, highlights)},
+	}
+}
+
+// Artifacts renders every supported format for the colorscheme
+func Artifacts(colorscheme repository.Colorscheme) ([]Artifact, error) {
+	terminalArtifacts, err := TerminalArtifacts(colorscheme)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(terminalArtifacts, VimArtifacts(colorscheme)...), nil
+}
+
+// Archive bundles the artifacts in a zip archive
+func Archive(artifacts []Artifact) ([]byte, error) {
+	var buffer bytes.Buffer
+	writer := zip.NewWriter(&buffer)
+
+	for _, artifact := range artifacts {
+		entry, err := writer.Create(artifact.Path)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := entry.Write([]byte(artifact.Content)); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// BuildHighlights merges the extracted "<Name>Fg", "<Name>Bg" and "<Name>Sp"
+// groups into highlight definitions, in order of first appearance. Linked
+// groups get the color of their target. Group names without a known suffix
+// are treated as foreground colors, and groups with an invalid name or hex
+// code are skipped.
+func BuildHighlights(groups []repository.ColorschemeGroup) []Highlight {
+	var highlights []Highlight
+	indexes := map[string]int{}
+
+	for _, group := range repository.ResolveLinks(groups) {
+		name, target := repository.SplitGroupName(group.Name)
+		if !highlightNamePattern.MatchString(name) {
+			continue
+		}
+		value, err := color.ParseHex(group.HexCode)
+		if err != nil {
+			continue
+		}
+		hexCode := value.Hex()
+
+		index, exists := indexes[name]
+		if !exists {
+			highlights = append(highlights, Highlight{Name: name})
+			index = len(highlights) - 1
+			indexes[name] = index
+		}
+
+		highlight := &highlights[index]
+		switch target {
+		case "Bg":
+			highlight.Background = hexCode
+		case "Sp":
+			highlight.Special = hexCode
+		default:
+			highlight.Foreground = hexCode
+		}
+
+		for _, attribute := range groupAttributes(group) {
+			if !slices.Contains(highlight.Attributes, attribute) {
+				highlight.Attributes = append(highlight.Attributes, attribute)
+			}
+		}
+	}
+
+	return highlights
+}
+
+func renderVimColorscheme(name string, highlights map[repository.BackgroundValue][]Highlight) string {
+	var b strings.Builder
+
+	_, hasLight := highlights[repository.LightBackground]
+	_, hasDark := highlights[repository.DarkBackground]
+
+	fmt.Fprintf(&b, "\" "+generatedHeader+"\n\n", name)
+	if hasLight != hasDark {
+		// Set the only supported background before clearing, since changing
+		// it afterwards reloads the colorscheme
+		fmt.Fprintf(&b, "set background=%s\n", singleBackground(hasLight))
+	}
+	b.WriteString("hi clear\n")
+	b.WriteString("if exists('syntax_on')\n  syntax reset\nendif\n")
+	fmt.Fprintf(&b, "let g:colors_name = %s\n\n", vimString(name))
+
+	writeBackground := func(background repository.BackgroundValue, indent string) {
+		var neovimOnly []Highlight
+		for _, highlight := range highlights[background] {
+			if neovimOnlyNamePattern.MatchString(highlight.Name) || vimHighlight(highlight) != nil {
+				neovimOnly = append(neovimOnly, highlight)
+				continue
+			}
+			b.WriteString(indent)
+			b.WriteString(vimHighlightCommand(highlight))
+			b.WriteString("\n")
+		}
+		if len(neovimOnly) == 0 {
+			return
+		}
+
+		fmt.Fprintf(&b, "%sif has('nvim')\n", indent)
+		for _, highlight := range neovimOnly {
+			fmt.Fprintf(&b, "%s  %s\n", indent, vimHighlightCommand(highlight))
+		}
+		var vimHighlights []Highlight
+		for _, highlight := range neovimOnly {
+			if fallback := vimHighlight(highlight); fallback != nil && !neovimOnlyNamePattern.MatchString(highlight.Name) {
+				vimHighlights = append(vimHighlights, *fallback)
+			}
+		}
+		if len(vimHighlights) > 0 {
+			fmt.Fprintf(&b, "%selse\n", indent)
+			for _, highlight := range vimHighlights {
+				fmt.Fprintf(&b, "%s  %s\n", indent, vimHighlightCommand(highlight))
+			}
+		}
+		fmt.Fprintf(&b, "%sendif\n", indent)
+	}
+
+	if hasLight && hasDark {
+		b.WriteString("if &background ==# 'light'\n")
+		writeBackground(repository.LightBackground, "  ")
+		b.WriteString("else\n")
+		writeBackground(repository.DarkBackground, "  ")
+		b.WriteString("endif\n")
+	} else {
+		writeBackground(singleBackground(hasLight), "")
+	}
+
+	return b.String()
+}
+
+// Returns the highlight with its Neovim only attributes replaced by
+// underline, or nil when it has none
+func vimHighlight(highlight Highlight) *Highlight {
+	if !slices.ContainsFunc(highlight.Attributes, func(attribute string) bool {
+		return slices.Contains(neovimOnlyAttributes, attribute)
+	}) {
+		return nil
+	}
+
+	fallback := highlight
+	fallback.Attributes = nil
+	for _, attribute := range highlight.Attributes {
+		if slices.Contains(neovimOnlyAttributes, attribute) {
+			attribute = "underline"
+		}
+		if !slices.Contains(fallback.Attributes, attribute) {
+			fallback.Attributes = append(fallback.Attributes, attribute)
+		}
+	}
+	return &fallback
+}
+
+func vimHighlightCommand(highlight Highlight) string {
+	parts := []string{"hi", highlight.Name}
+	if highlight.Foreground != "" {
+		parts = append(parts, "guifg="+highlight.Foreground)
+	}
+	if highlight.Background != "" {
+		parts = append(parts, "guibg="+highlight.Background)
+	}
+	if highlight.Special != "" {
+		parts = append(parts, "guisp="+highlight.Special)
+	}
+
+	attributes := "NONE"
+	if len(highlight.Attributes) > 0 {
+		attributes = strings.Join(highlight.Attributes, ",")
+	}
+	parts = append(parts, "gui="+attributes, "cterm="+attributes)
+
+	return strings.Join(parts, " ")
+}
+
+func renderLuaColorscheme(name string, highlights map[repository.BackgroundValue][]Highlight) string {
+	var b strings.Builder
+
+	_, hasLight := highlights[repository.LightBackground]
+	_, hasDark := highlights[repository.DarkBackground]
+
+	fmt.Fprintf(&b, "-- "+generatedHeader+"\n\n", name)
+	if hasLight != hasDark {
+		fmt.Fprintf(&b, "vim.o.background = %s\n", luaString(string(singleBackground(hasLight))))
+	}
+	b.WriteString("vim.cmd(\"highlight clear\")\n")
+	b.WriteString("if vim.fn.exists(\"syntax_on\") == 1 then\n  vim.cmd(\"syntax reset\")\nend\n")
+	fmt.Fprintf(&b, "vim.g.colors_name = %s\n\n", luaString(name))
+
+	b.WriteString("local highlights = {\n")
+	for _, background := range []repository.BackgroundValue{repository.LightBackground, repository.DarkBackground} {
+		groups, ok := highlights[background]
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(&b, "  %s = {\n", background)
+		for _, highlight := range groups {
+			fmt.Fprintf(&b, "    [%s] = { %s },\n", luaString(highlight.Name), luaHighlightFields(highlight))
+		}
+		b.WriteString("  },\n")
+	}
+	b.WriteString("}\n\n")
+
+	if hasLight && hasDark {
+		b.WriteString("local groups = highlights[vim.o.background] or highlights.dark\n")
+	} else {
+		fmt.Fprintf(&b, "local groups = highlights.%s\n", singleBackground(hasLight))
+	}
+
+	b.WriteString("for name, value in pairs(groups) do\n  vim.api.nvim_set_hl(0, name, value)\nend\n")
+
+	return b.String()
+}
+
+func singleBackground(hasLight bool) repository.BackgroundValue {
+	if hasLight {
+		return repository.LightBackground
+	}
+	return repository.DarkBackground
+}
+
+func luaHighlightFields(highlight Highlight) string {
+	var fields []string
+	if highlight.Foreground != "" {
+		fields = append(fields, "fg = "+luaString(highlight.Foreground))
+	}
+	if highlight.Background != "" {
+		fields = append(fields, "bg = "+luaString(highlight.Background))
+	}
+	if highlight.Special != "" {
+		fields = append(fields, "sp = "+luaString(highlight.Special))
+	}
+	for _, attribute := range highlight.Attributes {
+		fields = append(fields, attribute+" = true")
+	}
+	return strings.Join(fields, ", ")
+}
+
+func groupAttributes(group repository.ColorschemeGroup) []string {
+	var attributes []string
+	for _, attribute := range []struct {
+		name    string
+		enabled bool
+	}{
+		{"bold", group.Bold},
+		{"italic", group.Italic},
+		{"underline", group.Underline},
+		{"undercurl", group.Undercurl},
+		{"underdouble", group.Underdouble},
+		{"underdotted", group.Underdotted},
+		{"underdashed", group.Underdashed},
+		{"strikethrough", group.Strikethrough},
+		{"reverse", group.Reverse},
+	} {
+		if attribute.enabled {
+			attributes = append(attributes, attribute.name)
+		}
+	}
+	return attributes
+}
+
+// vimString returns a single quoted Vim script string literal
+func vimString(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// luaString returns a double quoted Lua string literal
+func luaString(value string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range value {
+		switch r {
+		case '"', '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(&b, `\%03d`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
@@ -0,0 +1,81 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/vimcolorschemes/worker/internal/repository"
+)
+
+func TestBuildHighlights(t *testing.T) {
+	highlights := BuildHighlights([]repository.ColorschemeGroup{
+		{Name: "NormalFg", HexCode: "#EBDBB2"},
+		{Name: "NormalBg", HexCode: "#282828"},
+		{Name: "SpellBadSp", HexCode: "#fb4934", Undercurl: true},
+		{Name: "Title", HexCode: "#b8bb26", Bold: true},
+		{Name: "Bad Name", HexCode: "#000000"},
+		{Name: "CommentFg", HexCode: "NONE"},
+	})
+
+	expected := []Highlight{
+		{Name: "Normal", Foreground: "#ebdbb2", Background: "#282828"},
+		{Name: "SpellBad", Special: "#fb4934", Attributes: []string{"undercurl"}},
+		{Name: "Title", Foreground: "#b8bb26", Attributes: []string{"bold"}},
+	}
+	if !reflect.DeepEqual(highlights, expected) {
+		t.Fatalf("BuildHighlights = %+v, want %+v", highlights, expected)
+	}
+}
+
+func TestVimArtifacts(t *testing.T) {
+	t.Run("renders a single background", func(t *testing.T) {
+		artifacts := VimArtifacts(testColorscheme)
+		if len(artifacts) != 2 {
+			t.Fatalf("len(artifacts) = %d, want 2", len(artifacts))
+		}
+		if artifacts[0].Path != "colors/testscheme.vim" || artifacts[1].Path != "colors/testscheme.lua" {
+			t.Fatalf("paths = %q, %q, want colors/testscheme.vim and colors/testscheme.lua", artifacts[0].Path, artifacts[1].Path)
+		}
+		assertGolden(t, "vim", artifacts[0].Content)
+		assertGolden(t, "lua", artifacts[1].Content)
+	})
+
+	t.Run("renders both backgrounds and escapes the name", func(t *testing.T) {
+		artifacts := VimArtifacts(repository.Colorscheme{
+			Name: `it's "quoted"`,
+			Data: repository.ColorschemeData{
+				Light: []repository.ColorschemeGroup{{Name: "NormalFg", HexCode: "#000000"}},
+				Dark: []repository.ColorschemeGroup{
+					{Name: "NormalFg", HexCode: "#ffffff", Italic: true},
+					{Name: "@variable.builtinFg", HexCode: "#ff0000"},
+					{Name: "SpellBadSp", HexCode: "#00ff00", Underdouble: true, Bold: true},
+				},
+			},
+		})
+		assertGolden(t, "vim_backgrounds", artifacts[0].Content)
+		assertGolden(t, "lua_backgrounds", artifacts[1].Content)
+	})
+
+	t.Run("skips colorschemes without data", func(t *testing.T) {
+		if artifacts := VimArtifacts(repository.Colorscheme{Name: "empty"}); artifacts != nil {
+			t.Fatalf("artifacts = %+v, want nil", artifacts)
+		}
+	})
+}
+
+func TestArchive(t *testing.T) {
+	content, err := Archive(VimArtifacts(testColorscheme))
+	if err != nil {
+		t.Fatalf("Archive: %v", err)
+	}
+
+	reader, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		t.Fatalf("zip.NewReader: %v", err)
+	}
+	if len(reader.File) != 2 || reader.File[0].Name != "colors/testscheme.vim" {
+		t.Fatalf("archive files = %d, want colors/testscheme.vim and colors/testscheme.lua", len(reader.File))
+	}
+}
@@ -3,6 +3,7 @@ package file
 import (
 	"log"
 	"os"
+	"path/filepath"
 )
 
 // GetLocalFileContent returns the file content of a local file at a path
@@ -33,3 +34,12 @@ func AppendToFile(content string, path string) error {
 
 	return file.Sync()
 }
+
+// WriteToFile replaces the content of a local file, creating its parent directories
+func WriteToFile(content string, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, []byte(content), 0644)
+}
@@ -2,6 +2,7 @@ package file
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -155,3 +156,24 @@ func TestAppendToFile(t *testing.T) {
 		}
 	})
 }
+
+func TestWriteToFile(t *testing.T) {
+	t.Run("should create parent directories and replace content", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "nested", "directory", "file.txt")
+
+		if err := WriteToFile("first", path); err != nil {
+			t.Fatalf("Incorrect result for WriteToFile, got error: %s", err)
+		}
+		if err := WriteToFile("second", path); err != nil {
+			t.Fatalf("Incorrect result for WriteToFile, got error: %s", err)
+		}
+
+		content, err := GetLocalFileContent(path)
+		if err != nil {
+			t.Fatalf("Incorrect result for WriteToFile, error reading file: %s", err)
+		}
+		if content != "second" {
+			t.Errorf("Incorrect result for WriteToFile, got: %s, want: %s", content, "second")
+		}
+	})
+}
@@ -0,0 +1,93 @@
+package plugins
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/vimcolorschemes/worker/internal/repository"
+)
+
+// plugins.json is the curated list of plugins and the highlight groups they
+// use. Bump its version whenever plugins or groups change, so coverages
+// computed from different lists can be told apart.
+//
+//go:embed plugins.json
+var content []byte
+
+// Plugin represents a plugin whose highlight groups colorschemes can define.
+// Plugins themed through a Lua module instead, like lualine.nvim, set Module
+// to the module containing one theme per colorscheme.
+type Plugin struct {
+	Name       string   `json:"name"`
+	Repository string   `json:"repository"`
+	Groups     []string `json:"groups,omitempty"`
+	Module     string   `json:"module,omitempty"`
+}
+
+// List represents a version of the curated plugin list
+type List struct {
+	Version int      `json:"version"`
+	Plugins []Plugin `json:"plugins"`
+}
+
+// Query represents what nvim found defined by a colorscheme background: the
+// highlight groups of the list, and the modules having a theme named after
+// the colorscheme
+type Query struct {
+	Groups  []string `json:"groups"`
+	Modules []string `json:"modules"`
+}
+
+// Load parses the curated plugin list
+func Load() (List, error) {
+	var list List
+	if err := json.Unmarshal(content, &list); err != nil {
+		return List{}, fmt.Errorf("parse plugin list: %w", err)
+	}
+	return list, nil
+}
+
+// Query returns the groups and modules nvim needs to look for
+func (list List) Query() Query {
+	query := Query{Groups: []string{}, Modules: []string{}}
+	for _, plugin := range list.Plugins {
+		for _, group := range plugin.Groups {
+			if !slices.Contains(query.Groups, group) {
+				query.Groups = append(query.Groups, group)
+			}
+		}
+		if plugin.Module != "" && !slices.Contains(query.Modules, plugin.Module) {
+			query.Modules = append(query.Modules, plugin.Module)
+		}
+	}
+	return query
+}
+
+// Support computes the coverage of every plugin from what nvim found
+// defined by a colorscheme background
+func (list List) Support(background repository.BackgroundValue, found Query) []repository.PluginSupport {
+	support := make([]repository.PluginSupport, 0, len(list.Plugins))
+	for _, plugin := range list.Plugins {
+		entry := repository.PluginSupport{
+			Background:  background,
+			Plugin:      plugin.Name,
+			ListVersion: list.Version,
+			GroupCount:  len(plugin.Groups),
+		}
+		for _, group := range plugin.Groups {
+			if slices.Contains(found.Groups, group) {
+				entry.DefinedGroupCount++
+			}
+		}
+		if plugin.Module != "" {
+			entry.GroupCount++
+			if slices.Contains(found.Modules, plugin.Module) {
+				entry.DefinedGroupCount++
+			}
+		}
+		support = append(support, entry)
+	}
+	return support
+}
@@ -0,0 +1,71 @@
+package plugins
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/vimcolorschemes/worker/internal/repository"
+)
+
+func TestLoad(t *testing.T) {
+	list, err := Load()
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if list.Version < 1 {
+		t.Fatalf("Version = %d, want at least 1", list.Version)
+	}
+
+	names := map[string]bool{}
+	for _, plugin := range list.Plugins {
+		if names[plugin.Name] {
+			t.Errorf("plugin %q is listed twice", plugin.Name)
+		}
+		names[plugin.Name] = true
+		if len(plugin.Groups) == 0 && plugin.Module == "" {
+			t.Errorf("plugin %q has no groups nor module", plugin.Name)
+		}
+	}
+
+	for _, name := range []string{"telescope.nvim", "nvim-cmp", "gitsigns.nvim", "nvim-tree.lua", "lualine.nvim"} {
+		if !names[name] {
+			t.Errorf("plugin %q is missing", name)
+		}
+	}
+}
+
+func TestListQuery(t *testing.T) {
+	list := List{Plugins: []Plugin{
+		{Name: "a", Groups: []string{"AGroup", "SharedGroup"}},
+		{Name: "b", Groups: []string{"SharedGroup"}},
+		{Name: "c", Module: "c.themes"},
+	}}
+
+	query := list.Query()
+	if !slices.Equal(query.Groups, []string{"AGroup", "SharedGroup"}) {
+		t.Fatalf("Groups = %v, want [AGroup SharedGroup]", query.Groups)
+	}
+	if !slices.Equal(query.Modules, []string{"c.themes"}) {
+		t.Fatalf("Modules = %v, want [c.themes]", query.Modules)
+	}
+}
+
+func TestListSupport(t *testing.T) {
+	list := List{Version: 2, Plugins: []Plugin{
+		{Name: "a", Groups: []string{"AOne", "ATwo"}},
+		{Name: "c", Module: "c.themes"},
+	}}
+
+	support := list.Support(repository.DarkBackground, Query{Groups: []string{"AOne"}, Modules: []string{"c.themes"}})
+
+	expected := []repository.PluginSupport{
+		{Background: repository.DarkBackground, Plugin: "a", ListVersion: 2, DefinedGroupCount: 1, GroupCount: 2},
+		{Background: repository.DarkBackground, Plugin: "c", ListVersion: 2, DefinedGroupCount: 1, GroupCount: 1},
+	}
+	if !slices.Equal(support, expected) {
+		t.Fatalf("Support() = %#v, want %#v", support, expected)
+	}
+	if !support[0].Supported() || support[0].Coverage() != 0.5 {
+		t.Fatalf("a coverage = %v, supported = %v, want 0.5, true", support[0].Coverage(), support[0].Supported())
+	}
+}
@@ -0,0 +1,234 @@
+package preview
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"image"
+	imagecolor "image/color"
+	"image/draw"
+	"image/png"
+	"strings"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+
+	"github.com/vimcolorschemes/worker/internal/color"
+	"github.com/vimcolorschemes/worker/internal/repository"
+)
+
+// StatusLineText is shown in the status line, like the "%f" statusline of vim/init.lua
+const StatusLineText = "code_sample.vim"
+
+// Layout of the preview grid, in character cells
+const (
+	gutterWidth = 4
+	padding     = 1
+)
+
+// SVG cell size in pixels, matching a 14px monospace font
+const (
+	svgFontSize   = 14
+	svgCellWidth  = 8.4
+	svgCellHeight = 20
+)
+
+// Theme represents the colors and attributes used to render a preview
+type Theme struct {
+	Background   color.RGB
+	Foreground   color.RGB
+	LineNr       style
+	LineNrBg     color.RGB
+	StatusLine   style
+	StatusLineBg color.RGB
+	groups       []repository.ColorschemeGroup
+}
+
+type style struct {
+	color     color.RGB
+	bold      bool
+	italic    bool
+	underline bool
+}
+
+// NewTheme resolves the preview colors of a colorscheme background, or
+// returns false if Normal colors are missing
+func NewTheme(groups []repository.ColorschemeGroup) (Theme, bool) {
+	groups = repository.ResolveLinks(groups)
+
+	normalBg, ok := groupStyle(groups, "NormalBg")
+	if !ok {
+		return Theme{}, false
+	}
+	normalFg, ok := groupStyle(groups, "NormalFg")
+	if !ok {
+		return Theme{}, false
+	}
+
+	theme := Theme{
+		Background: normalBg.color,
+		Foreground: normalFg.color,
+		LineNrBg:   normalBg.color,
+		groups:     groups,
+	}
+	theme.LineNr = theme.style("LineNrFg", "CommentFg")
+	if lineNrBg, ok := groupStyle(groups, "LineNrBg"); ok {
+		theme.LineNrBg = lineNrBg.color
+	}
+
+	// Without both colors, the status line is rendered like Vim's default reversed Normal
+	statusLineFg, hasStatusLineFg := groupStyle(groups, "StatusLineFg")
+	statusLineBg, hasStatusLineBg := groupStyle(groups, "StatusLineBg")
+	if hasStatusLineFg && hasStatusLineBg {
+		theme.StatusLine, theme.StatusLineBg = statusLineFg, statusLineBg.color
+	} else {
+		theme.StatusLine, theme.StatusLineBg = style{color: normalBg.color, bold: true}, normalFg.color
+	}
+
+	return theme, true
+}
+
+// RenderSVG renders the lines as a syntax highlighted SVG image
+func RenderSVG(theme Theme, lines []Line) string {
+	columns, rows := gridSize(lines)
+	width := float64(columns) * svgCellWidth
+	height := float64(rows) * svgCellHeight
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%s" height="%s" viewBox="0 0 %s %s" font-family="monospace" font-size="%d">`+"\n",
+		formatFloat(width), formatFloat(height), formatFloat(width), formatFloat(height), svgFontSize)
+	fmt.Fprintf(&b, `<rect width="100%%" height="100%%" fill="%s"/>`+"\n", theme.Background.Hex())
+	fmt.Fprintf(&b, `<rect width="%s" height="%s" fill="%s"/>`+"\n", formatFloat(gutterWidth*svgCellWidth), formatFloat(float64(rows-1)*svgCellHeight), theme.LineNrBg.Hex())
+
+	textY := func(row int) string {
+		return formatFloat((float64(row) + 0.7) * svgCellHeight)
+	}
+
+	for index, line := range lines {
+		row := index + padding
+		fmt.Fprintf(&b, `<text x="0" y="%s" xml:space="preserve"%s>%s</text>`+"\n", textY(row), svgStyleAttributes(theme.LineNr), lineNumber(index+1))
+
+		fmt.Fprintf(&b, `<text x="%s" y="%s" xml:space="preserve">`, formatFloat((gutterWidth+padding)*svgCellWidth), textY(row))
+		for _, token := range line {
+			fmt.Fprintf(&b, `<tspan%s>%s</tspan>`, svgStyleAttributes(theme.tokenStyle(token)), html.EscapeString(token.Text))
+		}
+		b.WriteString("</text>\n")
+	}
+
+	statusRow := rows - 1
+	fmt.Fprintf(&b, `<rect y="%s" width="100%%" height="%s" fill="%s"/>`+"\n", formatFloat(float64(statusRow)*svgCellHeight), formatFloat(svgCellHeight), theme.StatusLineBg.Hex())
+	fmt.Fprintf(&b, `<text x="0" y="%s" xml:space="preserve"%s>%s</text>`+"\n", textY(statusRow), svgStyleAttributes(theme.StatusLine), html.EscapeString(StatusLineText))
+	b.WriteString("</svg>\n")
+
+	return b.String()
+}
+
+// RenderPNG rasterizes the lines with a 7x13 bitmap font
+func RenderPNG(theme Theme, lines []Line) ([]byte, error) {
+	face := basicfont.Face7x13
+	cellWidth, cellHeight := face.Advance, face.Height
+	columns, rows := gridSize(lines)
+
+	img := image.NewRGBA(image.Rect(0, 0, columns*cellWidth, rows*cellHeight))
+	fill := func(rect image.Rectangle, value color.RGB) {
+		draw.Draw(img, rect, image.NewUniform(toImageColor(value)), image.Point{}, draw.Src)
+	}
+	fill(img.Bounds(), theme.Background)
+	fill(image.Rect(0, 0, gutterWidth*cellWidth, (rows-1)*cellHeight), theme.LineNrBg)
+	fill(image.Rect(0, (rows-1)*cellHeight, columns*cellWidth, rows*cellHeight), theme.StatusLineBg)
+
+	drawer := &font.Drawer{Dst: img, Face: face}
+	drawText := func(column int, row int, text string, value color.RGB) int {
+		drawer.Src = image.NewUniform(toImageColor(value))
+		drawer.Dot = fixed.P(column*cellWidth, row*cellHeight+face.Ascent)
+		drawer.DrawString(text)
+		return column + len([]rune(text))
+	}
+
+	for index, line := range lines {
+		row := index + padding
+		drawText(0, row, lineNumber(index+1), theme.LineNr.color)
+
+		column := gutterWidth + padding
+		for _, token := range line {
+			column = drawText(column, row, token.Text, theme.tokenStyle(token).color)
+		}
+	}
+	drawText(0, rows-1, StatusLineText, theme.StatusLine.color)
+
+	var buffer bytes.Buffer
+	if err := png.Encode(&buffer, img); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func (theme Theme) tokenStyle(token Token) style {
+	return theme.style(append([]string{token.Group}, groupFallbacks[token.Group]...)...)
+}
+
+// style returns the style of the first defined group, falling back to NormalFg
+func (theme Theme) style(names ...string) style {
+	for _, name := range names {
+		if value, ok := groupStyle(theme.groups, name); ok {
+			return value
+		}
+	}
+	return style{color: theme.Foreground}
+}
+
+func groupStyle(groups []repository.ColorschemeGroup, name string) (style, bool) {
+	for _, group := range groups {
+		if group.Name != name {
+			continue
+		}
+		value, err := color.ParseHex(group.HexCode)
+		if err != nil {
+			return style{}, false
+		}
+		return style{color: value, bold: group.Bold, italic: group.Italic, underline: group.Underline || group.Undercurl}, true
+	}
+	return style{}, false
+}
+
+// gridSize returns the number of columns and rows of the preview, including
+// the gutter, padding and status line
+func gridSize(lines []Line) (int, int) {
+	width := 0
+	for _, line := range lines {
+		length := 0
+		for _, token := range line {
+			length += len([]rune(token.Text))
+		}
+		width = max(width, length)
+	}
+	return gutterWidth + padding + width + padding, padding + len(lines) + padding + 1
+}
+
+func svgStyleAttributes(value style) string {
+	attributes := fmt.Sprintf(` fill="%s"`, value.color.Hex())
+	if value.bold {
+		attributes += ` font-weight="bold"`
+	}
+	if value.italic {
+		attributes += ` font-style="italic"`
+	}
+	if value.underline {
+		attributes += ` text-decoration="underline"`
+	}
+	return attributes
+}
+
+func lineNumber(number int) string {
+	return fmt.Sprintf("%*d ", gutterWidth-1, number)
+}
+
+func formatFloat(value float64) string {
+	return strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%.2f", value), "0"), ".")
+}
+
+func toImageColor(value color.RGB) imagecolor.RGBA {
+	r, g, b := value.Bytes()
+	return imagecolor.RGBA{R: r, G: g, B: b, A: 0xff}
+}
@@ -0,0 +1,82 @@
+package preview
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/vimcolorschemes/worker/internal/repository"
+)
+
+var testGroups = []repository.ColorschemeGroup{
+	{Name: "NormalBg", HexCode: "#1d2021"},
+	{Name: "NormalFg", HexCode: "#ebdbb2"},
+	{Name: "CommentFg", HexCode: "#928374", Italic: true},
+	{Name: "StatementFg", HexCode: "#fb4934", Bold: true},
+	{Name: "ConstantFg", HexCode: "#d3869b"},
+}
+
+func TestNewTheme(t *testing.T) {
+	t.Run("requires Normal colors", func(t *testing.T) {
+		if _, ok := NewTheme([]repository.ColorschemeGroup{{Name: "NormalBg", HexCode: "#000000"}}); ok {
+			t.Fatal("NewTheme() ok = true, want false")
+		}
+	})
+
+	t.Run("falls back to reversed Normal status line", func(t *testing.T) {
+		theme, ok := NewTheme(testGroups)
+		if !ok {
+			t.Fatal("NewTheme() ok = false, want true")
+		}
+		if theme.StatusLineBg.Hex() != "#ebdbb2" || theme.StatusLine.color.Hex() != "#1d2021" {
+			t.Fatalf("status line = %s on %s, want #1d2021 on #ebdbb2", theme.StatusLine.color.Hex(), theme.StatusLineBg.Hex())
+		}
+		if theme.LineNr.color.Hex() != "#928374" {
+			t.Fatalf("LineNr = %s, want comment color #928374", theme.LineNr.color.Hex())
+		}
+	})
+}
+
+func TestRenderSVG(t *testing.T) {
+	theme, _ := NewTheme(testGroups)
+	svg := RenderSVG(theme, TokenizeVim("\" <comment> & more\nlet x = 'a'\n"))
+
+	for _, expected := range []string{
+		`<svg xmlns="http://www.w3.org/2000/svg"`,
+		`<rect width="100%" height="100%" fill="#1d2021"/>`,
+		`<tspan fill="#928374" font-style="italic">&#34; &lt;comment&gt; &amp; more</tspan>`,
+		`<tspan fill="#fb4934" font-weight="bold">let</tspan>`,
+		`<tspan fill="#d3869b">&#39;a&#39;</tspan>`,
+		`>code_sample.vim</text>`,
+	} {
+		if !strings.Contains(svg, expected) {
+			t.Errorf("RenderSVG() does not contain %q:\n%s", expected, svg)
+		}
+	}
+}
+
+func TestRenderPNG(t *testing.T) {
+	theme, _ := NewTheme(testGroups)
+	content, err := RenderPNG(theme, TokenizeVim("let x = 1\n"))
+	if err != nil {
+		t.Fatalf("RenderPNG returned error: %v", err)
+	}
+
+	img, err := png.Decode(bytes.NewReader(content))
+	if err != nil {
+		t.Fatalf("png.Decode returned error: %v", err)
+	}
+
+	// 4 gutter + 1 padding + 9 characters + 1 padding columns, and
+	// 1 padding + 1 line + 1 padding + 1 status line rows
+	bounds := img.Bounds()
+	if bounds.Dx() != 15*7 || bounds.Dy() != 4*13 {
+		t.Fatalf("size = %dx%d, want %dx%d", bounds.Dx(), bounds.Dy(), 15*7, 4*13)
+	}
+
+	r, g, b, _ := img.At(bounds.Max.X-1, 0).RGBA()
+	if r>>8 != 0x1d || g>>8 != 0x20 || b>>8 != 0x21 {
+		t.Fatalf("background pixel = %02x%02x%02x, want 1d2021", r>>8, g>>8, b>>8)
+	}
+}
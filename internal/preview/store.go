@@ -0,0 +1,52 @@
+package preview
+
+import (
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+
+	file "github.com/vimcolorschemes/worker/internal/file"
+)
+
+// Store saves rendered previews and returns the URL they are served from.
+// Object stores can implement it to upload previews instead of writing them
+// to disk.
+type Store interface {
+	Put(key string, content []byte) (string, error)
+}
+
+// DirectoryStore writes previews to a local directory, served from BaseURL
+type DirectoryStore struct {
+	Path    string
+	BaseURL string
+}
+
+// Put writes the content under the directory and returns its URL, or its
+// file path when no base URL is configured
+func (store DirectoryStore) Put(key string, content []byte) (string, error) {
+	target := filepath.Join(store.Path, filepath.FromSlash(key))
+	if err := file.WriteToFile(string(content), target); err != nil {
+		return "", err
+	}
+
+	if store.BaseURL == "" {
+		return target, nil
+	}
+
+	return strings.TrimSuffix(store.BaseURL, "/") + "/" + escapeKey(key), nil
+}
+
+// Key returns the storage key of a colorscheme background preview, e.g.
+// "morhetz/gruvbox/gruvbox-dark.svg"
+func Key(owner string, repository string, fileName string, extension string) string {
+	return path.Join(owner, repository, fileName+"."+extension)
+}
+
+func escapeKey(key string) string {
+	segments := strings.Split(key, "/")
+	for index, segment := range segments {
+		segments[index] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
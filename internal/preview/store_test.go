@@ -0,0 +1,42 @@
+package preview
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDirectoryStorePut(t *testing.T) {
+	directoryPath := t.TempDir()
+	key := Key("owner", "repo", "my scheme-dark", "svg")
+
+	t.Run("returns the public URL", func(t *testing.T) {
+		store := DirectoryStore{Path: directoryPath, BaseURL: "https://previews.example.com/"}
+		url, err := store.Put(key, []byte("<svg/>"))
+		if err != nil {
+			t.Fatalf("Put returned error: %v", err)
+		}
+		if url != "https://previews.example.com/owner/repo/my%20scheme-dark.svg" {
+			t.Fatalf("url = %q", url)
+		}
+
+		content, err := os.ReadFile(filepath.Join(directoryPath, "owner", "repo", "my scheme-dark.svg"))
+		if err != nil {
+			t.Fatalf("ReadFile returned error: %v", err)
+		}
+		if string(content) != "<svg/>" {
+			t.Fatalf("content = %q, want %q", content, "<svg/>")
+		}
+	})
+
+	t.Run("returns the file path without base URL", func(t *testing.T) {
+		store := DirectoryStore{Path: directoryPath}
+		url, err := store.Put(key, []byte("<svg/>"))
+		if err != nil {
+			t.Fatalf("Put returned error: %v", err)
+		}
+		if url != filepath.Join(directoryPath, "owner", "repo", "my scheme-dark.svg") {
+			t.Fatalf("url = %q", url)
+		}
+	})
+}
@@ -0,0 +1,154 @@
+package preview
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Token represents a piece of source code highlighted with a group
+type Token struct {
+	Text  string
+	Group string
+}
+
+// Line represents a line of tokens
+type Line []Token
+
+// Groups used to highlight the code sample, named after extractor.nvim's
+// foreground groups
+const (
+	normalGroup     = "NormalFg"
+	commentGroup    = "CommentFg"
+	stringGroup     = "StringFg"
+	numberGroup     = "NumberFg"
+	statementGroup  = "StatementFg"
+	functionGroup   = "FunctionFg"
+	identifierGroup = "IdentifierFg"
+	operatorGroup   = "OperatorFg"
+)
+
+// groupFallbacks lists the groups to use, in order, when a colorscheme does
+// not define a group. NormalFg is the last resort of every group.
+var groupFallbacks = map[string][]string{
+	stringGroup:    {"ConstantFg"},
+	numberGroup:    {"ConstantFg"},
+	statementGroup: {"KeywordFg"},
+	functionGroup:  {"IdentifierFg"},
+	operatorGroup:  {"StatementFg"},
+}
+
+var vimKeywords = map[string]bool{
+	"abort":       true,
+	"call":        true,
+	"else":        true,
+	"elseif":      true,
+	"endfor":      true,
+	"endfunction": true,
+	"endif":       true,
+	"endwhile":    true,
+	"for":         true,
+	"function":    true,
+	"function!":   true,
+	"if":          true,
+	"in":          true,
+	"let":         true,
+	"return":      true,
+	"while":       true,
+}
+
+// TokenizeVim splits Vim script source code into highlighted lines. It only
+// knows about the constructs used by vim/code_sample.vim.
+func TokenizeVim(source string) []Line {
+	var lines []Line
+	for _, text := range strings.Split(strings.TrimRight(source, "\n"), "\n") {
+		lines = append(lines, tokenizeVimLine(text))
+	}
+	return lines
+}
+
+func tokenizeVimLine(text string) Line {
+	var line Line
+	add := func(value string, group string) {
+		if value == "" {
+			return
+		}
+		if len(line) > 0 && line[len(line)-1].Group == group {
+			line[len(line)-1].Text += value
+			return
+		}
+		line = append(line, Token{Text: value, Group: group})
+	}
+
+	trimmed := strings.TrimLeft(text, " \t")
+	add(text[:len(text)-len(trimmed)], normalGroup)
+	if strings.HasPrefix(trimmed, "\"") {
+		add(trimmed, commentGroup)
+		return line
+	}
+
+	runes := []rune(trimmed)
+	for index := 0; index < len(runes); {
+		r := runes[index]
+		switch {
+		case r == '\'' || r == '"':
+			end := scanString(runes, index)
+			add(string(runes[index:end]), stringGroup)
+			index = end
+		case unicode.IsDigit(r):
+			end := index
+			for end < len(runes) && (unicode.IsDigit(runes[end]) || runes[end] == '.') {
+				end++
+			}
+			add(string(runes[index:end]), numberGroup)
+			index = end
+		case unicode.IsLetter(r) || r == '_':
+			end := index
+			for end < len(runes) && (unicode.IsLetter(runes[end]) || unicode.IsDigit(runes[end]) || strings.ContainsRune("_:#", runes[end])) {
+				end++
+			}
+			if end < len(runes) && runes[end] == '!' && string(runes[index:end]) == "function" {
+				end++
+			}
+			word := string(runes[index:end])
+			switch {
+			case vimKeywords[word]:
+				add(word, statementGroup)
+			case end < len(runes) && runes[end] == '(':
+				add(word, functionGroup)
+			case strings.Contains(word, ":"):
+				add(word, identifierGroup)
+			default:
+				add(word, normalGroup)
+			}
+			index = end
+		case strings.ContainsRune("=+-*/<>!&|.", r):
+			add(string(r), operatorGroup)
+			index++
+		default:
+			add(string(r), normalGroup)
+			index++
+		}
+	}
+
+	return line
+}
+
+// scanString returns the index right after the string starting at start
+func scanString(runes []rune, start int) int {
+	quote := runes[start]
+	for index := start + 1; index < len(runes); index++ {
+		if quote == '"' && runes[index] == '\\' {
+			index++
+			continue
+		}
+		if runes[index] != quote {
+			continue
+		}
+		if quote == '\'' && index+1 < len(runes) && runes[index+1] == '\'' {
+			index++
+			continue
+		}
+		return index + 1
+	}
+	return len(runes)
+}
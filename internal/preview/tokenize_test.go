@@ -0,0 +1,56 @@
+package preview
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenizeVim(t *testing.T) {
+	lines := TokenizeVim("\" Returns true\nfunction! IsLight(color) abort\n  let l:value = str2nr('ff', 16)\nendfunction\n")
+
+	expected := []Line{
+		{{Text: "\" Returns true", Group: commentGroup}},
+		{
+			{Text: "function!", Group: statementGroup},
+			{Text: " ", Group: normalGroup},
+			{Text: "IsLight", Group: functionGroup},
+			{Text: "(color) ", Group: normalGroup},
+			{Text: "abort", Group: statementGroup},
+		},
+		{
+			{Text: "  ", Group: normalGroup},
+			{Text: "let", Group: statementGroup},
+			{Text: " ", Group: normalGroup},
+			{Text: "l:value", Group: identifierGroup},
+			{Text: " ", Group: normalGroup},
+			{Text: "=", Group: operatorGroup},
+			{Text: " ", Group: normalGroup},
+			{Text: "str2nr", Group: functionGroup},
+			{Text: "(", Group: normalGroup},
+			{Text: "'ff'", Group: stringGroup},
+			{Text: ", ", Group: normalGroup},
+			{Text: "16", Group: numberGroup},
+			{Text: ")", Group: normalGroup},
+		},
+		{{Text: "endfunction", Group: statementGroup}},
+	}
+
+	if !reflect.DeepEqual(lines, expected) {
+		t.Fatalf("TokenizeVim() = %#v, want %#v", lines, expected)
+	}
+}
+
+func TestScanString(t *testing.T) {
+	for _, test := range []struct {
+		source string
+		end    int
+	}{
+		{`'it''s' rest`, 7},
+		{`"a \" b" rest`, 8},
+		{`'unterminated`, 13},
+	} {
+		if end := scanString([]rune(test.source), 0); end != test.end {
+			t.Errorf("scanString(%q) = %d, want %d", test.source, end, test.end)
+		}
+	}
+}
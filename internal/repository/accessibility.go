@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"github.com/vimcolorschemes/worker/internal/color"
+)
+
+const (
+	// WCAG minimum contrast ratios for normal text
+	contrastRatioAA  = 4.5
+	contrastRatioAAA = 7
+
+	// NormalVision is the vision of accessibility checks run without simulation
+	NormalVision = "normal"
+)
+
+// AccessibilityLevel sets up an enum containing the WCAG levels a colorscheme can reach
+type AccessibilityLevel string
+
+const (
+	// AccessibilityLevelNone is set when at least one check fails AA
+	AccessibilityLevelNone AccessibilityLevel = ""
+
+	// AccessibilityLevelAA is set when all checks pass AA
+	AccessibilityLevelAA AccessibilityLevel = "aa"
+
+	// AccessibilityLevelAAA is set when all checks pass AAA
+	AccessibilityLevelAAA AccessibilityLevel = "aaa"
+)
+
+// AccessibilityCheck represents the contrast of a group pair for a given vision
+type AccessibilityCheck struct {
+	Name            string  `json:"name"`
+	Vision          string  `json:"vision"`
+	ForegroundColor string  `json:"foregroundColor"`
+	BackgroundColor string  `json:"backgroundColor"`
+	ContrastRatio   float64 `json:"contrastRatio"`
+	PassesAA        bool    `json:"passesAA"`
+	PassesAAA       bool    `json:"passesAAA"`
+}
+
+// AccessibilitySummary represents the accessibility of a set of colorscheme backgrounds
+type AccessibilitySummary struct {
+	Level   AccessibilityLevel `json:"level"`
+	CVDSafe bool               `json:"cvdSafe"`
+}
+
+// accessibilityPairs lists the audited pairs as foreground and background
+// groups, each falling back to the next name when missing
+var accessibilityPairs = []struct {
+	name       string
+	foreground []string
+	background []string
+}{
+	{"normal", []string{"NormalFg"}, []string{"NormalBg"}},
+	{"comment", []string{"CommentFg"}, []string{"NormalBg"}},
+	{"visual", []string{"VisualFg", "NormalFg"}, []string{"VisualBg"}},
+	{"search", []string{"SearchFg", "NormalFg"}, []string{"SearchBg"}},
+	{"diagnostic_error", []string{"DiagnosticErrorFg"}, []string{"NormalBg"}},
+	{"diagnostic_warn", []string{"DiagnosticWarnFg"}, []string{"NormalBg"}},
+	{"diagnostic_info", []string{"DiagnosticInfoFg"}, []string{"NormalBg"}},
+	{"diagnostic_hint", []string{"DiagnosticHintFg"}, []string{"NormalBg"}},
+}
+
+// AuditAccessibility checks the contrast of key group pairs of a colorscheme
+// background, as seen with normal vision and each color vision deficiency.
+// Pairs with a missing group are skipped.
+func AuditAccessibility(groups []ColorschemeGroup) []AccessibilityCheck {
+	var checks []AccessibilityCheck
+
+	for _, pair := range accessibilityPairs {
+		foreground, ok := parseFirstGroupColor(groups, pair.foreground)
+		if !ok {
+			continue
+		}
+		background, ok := parseFirstGroupColor(groups, pair.background)
+		if !ok {
+			continue
+		}
+
+		checks = append(checks, newAccessibilityCheck(pair.name, NormalVision, foreground, background))
+		for _, deficiency := range color.Deficiencies {
+			checks = append(checks, newAccessibilityCheck(
+				pair.name,
+				string(deficiency),
+				foreground.Simulate(deficiency),
+				background.Simulate(deficiency),
+			))
+		}
+	}
+
+	return checks
+}
+
+// SummarizeAccessibility returns the WCAG level reached by all normal vision
+// checks, and whether all checks pass AA for every simulated vision
+func SummarizeAccessibility(checks []AccessibilityCheck) AccessibilitySummary {
+	if len(checks) == 0 {
+		return AccessibilitySummary{}
+	}
+
+	level := AccessibilityLevelAAA
+	cvdSafe := true
+	for _, check := range checks {
+		if check.Vision != NormalVision {
+			cvdSafe = cvdSafe && check.PassesAA
+			continue
+		}
+		if !check.PassesAA {
+			level = AccessibilityLevelNone
+		} else if !check.PassesAAA && level == AccessibilityLevelAAA {
+			level = AccessibilityLevelAA
+		}
+	}
+
+	return AccessibilitySummary{Level: level, CVDSafe: cvdSafe && level != AccessibilityLevelNone}
+}
+
+// BestAccessibilitySummary returns the best of the summaries, ranked by level
+// then CVD safety, so a repository is badged as soon as one of its colorscheme
+// backgrounds qualifies. The summary is returned whole, the badge never
+// combining the level of a background with the CVD safety of another.
+func BestAccessibilitySummary(summaries []AccessibilitySummary) AccessibilitySummary {
+	rank := map[AccessibilityLevel]int{
+		AccessibilityLevelNone: 0,
+		AccessibilityLevelAA:   1,
+		AccessibilityLevelAAA:  2,
+	}
+	better := func(a AccessibilitySummary, b AccessibilitySummary) bool {
+		if rank[a.Level] != rank[b.Level] {
+			return rank[a.Level] > rank[b.Level]
+		}
+		return a.CVDSafe && !b.CVDSafe
+	}
+
+	best := AccessibilitySummary{}
+	for _, summary := range summaries {
+		if better(summary, best) {
+			best = summary
+		}
+	}
+	return best
+}
+
+func newAccessibilityCheck(name string, vision string, foreground color.RGB, background color.RGB) AccessibilityCheck {
+	ratio := color.ContrastRatio(foreground, background)
+	return AccessibilityCheck{
+		Name:            name,
+		Vision:          vision,
+		ForegroundColor: foreground.Hex(),
+		BackgroundColor: background.Hex(),
+		ContrastRatio:   ratio,
+		PassesAA:        ratio >= contrastRatioAA,
+		PassesAAA:       ratio >= contrastRatioAAA,
+	}
+}
+
+func parseFirstGroupColor(groups []ColorschemeGroup, names []string) (color.RGB, bool) {
+	for _, name := range names {
+		if value, ok := parseGroupColor(groups, name); ok {
+			return value, true
+		}
+	}
+	return color.RGB{}, false
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"testing"
+)
+
+func TestAuditAccessibility(t *testing.T) {
+	t.Run("should skip pairs with missing groups", func(t *testing.T) {
+		checks := AuditAccessibility([]ColorschemeGroup{
+			{Name: "NormalBg", HexCode: "#000000"},
+			{Name: "NormalFg", HexCode: "#ffffff"},
+		})
+		if len(checks) != 4 {
+			t.Fatalf("Incorrect result for AuditAccessibility, got length: %d, want length: 4", len(checks))
+		}
+		for _, check := range checks {
+			if check.Name != "normal" {
+				t.Errorf("Incorrect result for AuditAccessibility, got check: %s, want: normal", check.Name)
+			}
+		}
+	})
+
+	t.Run("should fall back to Normal foreground for visual", func(t *testing.T) {
+		checks := AuditAccessibility([]ColorschemeGroup{
+			{Name: "NormalFg", HexCode: "#ffffff"},
+			{Name: "VisualBg", HexCode: "#333333"},
+		})
+		if len(checks) == 0 || checks[0].Name != "visual" || checks[0].ForegroundColor != "#ffffff" {
+			t.Fatalf("Incorrect result for AuditAccessibility, got %+v, want visual check with NormalFg", checks)
+		}
+	})
+
+	t.Run("should flag low contrast comments", func(t *testing.T) {
+		checks := AuditAccessibility([]ColorschemeGroup{
+			{Name: "NormalBg", HexCode: "#002b36"},
+			{Name: "CommentFg", HexCode: "#586e75"},
+		})
+		if checks[0].Vision != NormalVision || checks[0].PassesAA {
+			t.Fatalf("Incorrect result for AuditAccessibility, got %+v, want failing normal vision check", checks[0])
+		}
+	})
+}
+
+func TestSummarizeAccessibility(t *testing.T) {
+	t.Run("should reach AAA with high contrast grays", func(t *testing.T) {
+		summary := SummarizeAccessibility(AuditAccessibility([]ColorschemeGroup{
+			{Name: "NormalBg", HexCode: "#000000"},
+			{Name: "NormalFg", HexCode: "#ffffff"},
+		}))
+		if summary.Level != AccessibilityLevelAAA || !summary.CVDSafe {
+			t.Errorf("Incorrect result for SummarizeAccessibility, got %+v, want AAA and CVD safe", summary)
+		}
+	})
+
+	t.Run("should not be CVD safe when a deficiency fails AA", func(t *testing.T) {
+		summary := SummarizeAccessibility([]AccessibilityCheck{
+			{Name: "normal", Vision: NormalVision, PassesAA: true},
+			{Name: "normal", Vision: "protanopia", PassesAA: false},
+		})
+		if summary.Level != AccessibilityLevelAA || summary.CVDSafe {
+			t.Errorf("Incorrect result for SummarizeAccessibility, got %+v, want AA and not CVD safe", summary)
+		}
+	})
+
+	t.Run("should be empty without checks", func(t *testing.T) {
+		summary := SummarizeAccessibility(nil)
+		if summary != (AccessibilitySummary{}) {
+			t.Errorf("Incorrect result for SummarizeAccessibility, got %+v, want empty summary", summary)
+		}
+	})
+}
+
+func TestBestAccessibilitySummary(t *testing.T) {
+	t.Run("should not combine summaries", func(t *testing.T) {
+		best := BestAccessibilitySummary([]AccessibilitySummary{
+			{Level: AccessibilityLevelNone},
+			{Level: AccessibilityLevelAAA},
+			{Level: AccessibilityLevelAA, CVDSafe: true},
+		})
+		if best.Level != AccessibilityLevelAAA || best.CVDSafe {
+			t.Errorf("Incorrect result for BestAccessibilitySummary, got %+v, want AAA and not CVD safe", best)
+		}
+	})
+
+	t.Run("should prefer CVD safe summaries of the same level", func(t *testing.T) {
+		best := BestAccessibilitySummary([]AccessibilitySummary{
+			{Level: AccessibilityLevelAA},
+			{Level: AccessibilityLevelAA, CVDSafe: true},
+		})
+		if best.Level != AccessibilityLevelAA || !best.CVDSafe {
+			t.Errorf("Incorrect result for BestAccessibilitySummary, got %+v, want AA and CVD safe", best)
+		}
+	})
+}
@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"strings"
+
+	"github.com/vimcolorschemes/worker/internal/color"
+)
+
+// PaletteAnalytics represents the derived palette attributes of a colorscheme background
+type PaletteAnalytics struct {
+	BackgroundLuminance float64 `json:"backgroundLuminance"`
+	ContrastRatio       float64 `json:"contrastRatio"`
+	ColorCount          int     `json:"colorCount"`
+	HueFamily           string  `json:"hueFamily"`
+	Temperature         string  `json:"temperature"`
+	Saturation          float64 `json:"saturation"`
+	SaturationLevel     string  `json:"saturationLevel"`
+}
+
+// hueFamilies maps the upper bound (exclusive, in degrees) of each hue range to its family
+var hueFamilies = []struct {
+	upperBound float64
+	name       string
+}{
+	{15, "red"},
+	{45, "orange"},
+	{70, "yellow"},
+	{165, "green"},
+	{200, "cyan"},
+	{260, "blue"},
+	{290, "purple"},
+	{335, "pink"},
+	{360, "red"},
+}
+
+const (
+	// Colors less saturated than this are considered gray and do not count towards the hue family
+	chromaticSaturationThreshold = 0.2
+
+	// Share of chromatic weight a temperature needs to be the palette's temperature
+	temperatureThreshold = 0.6
+)
+
+// ComputePaletteAnalytics derives palette attributes from the groups of a
+// colorscheme background, or returns false if Normal colors are missing
+func ComputePaletteAnalytics(groups []ColorschemeGroup) (PaletteAnalytics, bool) {
+	normalBg, ok := parseGroupColor(groups, "NormalBg")
+	if !ok {
+		return PaletteAnalytics{}, false
+	}
+	normalFg, ok := parseGroupColor(groups, "NormalFg")
+	if !ok {
+		return PaletteAnalytics{}, false
+	}
+
+	colors := distinctColors(groups)
+
+	familyWeights := map[string]float64{}
+	warmWeight, coolWeight := 0.0, 0.0
+	saturationSum, lightnessSum := 0.0, 0.0
+	foregroundCount := 0
+
+	for _, value := range colors {
+		if value == normalBg {
+			continue
+		}
+
+		hue, saturation, lightness := value.HSL()
+		saturationSum += saturation
+		lightnessSum += lightness
+		foregroundCount++
+
+		if saturation < chromaticSaturationThreshold || lightness < 0.15 || lightness > 0.9 {
+			continue
+		}
+
+		familyWeights[hueFamily(hue)] += saturation
+		if hue < 90 || hue >= 300 {
+			warmWeight += saturation
+		} else {
+			coolWeight += saturation
+		}
+	}
+
+	saturation, lightness := 0.0, 0.0
+	if foregroundCount > 0 {
+		saturation = saturationSum / float64(foregroundCount)
+		lightness = lightnessSum / float64(foregroundCount)
+	}
+
+	return PaletteAnalytics{
+		BackgroundLuminance: normalBg.Luminance(),
+		ContrastRatio:       color.ContrastRatio(normalFg, normalBg),
+		ColorCount:          len(colors),
+		HueFamily:           dominantHueFamily(familyWeights),
+		Temperature:         paletteTemperature(warmWeight, coolWeight),
+		Saturation:          saturation,
+		SaturationLevel:     saturationLevel(saturation, lightness),
+	}, true
+}
+
+func distinctColors(groups []ColorschemeGroup) []color.RGB {
+	seen := map[string]bool{}
+	var colors []color.RGB
+	for _, group := range groups {
+		key := strings.ToLower(group.HexCode)
+		if seen[key] {
+			continue
+		}
+		value, err := color.ParseHex(key)
+		if err != nil {
+			continue
+		}
+		seen[key] = true
+		colors = append(colors, value)
+	}
+	return colors
+}
+
+func hueFamily(hue float64) string {
+	for _, family := range hueFamilies {
+		if hue < family.upperBound {
+			return family.name
+		}
+	}
+	return "red"
+}
+
+func dominantHueFamily(weights map[string]float64) string {
+	dominant, dominantWeight := "neutral", 0.0
+	for _, family := range hueFamilies {
+		if weights[family.name] > dominantWeight {
+			dominant, dominantWeight = family.name, weights[family.name]
+		}
+	}
+	return dominant
+}
+
+func paletteTemperature(warmWeight float64, coolWeight float64) string {
+	total := warmWeight + coolWeight
+	if total == 0 {
+		return "neutral"
+	}
+	if warmWeight/total >= temperatureThreshold {
+		return "warm"
+	}
+	if coolWeight/total >= temperatureThreshold {
+		return "cool"
+	}
+	return "balanced"
+}
+
+func saturationLevel(saturation float64, lightness float64) string {
+	switch {
+	case saturation < 0.1:
+		return "grayscale"
+	case saturation < 0.35:
+		return "muted"
+	case lightness >= 0.7:
+		return "pastel"
+	case saturation < 0.65:
+		return "moderate"
+	default:
+		return "vivid"
+	}
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"math"
+	"testing"
+)
+
+func TestComputePaletteAnalytics(t *testing.T) {
+	t.Run("should require Normal colors", func(t *testing.T) {
+		_, ok := ComputePaletteAnalytics([]ColorschemeGroup{{Name: "NormalFg", HexCode: "#ffffff"}})
+		if ok {
+			t.Error("Incorrect result for ComputePaletteAnalytics, got ok without NormalBg")
+		}
+	})
+
+	t.Run("should compute contrast and luminance", func(t *testing.T) {
+		analytics, ok := ComputePaletteAnalytics([]ColorschemeGroup{
+			{Name: "NormalBg", HexCode: "#000000"},
+			{Name: "NormalFg", HexCode: "#FFFFFF"},
+			{Name: "CommentFg", HexCode: "#ffffff"},
+		})
+		if !ok {
+			t.Fatal("Incorrect result for ComputePaletteAnalytics, got not ok")
+		}
+		if analytics.BackgroundLuminance != 0 {
+			t.Errorf("Incorrect result for ComputePaletteAnalytics, got background luminance: %f, want: 0", analytics.BackgroundLuminance)
+		}
+		if math.Abs(analytics.ContrastRatio-21) > 0.001 {
+			t.Errorf("Incorrect result for ComputePaletteAnalytics, got contrast ratio: %f, want: 21", analytics.ContrastRatio)
+		}
+		if analytics.ColorCount != 2 {
+			t.Errorf("Incorrect result for ComputePaletteAnalytics, got color count: %d, want: 2", analytics.ColorCount)
+		}
+		if analytics.HueFamily != "neutral" || analytics.Temperature != "neutral" || analytics.SaturationLevel != "grayscale" {
+			t.Errorf("Incorrect result for ComputePaletteAnalytics, got %+v, want neutral grayscale palette", analytics)
+		}
+	})
+
+	t.Run("should classify warm vivid palettes", func(t *testing.T) {
+		analytics, _ := ComputePaletteAnalytics([]ColorschemeGroup{
+			{Name: "NormalBg", HexCode: "#1d2021"},
+			{Name: "NormalFg", HexCode: "#fb4934"},
+			{Name: "StringFg", HexCode: "#fe8019"},
+			{Name: "KeywordFg", HexCode: "#ff2010"},
+		})
+		if analytics.HueFamily != "red" {
+			t.Errorf("Incorrect result for ComputePaletteAnalytics, got hue family: %s, want: red", analytics.HueFamily)
+		}
+		if analytics.Temperature != "warm" {
+			t.Errorf("Incorrect result for ComputePaletteAnalytics, got temperature: %s, want: warm", analytics.Temperature)
+		}
+		if analytics.SaturationLevel != "vivid" {
+			t.Errorf("Incorrect result for ComputePaletteAnalytics, got saturation level: %s, want: vivid", analytics.SaturationLevel)
+		}
+	})
+
+	t.Run("should classify cool pastel palettes", func(t *testing.T) {
+		analytics, _ := ComputePaletteAnalytics([]ColorschemeGroup{
+			{Name: "NormalBg", HexCode: "#1e1e2e"},
+			{Name: "NormalFg", HexCode: "#89b4fa"},
+			{Name: "StringFg", HexCode: "#a6e3a1"},
+			{Name: "KeywordFg", HexCode: "#b4befe"},
+		})
+		if analytics.HueFamily != "blue" {
+			t.Errorf("Incorrect result for ComputePaletteAnalytics, got hue family: %s, want: blue", analytics.HueFamily)
+		}
+		if analytics.Temperature != "cool" {
+			t.Errorf("Incorrect result for ComputePaletteAnalytics, got temperature: %s, want: cool", analytics.Temperature)
+		}
+		if analytics.SaturationLevel != "pastel" {
+			t.Errorf("Incorrect result for ComputePaletteAnalytics, got saturation level: %s, want: pastel", analytics.SaturationLevel)
+		}
+	})
+}
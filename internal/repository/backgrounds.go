@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"math"
+	"slices"
+)
+
+// BackgroundMismatch is the reason a claimed background is wrong
+type BackgroundMismatch string
+
+const (
+	// LuminanceMismatch is a background whose Normal background color is on
+	// the other side of the luminance threshold, like a light background
+	// emitting dark colors
+	LuminanceMismatch BackgroundMismatch = "luminance"
+
+	// IdenticalMismatch is a background with the same data as the other
+	// background, as when a colorscheme ignores the background option
+	IdenticalMismatch BackgroundMismatch = "identical"
+)
+
+// Relative luminance of a mid gray, separating dark from light backgrounds
+const backgroundLuminanceThreshold = 0.18
+
+// Confidence from which a luminance mismatch corrects the claimed background
+const backgroundCorrectionConfidence = 0.5
+
+// BackgroundCheck represents the validation of a background a colorscheme
+// was extracted for, against the luminance of its Normal background color
+type BackgroundCheck struct {
+	Background BackgroundValue `json:"background"`
+	// Detected is the corrected classification, the claimed background when
+	// Normal has no background color
+	Detected            BackgroundValue `json:"detected"`
+	BackgroundLuminance float64         `json:"backgroundLuminance"`
+	// Confidence in the detected background, from 0 at the threshold to 1
+	// for black or white
+	Confidence float64            `json:"confidence"`
+	Mismatch   BackgroundMismatch `json:"mismatch,omitempty"`
+}
+
+// ValidateBackgrounds checks each background of the colorscheme data
+func ValidateBackgrounds(data ColorschemeData) []BackgroundCheck {
+	identical := len(data.Light) > 0 && slices.Equal(data.Light, data.Dark)
+
+	var checks []BackgroundCheck
+	for _, bg := range []struct {
+		value  BackgroundValue
+		groups []ColorschemeGroup
+	}{
+		{LightBackground, data.Light},
+		{DarkBackground, data.Dark},
+	} {
+		if len(bg.groups) == 0 {
+			continue
+		}
+
+		check := BackgroundCheck{Background: bg.value, Detected: bg.value}
+		if normalBg, ok := parseGroupColor(bg.groups, "NormalBg"); ok {
+			check.BackgroundLuminance = normalBg.Luminance()
+			check.Detected, check.Confidence = classifyBackground(check.BackgroundLuminance)
+			if check.Detected != bg.value {
+				check.Mismatch = LuminanceMismatch
+			}
+		}
+		if identical {
+			check.Mismatch = IdenticalMismatch
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// DetectedBackgrounds returns the backgrounds of the checks, corrected by
+// the luminance mismatches confident enough. Identical backgrounds are the
+// same colors extracted twice, counted once as the detected background.
+func DetectedBackgrounds(checks []BackgroundCheck) []BackgroundValue {
+	var backgrounds []BackgroundValue
+	identicalCounted := false
+	for _, check := range checks {
+		background := check.Background
+		switch {
+		case check.Mismatch == IdenticalMismatch:
+			if identicalCounted {
+				continue
+			}
+			identicalCounted = true
+			background = check.Detected
+		case check.Mismatch == LuminanceMismatch && check.Confidence >= backgroundCorrectionConfidence:
+			background = check.Detected
+		}
+
+		if !slices.Contains(backgrounds, background) {
+			backgrounds = append(backgrounds, background)
+		}
+	}
+	return backgrounds
+}
+
+func classifyBackground(luminance float64) (BackgroundValue, float64) {
+	if luminance < backgroundLuminanceThreshold {
+		return DarkBackground, math.Min(1, (backgroundLuminanceThreshold-luminance)/backgroundLuminanceThreshold)
+	}
+	return LightBackground, math.Min(1, (luminance-backgroundLuminanceThreshold)/(1-backgroundLuminanceThreshold))
+}
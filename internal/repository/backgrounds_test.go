@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestValidateBackgrounds(t *testing.T) {
+	dark := []ColorschemeGroup{{Name: "NormalBg", HexCode: "#000000"}, {Name: "NormalFg", HexCode: "#ffffff"}}
+	light := []ColorschemeGroup{{Name: "NormalBg", HexCode: "#ffffff"}, {Name: "NormalFg", HexCode: "#000000"}}
+
+	t.Run("should confirm matching backgrounds", func(t *testing.T) {
+		checks := ValidateBackgrounds(ColorschemeData{Light: light, Dark: dark})
+		if len(checks) != 2 {
+			t.Fatalf("Incorrect result for ValidateBackgrounds, got %d checks, want 2", len(checks))
+		}
+		for _, check := range checks {
+			if check.Detected != check.Background || check.Mismatch != "" || math.Abs(check.Confidence-1) > 0.001 {
+				t.Errorf("Incorrect result for ValidateBackgrounds, got %+v, want a confident match", check)
+			}
+		}
+	})
+
+	t.Run("should flag a light background emitting dark colors", func(t *testing.T) {
+		checks := ValidateBackgrounds(ColorschemeData{Light: []ColorschemeGroup{{Name: "NormalBg", HexCode: "#282828"}}})
+		if len(checks) != 1 || checks[0].Detected != DarkBackground || checks[0].Mismatch != LuminanceMismatch {
+			t.Fatalf("Incorrect result for ValidateBackgrounds, got %+v, want a dark luminance mismatch", checks)
+		}
+		if checks[0].Confidence <= 0.5 || checks[0].Confidence >= 1 {
+			t.Errorf("Incorrect result for ValidateBackgrounds, got confidence %f, want between 0.5 and 1", checks[0].Confidence)
+		}
+	})
+
+	t.Run("should flag identical backgrounds", func(t *testing.T) {
+		checks := ValidateBackgrounds(ColorschemeData{Light: dark, Dark: slices.Clone(dark)})
+		for _, check := range checks {
+			if check.Mismatch != IdenticalMismatch || check.Detected != DarkBackground {
+				t.Errorf("Incorrect result for ValidateBackgrounds, got %+v, want an identical dark background", check)
+			}
+		}
+		if backgrounds := DetectedBackgrounds(checks); !slices.Equal(backgrounds, []BackgroundValue{DarkBackground}) {
+			t.Errorf("Incorrect result for DetectedBackgrounds, got %v, want [dark]", backgrounds)
+		}
+	})
+
+	t.Run("should keep the claimed background without Normal", func(t *testing.T) {
+		checks := ValidateBackgrounds(ColorschemeData{Light: []ColorschemeGroup{{Name: "CommentFg", HexCode: "#888888"}}})
+		if len(checks) != 1 || checks[0].Detected != LightBackground || checks[0].Confidence != 0 || checks[0].Mismatch != "" {
+			t.Errorf("Incorrect result for ValidateBackgrounds, got %+v, want the unconfident claimed background", checks)
+		}
+	})
+}
+
+func TestDetectedBackgrounds(t *testing.T) {
+	tests := []struct {
+		name     string
+		checks   []BackgroundCheck
+		expected []BackgroundValue
+	}{
+		{
+			"should keep matching backgrounds",
+			[]BackgroundCheck{{Background: LightBackground, Detected: LightBackground}, {Background: DarkBackground, Detected: DarkBackground}},
+			[]BackgroundValue{LightBackground, DarkBackground},
+		},
+		{
+			"should correct confident mismatches",
+			[]BackgroundCheck{{Background: LightBackground, Detected: DarkBackground, Confidence: 0.9, Mismatch: LuminanceMismatch}},
+			[]BackgroundValue{DarkBackground},
+		},
+		{
+			"should keep the claimed background of unconfident mismatches",
+			[]BackgroundCheck{{Background: LightBackground, Detected: DarkBackground, Confidence: 0.1, Mismatch: LuminanceMismatch}},
+			[]BackgroundValue{LightBackground},
+		},
+		{
+			"should count identical backgrounds once",
+			[]BackgroundCheck{
+				{Background: LightBackground, Detected: LightBackground, Mismatch: IdenticalMismatch},
+				{Background: DarkBackground, Detected: DarkBackground, Mismatch: IdenticalMismatch},
+			},
+			[]BackgroundValue{LightBackground},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if backgrounds := DetectedBackgrounds(test.checks); !slices.Equal(backgrounds, test.expected) {
+				t.Errorf("Incorrect result for DetectedBackgrounds, got %v, want %v", backgrounds, test.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// GroupDiff represents the groups added, removed and changed between two
+// extractions of a colorscheme background
+type GroupDiff struct {
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+	Changed []string `json:"changed,omitempty"`
+}
+
+// DataDiff represents the differences between two extractions of the
+// colorschemes of a repository
+type DataDiff struct {
+	AddedColorschemes   []string                                 `json:"addedColorschemes,omitempty"`
+	RemovedColorschemes []string                                 `json:"removedColorschemes,omitempty"`
+	ChangedColorschemes map[string]map[BackgroundValue]GroupDiff `json:"changedColorschemes,omitempty"`
+}
+
+// IsEmpty returns true if both sets of groups are identical
+func (diff GroupDiff) IsEmpty() bool {
+	return len(diff.Added) == 0 && len(diff.Removed) == 0 && len(diff.Changed) == 0
+}
+
+// IsEmpty returns true if both extractions are identical
+func (diff DataDiff) IsEmpty() bool {
+	return len(diff.AddedColorschemes) == 0 && len(diff.RemovedColorschemes) == 0 && len(diff.ChangedColorschemes) == 0
+}
+
+// DiffGroups compares two sets of groups by name. A group is changed when
+// its color, attributes or link differ.
+func DiffGroups(before []ColorschemeGroup, after []ColorschemeGroup) GroupDiff {
+	beforeGroups := groupsByName(before)
+	afterGroups := groupsByName(after)
+
+	var diff GroupDiff
+	for _, name := range slices.Sorted(maps.Keys(afterGroups)) {
+		group, existed := beforeGroups[name]
+		switch {
+		case !existed:
+			diff.Added = append(diff.Added, name)
+		case group != afterGroups[name]:
+			diff.Changed = append(diff.Changed, name)
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(beforeGroups)) {
+		if _, exists := afterGroups[name]; !exists {
+			diff.Removed = append(diff.Removed, name)
+		}
+	}
+	return diff
+}
+
+// DiffColorschemeData compares both backgrounds of two extractions of a
+// colorscheme, leaving out the identical backgrounds
+func DiffColorschemeData(before ColorschemeData, after ColorschemeData) map[BackgroundValue]GroupDiff {
+	diffs := map[BackgroundValue]GroupDiff{}
+	for _, background := range []BackgroundValue{LightBackground, DarkBackground} {
+		if diff := DiffGroups(before.Groups(background), after.Groups(background)); !diff.IsEmpty() {
+			diffs[background] = diff
+		}
+	}
+	return diffs
+}
+
+// DiffData compares two extractions of colorschemes keyed by name
+func DiffData(before map[string]ColorschemeData, after map[string]ColorschemeData) DataDiff {
+	var diff DataDiff
+	for _, name := range slices.Sorted(maps.Keys(after)) {
+		beforeData, existed := before[name]
+		if !existed {
+			diff.AddedColorschemes = append(diff.AddedColorschemes, name)
+			continue
+		}
+		if changes := DiffColorschemeData(beforeData, after[name]); len(changes) > 0 {
+			if diff.ChangedColorschemes == nil {
+				diff.ChangedColorschemes = map[string]map[BackgroundValue]GroupDiff{}
+			}
+			diff.ChangedColorschemes[name] = changes
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(before)) {
+		if _, exists := after[name]; !exists {
+			diff.RemovedColorschemes = append(diff.RemovedColorschemes, name)
+		}
+	}
+	return diff
+}
+
+func groupsByName(groups []ColorschemeGroup) map[string]ColorschemeGroup {
+	byName := make(map[string]ColorschemeGroup, len(groups))
+	for _, group := range groups {
+		byName[group.Name] = group
+	}
+	return byName
+}
+
+// ChangeSummary counts the differences of a DataDiff
+type ChangeSummary struct {
+	AddedColorschemes   int `json:"addedColorschemes"`
+	RemovedColorschemes int `json:"removedColorschemes"`
+	ChangedColorschemes int `json:"changedColorschemes"`
+	AddedGroups         int `json:"addedGroups"`
+	RemovedGroups       int `json:"removedGroups"`
+	ChangedGroups       int `json:"changedGroups"`
+}
+
+// Summary counts the colorschemes and groups of the diff, the groups of each
+// background counted separately
+func (diff DataDiff) Summary() ChangeSummary {
+	summary := ChangeSummary{
+		AddedColorschemes:   len(diff.AddedColorschemes),
+		RemovedColorschemes: len(diff.RemovedColorschemes),
+		ChangedColorschemes: len(diff.ChangedColorschemes),
+	}
+	for _, backgrounds := range diff.ChangedColorschemes {
+		for _, groupDiff := range backgrounds {
+			summary.AddedGroups += len(groupDiff.Added)
+			summary.RemovedGroups += len(groupDiff.Removed)
+			summary.ChangedGroups += len(groupDiff.Changed)
+		}
+	}
+	return summary
+}
+
+// String describes the summary, e.g. "1 colorscheme added, 3 groups changed"
+func (summary ChangeSummary) String() string {
+	var parts []string
+	for _, count := range []struct {
+		value    int
+		singular string
+		plural   string
+	}{
+		{summary.AddedColorschemes, "colorscheme added", "colorschemes added"},
+		{summary.RemovedColorschemes, "colorscheme removed", "colorschemes removed"},
+		{summary.AddedGroups, "group added", "groups added"},
+		{summary.RemovedGroups, "group removed", "groups removed"},
+		{summary.ChangedGroups, "group changed", "groups changed"},
+	} {
+		switch {
+		case count.value == 1:
+			parts = append(parts, "1 "+count.singular)
+		case count.value > 1:
+			parts = append(parts, strconv.Itoa(count.value)+" "+count.plural)
+		}
+	}
+	if len(parts) == 0 {
+		return "no changes"
+	}
+	return strings.Join(parts, ", ")
+}
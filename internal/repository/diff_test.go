@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffGroups(t *testing.T) {
+	before := []ColorschemeGroup{
+		{Name: "NormalFg", HexCode: "#ffffff"},
+		{Name: "CommentFg", HexCode: "#777777"},
+		{Name: "StringFg", HexCode: "#00ff00"},
+	}
+	after := []ColorschemeGroup{
+		{Name: "NormalFg", HexCode: "#ffffff"},
+		{Name: "CommentFg", HexCode: "#777777", Italic: true},
+		{Name: "@stringFg", HexCode: "#00ff00"},
+	}
+
+	diff := DiffGroups(before, after)
+
+	expected := GroupDiff{Added: []string{"@stringFg"}, Removed: []string{"StringFg"}, Changed: []string{"CommentFg"}}
+	if !reflect.DeepEqual(diff, expected) {
+		t.Fatalf("DiffGroups() = %#v, want %#v", diff, expected)
+	}
+	if !DiffGroups(before, before).IsEmpty() {
+		t.Fatal("DiffGroups() of identical groups is not empty")
+	}
+}
+
+func TestDiffData(t *testing.T) {
+	before := map[string]ColorschemeData{
+		"kept":    {Dark: []ColorschemeGroup{{Name: "NormalBg", HexCode: "#000000"}}},
+		"changed": {Dark: []ColorschemeGroup{{Name: "NormalBg", HexCode: "#000000"}}},
+		"removed": {Light: []ColorschemeGroup{{Name: "NormalBg", HexCode: "#ffffff"}}},
+	}
+	after := map[string]ColorschemeData{
+		"kept":    {Dark: []ColorschemeGroup{{Name: "NormalBg", HexCode: "#000000"}}},
+		"changed": {Dark: []ColorschemeGroup{{Name: "NormalBg", HexCode: "#111111"}}},
+		"added":   {Light: []ColorschemeGroup{{Name: "NormalBg", HexCode: "#ffffff"}}},
+	}
+
+	diff := DiffData(before, after)
+
+	expected := DataDiff{
+		AddedColorschemes:   []string{"added"},
+		RemovedColorschemes: []string{"removed"},
+		ChangedColorschemes: map[string]map[BackgroundValue]GroupDiff{
+			"changed": {DarkBackground: {Changed: []string{"NormalBg"}}},
+		},
+	}
+	if !reflect.DeepEqual(diff, expected) {
+		t.Fatalf("DiffData() = %#v, want %#v", diff, expected)
+	}
+	if !DiffData(before, before).IsEmpty() {
+		t.Fatal("DiffData() of identical data is not empty")
+	}
+}
+
+func TestDataDiffSummary(t *testing.T) {
+	diff := DataDiff{
+		AddedColorschemes: []string{"added"},
+		ChangedColorschemes: map[string]map[BackgroundValue]GroupDiff{
+			"changed": {
+				DarkBackground:  {Added: []string{"@keyword"}, Changed: []string{"NormalBg", "CommentFg"}},
+				LightBackground: {Changed: []string{"NormalBg"}},
+			},
+		},
+	}
+
+	summary := diff.Summary()
+	expected := ChangeSummary{AddedColorschemes: 1, ChangedColorschemes: 1, AddedGroups: 1, ChangedGroups: 3}
+	if summary != expected {
+		t.Fatalf("Summary() = %+v, want %+v", summary, expected)
+	}
+	if text := summary.String(); text != "1 colorscheme added, 1 group added, 3 groups changed" {
+		t.Fatalf("String() = %q", text)
+	}
+	if text := (ChangeSummary{}).String(); text != "no changes" {
+		t.Fatalf("String() = %q, want no changes", text)
+	}
+}
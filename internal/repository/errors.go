@@ -0,0 +1,160 @@
+package repository
+
+import "errors"
+
+// MaxOutputExcerptLength is the length each excerpt of an ErrorOutput is
+// bounded to
+const MaxOutputExcerptLength = 4096
+
+// ErrorCode classifies a generate error
+type ErrorCode string
+
+const (
+	// CloneTimeoutError is a clone that ran out of time
+	CloneTimeoutError ErrorCode = "clone_timeout"
+
+	// CloneNotFoundError is a repository that doesn't exist anymore or can't
+	// be read
+	CloneNotFoundError ErrorCode = "clone_not_found"
+
+	// NoColorsError is a repository without colors/*.vim or colors/*.lua
+	// files, rejected before extraction
+	NoColorsError ErrorCode = "no_colors"
+
+	// NvimTimeoutError is an extraction that ran out of time
+	NvimTimeoutError ErrorCode = "nvim_timeout"
+
+	// NvimCrashError is an editor that exited with a failure, or was killed
+	NvimCrashError ErrorCode = "nvim_crash"
+
+	// LuaError is an extraction that failed while running the colorscheme code
+	LuaError ErrorCode = "lua_error"
+
+	// EmptyOutputError is an extraction that didn't output any data
+	EmptyOutputError ErrorCode = "empty_output"
+
+	// InvalidJSONError is an extraction whose output couldn't be parsed
+	InvalidJSONError ErrorCode = "invalid_json"
+
+	// DBWriteError is a generate result that couldn't be stored
+	DBWriteError ErrorCode = "db_write"
+
+	// UnknownError is any other error
+	UnknownError ErrorCode = "unknown"
+)
+
+// GenerateError is a generate error classified by its code, with the output
+// of the editor that failed
+type GenerateError struct {
+	Code   ErrorCode
+	Err    error
+	Output ErrorOutput
+}
+
+func (err *GenerateError) Error() string {
+	return err.Err.Error()
+}
+
+func (err *GenerateError) Unwrap() error {
+	return err.Err
+}
+
+// ErrorOutput represents the excerpts of what an editor printed while
+// extracting colors
+type ErrorOutput struct {
+	// Colorscheme is the last colorscheme the editor loaded
+	Colorscheme string `json:"colorscheme,omitempty"`
+	Stdout      string `json:"stdout,omitempty"`
+	Stderr      string `json:"stderr,omitempty"`
+	// Messages is the output of :messages
+	Messages string `json:"messages,omitempty"`
+	// ErrorMessage is the last error message, v:errmsg
+	ErrorMessage string `json:"errorMessage,omitempty"`
+}
+
+// NewGenerateError classifies err with the code, returning nil if err is nil
+func NewGenerateError(code ErrorCode, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &GenerateError{Code: code, Err: err}
+}
+
+// WithErrorOutput attaches the editor output to err, keeping its code
+func WithErrorOutput(err error, output ErrorOutput) error {
+	if err == nil || output.IsEmpty() {
+		return err
+	}
+	return &GenerateError{Code: ErrorCodeOf(err), Err: err, Output: output}
+}
+
+// ErrorOutputOf returns the editor output attached to err
+func ErrorOutputOf(err error) ErrorOutput {
+	for err != nil {
+		var generateError *GenerateError
+		if !errors.As(err, &generateError) {
+			break
+		}
+		if !generateError.Output.IsEmpty() {
+			return generateError.Output
+		}
+		err = generateError.Err
+	}
+	return ErrorOutput{}
+}
+
+// IsEmpty returns true if the editor printed nothing
+func (output ErrorOutput) IsEmpty() bool {
+	return output == ErrorOutput{}
+}
+
+// Excerpt bounds each output to MaxOutputExcerptLength, keeping the end of
+// the streams and the beginning of the messages where the first error is
+func (output ErrorOutput) Excerpt() ErrorOutput {
+	return ErrorOutput{
+		Colorscheme:  output.Colorscheme,
+		Stdout:       tail(output.Stdout, MaxOutputExcerptLength),
+		Stderr:       tail(output.Stderr, MaxOutputExcerptLength),
+		Messages:     head(output.Messages, MaxOutputExcerptLength),
+		ErrorMessage: head(output.ErrorMessage, MaxOutputExcerptLength),
+	}
+}
+
+func head(value string, length int) string {
+	if len(value) <= length {
+		return value
+	}
+	return value[:length]
+}
+
+func tail(value string, length int) string {
+	if len(value) <= length {
+		return value
+	}
+	return value[len(value)-length:]
+}
+
+// ErrorCodeOf returns the code of the first GenerateError wrapped by err,
+// UnknownError when there's none
+func ErrorCodeOf(err error) ErrorCode {
+	var generateError *GenerateError
+	if errors.As(err, &generateError) {
+		return generateError.Code
+	}
+	return UnknownError
+}
+
+// FailureReason returns the reason retrying a failure of the code
+func (code ErrorCode) FailureReason() FailureReason {
+	switch code {
+	case CloneTimeoutError, NvimTimeoutError:
+		return TimeoutFailure
+	case CloneNotFoundError:
+		return CloneFailure
+	case NvimCrashError, LuaError:
+		return LuaFailure
+	case NoColorsError, EmptyOutputError, InvalidJSONError:
+		return EmptyDataFailure
+	}
+	return UnknownFailure
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestErrorCodeOf(t *testing.T) {
+	err := fmt.Errorf("getting color data: %w", NewGenerateError(InvalidJSONError, errors.New("unexpected end of JSON input")))
+	if code := ErrorCodeOf(err); code != InvalidJSONError {
+		t.Errorf("ErrorCodeOf(%q) = %s, want %s", err, code, InvalidJSONError)
+	}
+	if err.Error() != "getting color data: unexpected end of JSON input" {
+		t.Errorf("GenerateError should keep the message of its error, got %q", err)
+	}
+
+	if code := ErrorCodeOf(errors.New("database is locked")); code != UnknownError {
+		t.Errorf("ErrorCodeOf(untyped) = %s, want %s", code, UnknownError)
+	}
+
+	if NewGenerateError(LuaError, nil) != nil {
+		t.Error("NewGenerateError(nil) should return nil")
+	}
+}
+
+func TestErrorCodeFailureReason(t *testing.T) {
+	for code, expected := range map[ErrorCode]FailureReason{
+		CloneTimeoutError:  TimeoutFailure,
+		NvimTimeoutError:   TimeoutFailure,
+		CloneNotFoundError: CloneFailure,
+		NvimCrashError:     LuaFailure,
+		LuaError:           LuaFailure,
+		NoColorsError:      EmptyDataFailure,
+		EmptyOutputError:   EmptyDataFailure,
+		InvalidJSONError:   EmptyDataFailure,
+		DBWriteError:       UnknownFailure,
+		UnknownError:       UnknownFailure,
+	} {
+		if reason := code.FailureReason(); reason != expected {
+			t.Errorf("%s.FailureReason() = %s, want %s", code, reason, expected)
+		}
+	}
+}
+
+func TestErrorOutput(t *testing.T) {
+	output := ErrorOutput{Colorscheme: "mytest", ErrorMessage: "E5113: Error while calling lua chunk"}
+	err := fmt.Errorf("getting color data: %w", WithErrorOutput(NewGenerateError(NvimCrashError, errors.New("exit status 1")), output))
+
+	if ErrorCodeOf(err) != NvimCrashError {
+		t.Errorf("ErrorCodeOf() = %s, want the code to be kept", ErrorCodeOf(err))
+	}
+	if ErrorOutputOf(err) != output {
+		t.Errorf("ErrorOutputOf() = %+v, want %+v", ErrorOutputOf(err), output)
+	}
+	if !ErrorOutputOf(NewGenerateError(LuaError, errors.New("boom"))).IsEmpty() {
+		t.Error("ErrorOutputOf() should be empty without output")
+	}
+	if WithErrorOutput(nil, output) != nil {
+		t.Error("WithErrorOutput(nil) should return nil")
+	}
+
+	long := ErrorOutput{
+		Stderr:   strings.Repeat("a", MaxOutputExcerptLength) + "end",
+		Messages: "start" + strings.Repeat("b", MaxOutputExcerptLength),
+	}.Excerpt()
+	if len(long.Stderr) != MaxOutputExcerptLength || !strings.HasSuffix(long.Stderr, "end") {
+		t.Errorf("Excerpt() should keep the end of stderr, got %d bytes", len(long.Stderr))
+	}
+	if len(long.Messages) != MaxOutputExcerptLength || !strings.HasPrefix(long.Messages, "start") {
+		t.Errorf("Excerpt() should keep the beginning of the messages, got %d bytes", len(long.Messages))
+	}
+}
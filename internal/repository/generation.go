@@ -0,0 +1,71 @@
+package repository
+
+import "time"
+
+// Toolchain identifies the tools colorschemes are generated with
+type Toolchain struct {
+	ExtractorSHA string `json:"extractorSHA"`
+	LushSHA      string `json:"lushSHA"`
+	NvimVersion  string `json:"nvimVersion"`
+	VimVersion   string `json:"vimVersion"`
+	// InputsHash is a checksum of the vim files, code samples and dependency
+	// table the colorschemes are extracted with
+	InputsHash string `json:"inputsHash"`
+	// DependencyCommits are the commits of the dependencies keyed by name, the
+	// ones installed for a generation or the ones of the run
+	DependencyCommits map[string]string `json:"dependencyCommits,omitempty"`
+}
+
+// EditorVersion returns the version of the editor the runner drives
+func (toolchain Toolchain) EditorVersion(runner Runner) string {
+	if runner == VimRunner {
+		return toolchain.VimVersion
+	}
+	return toolchain.NvimVersion
+}
+
+// Generation records what a successful generate of a repository was built
+// from, and what it changed since the previous generation
+type Generation struct {
+	ID        int64     `json:"id,omitempty"`
+	CommitSHA string    `json:"commitSHA"`
+	Runner    Runner    `json:"runner"`
+	Toolchain Toolchain `json:"toolchain"`
+	CreatedAt time.Time `json:"createdAt"`
+	// Changes is empty for the first generation of a repository
+	Changes DataDiff `json:"changes"`
+}
+
+// Matches returns true if generating the commit with the runner and toolchain
+// would reproduce the generation
+func (generation Generation) Matches(commitSHA string, runner Runner, toolchain Toolchain) bool {
+	return generation.CommitSHA != "" &&
+		generation.CommitSHA == commitSHA &&
+		generation.Runner == runner &&
+		generation.Toolchain.ExtractorSHA == toolchain.ExtractorSHA &&
+		generation.Toolchain.LushSHA == toolchain.LushSHA &&
+		generation.Toolchain.InputsHash == toolchain.InputsHash &&
+		generation.Toolchain.EditorVersion(runner) == toolchain.EditorVersion(runner) &&
+		!generation.Toolchain.hasNewDependencyCommits(toolchain)
+}
+
+// Returns true if a dependency of the toolchain is at another commit in the
+// other toolchain, the dependencies the other doesn't know being left out
+func (toolchain Toolchain) hasNewDependencyCommits(other Toolchain) bool {
+	for name, commit := range toolchain.DependencyCommits {
+		if current, ok := other.DependencyCommits[name]; ok && current != commit {
+			return true
+		}
+	}
+	return false
+}
+
+// Snapshot returns the data of the colorschemes keyed by name, as stored on
+// each generation
+func Snapshot(colorschemes []Colorscheme) map[string]ColorschemeData {
+	snapshot := make(map[string]ColorschemeData, len(colorschemes))
+	for _, colorscheme := range colorschemes {
+		snapshot[colorscheme.Name] = colorscheme.Data
+	}
+	return snapshot
+}
@@ -0,0 +1,51 @@
+package repository
+
+import "testing"
+
+func TestGenerationMatches(t *testing.T) {
+	toolchain := Toolchain{ExtractorSHA: "e1", LushSHA: "l1", NvimVersion: "NVIM v0.10.4", VimVersion: "VIM - Vi IMproved 9.0", InputsHash: "i1"}
+	generation := Generation{CommitSHA: "c1", Runner: NeovimRunner, Toolchain: toolchain}
+
+	withNvim := toolchain
+	withNvim.NvimVersion = "NVIM v0.11.0"
+	withVim := toolchain
+	withVim.VimVersion = "VIM - Vi IMproved 9.1"
+	withExtractor := toolchain
+	withExtractor.ExtractorSHA = "e2"
+	withInputs := toolchain
+	withInputs.InputsHash = "i2"
+	withDependency := generation
+	withDependency.Toolchain.DependencyCommits = map[string]string{"lush.nvim": "d1"}
+	newDependency := toolchain
+	newDependency.DependencyCommits = map[string]string{"lush.nvim": "d2", "plenary.nvim": "p1"}
+	otherDependency := toolchain
+	otherDependency.DependencyCommits = map[string]string{"plenary.nvim": "p1"}
+
+	tests := []struct {
+		name       string
+		generation Generation
+		commitSHA  string
+		runner     Runner
+		toolchain  Toolchain
+		expected   bool
+	}{
+		{"matches the same sources", generation, "c1", NeovimRunner, toolchain, true},
+		{"differs with a new commit", generation, "c2", NeovimRunner, toolchain, false},
+		{"differs with another runner", generation, "c1", VimRunner, toolchain, false},
+		{"differs with a new extractor", generation, "c1", NeovimRunner, withExtractor, false},
+		{"differs with new extraction inputs", generation, "c1", NeovimRunner, withInputs, false},
+		{"differs with a new dependency commit", withDependency, "c1", NeovimRunner, newDependency, false},
+		{"ignores the dependencies it didn't install", withDependency, "c1", NeovimRunner, otherDependency, true},
+		{"differs with a new nvim", generation, "c1", NeovimRunner, withNvim, false},
+		{"ignores the version of the other editor", generation, "c1", NeovimRunner, withVim, true},
+		{"never matches an unknown commit", Generation{Runner: NeovimRunner, Toolchain: toolchain}, "", NeovimRunner, toolchain, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if matches := test.generation.Matches(test.commitSHA, test.runner, test.toolchain); matches != test.expected {
+				t.Fatalf("Matches = %v, want %v", matches, test.expected)
+			}
+		})
+	}
+}
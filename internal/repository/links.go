@@ -0,0 +1,76 @@
+package repository
+
+import "strings"
+
+// groupSuffixes lists the suffixes extractor.nvim appends to highlight names
+var groupSuffixes = []string{"Fg", "Bg", "Sp"}
+
+// SplitGroupName splits an extracted group name into its highlight name and
+// its "Fg", "Bg" or "Sp" suffix, which is empty for names without one
+func SplitGroupName(name string) (string, string) {
+	for _, suffix := range groupSuffixes {
+		if len(name) > len(suffix) && strings.HasSuffix(name, suffix) {
+			return strings.TrimSuffix(name, suffix), suffix
+		}
+	}
+	return name, ""
+}
+
+// ApplyLinks sets the link target of every group whose highlight is linked,
+// links being keyed by highlight name (e.g. "Comment" => "Constant")
+func ApplyLinks(groups []ColorschemeGroup, links map[string]string) []ColorschemeGroup {
+	linked := make([]ColorschemeGroup, len(groups))
+	for index, group := range groups {
+		highlight, _ := SplitGroupName(group.Name)
+		if link, ok := links[highlight]; ok && link != highlight {
+			group.Link = link
+		}
+		linked[index] = group
+	}
+	return linked
+}
+
+// ResolveLinks returns the groups with the effective color of every linked
+// group lacking its own hex code, following chains of links
+func ResolveLinks(groups []ColorschemeGroup) []ColorschemeGroup {
+	resolved := make([]ColorschemeGroup, len(groups))
+	for index, group := range groups {
+		if group.HexCode == "" {
+			group.HexCode, _ = GroupHexCode(groups, group.Name)
+		}
+		resolved[index] = group
+	}
+	return resolved
+}
+
+// resolveGroup returns the group matching the name, following links until a
+// group with a hex code is found
+func resolveGroup(groups []ColorschemeGroup, name string) (ColorschemeGroup, bool) {
+	visited := map[string]bool{}
+	for !visited[name] {
+		visited[name] = true
+
+		group, ok := findGroup(groups, name)
+		if !ok {
+			return ColorschemeGroup{}, false
+		}
+		if group.HexCode != "" || group.Link == "" {
+			return group, true
+		}
+
+		_, suffix := SplitGroupName(name)
+		name = group.Link + suffix
+	}
+
+	// Links loop without reaching a color
+	return ColorschemeGroup{}, false
+}
+
+func findGroup(groups []ColorschemeGroup, name string) (ColorschemeGroup, bool) {
+	for _, group := range groups {
+		if group.Name == name {
+			return group, true
+		}
+	}
+	return ColorschemeGroup{}, false
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitGroupName(t *testing.T) {
+	for name, expected := range map[string][2]string{
+		"CommentFg":         {"Comment", "Fg"},
+		"NormalBg":          {"Normal", "Bg"},
+		"SpellBadSp":        {"SpellBad", "Sp"},
+		"@keyword.returnFg": {"@keyword.return", "Fg"},
+		"Fg":                {"Fg", ""},
+		"Conceal":           {"Conceal", ""},
+	} {
+		highlight, suffix := SplitGroupName(name)
+		if highlight != expected[0] || suffix != expected[1] {
+			t.Errorf("SplitGroupName(%q) = %q, %q, want %q, %q", name, highlight, suffix, expected[0], expected[1])
+		}
+	}
+}
+
+func TestApplyLinks(t *testing.T) {
+	groups := ApplyLinks([]ColorschemeGroup{
+		{Name: "CommentFg", HexCode: "#777777"},
+		{Name: "CommentBg", HexCode: "#000000"},
+		{Name: "NormalFg", HexCode: "#ffffff"},
+	}, map[string]string{"Comment": "Constant", "Normal": "Normal"})
+
+	expected := []ColorschemeGroup{
+		{Name: "CommentFg", HexCode: "#777777", Link: "Constant"},
+		{Name: "CommentBg", HexCode: "#000000", Link: "Constant"},
+		{Name: "NormalFg", HexCode: "#ffffff"},
+	}
+	if !reflect.DeepEqual(groups, expected) {
+		t.Fatalf("ApplyLinks() = %#v, want %#v", groups, expected)
+	}
+}
+
+func TestResolveLinks(t *testing.T) {
+	groups := []ColorschemeGroup{
+		{Name: "ConstantFg", HexCode: "#ff0000"},
+		{Name: "StringFg", Link: "Constant"},
+		{Name: "@stringFg", Link: "String"},
+		{Name: "LoopAFg", Link: "LoopB"},
+		{Name: "LoopBFg", Link: "LoopA"},
+		{Name: "MissingFg", Link: "Unknown"},
+	}
+
+	resolved := ResolveLinks(groups)
+
+	for name, expected := range map[string]string{
+		"ConstantFg": "#ff0000",
+		"StringFg":   "#ff0000",
+		"@stringFg":  "#ff0000",
+		"LoopAFg":    "",
+		"MissingFg":  "",
+	} {
+		group, _ := findGroup(resolved, name)
+		if group.HexCode != expected {
+			t.Errorf("%s HexCode = %q, want %q", name, group.HexCode, expected)
+		}
+	}
+	if resolved[2].Link != "String" {
+		t.Fatalf("Link = %q, want links to be kept", resolved[2].Link)
+	}
+}
+
+func TestGroupHexCodeFollowsLinks(t *testing.T) {
+	groups := []ColorschemeGroup{
+		{Name: "ConstantFg", HexCode: "#ff0000"},
+		{Name: "StringFg", Link: "Constant"},
+	}
+
+	hexCode, ok := GroupHexCode(groups, "StringFg")
+	if !ok || hexCode != "#ff0000" {
+		t.Fatalf("GroupHexCode() = %q, %v, want %q, true", hexCode, ok, "#ff0000")
+	}
+
+	if _, ok := GroupHexCode(groups, "UnknownFg"); ok {
+		t.Fatal("GroupHexCode(UnknownFg) ok = true, want false")
+	}
+}
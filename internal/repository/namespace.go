@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"slices"
+	"strings"
+)
+
+// GroupNamespace represents the highlighting system a group belongs to
+type GroupNamespace string
+
+const (
+	// SyntaxNamespace is the namespace of legacy Vim syntax groups, e.g. "Comment"
+	SyntaxNamespace GroupNamespace = "syntax"
+	// TreesitterNamespace is the namespace of Treesitter captures, e.g. "@function.builtin"
+	TreesitterNamespace GroupNamespace = "treesitter"
+	// LSPNamespace is the namespace of LSP semantic tokens, e.g. "@lsp.type.function"
+	LSPNamespace GroupNamespace = "lsp"
+)
+
+// Namespace returns the namespace of the group, derived from its name
+func (group ColorschemeGroup) Namespace() GroupNamespace {
+	switch {
+	case strings.HasPrefix(group.Name, "@lsp."):
+		return LSPNamespace
+	case strings.HasPrefix(group.Name, "@"):
+		return TreesitterNamespace
+	}
+	return SyntaxNamespace
+}
+
+// HasNamespace returns true if any group belongs to the namespace
+func HasNamespace(groups []ColorschemeGroup, namespace GroupNamespace) bool {
+	for _, group := range groups {
+		if group.Namespace() == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// MergeGroups appends the groups of extra that are not already defined in
+// data, for each background
+func MergeGroups(data ColorschemeData, extra ColorschemeData) ColorschemeData {
+	return ColorschemeData{
+		Light: mergeGroups(data.Light, extra.Light),
+		Dark:  mergeGroups(data.Dark, extra.Dark),
+	}
+}
+
+func mergeGroups(groups []ColorschemeGroup, extra []ColorschemeGroup) []ColorschemeGroup {
+	if len(groups) == 0 {
+		// Captures alone don't make a background supported
+		return groups
+	}
+
+	names := make(map[string]bool, len(groups))
+	for _, group := range groups {
+		names[group.Name] = true
+	}
+
+	merged := slices.Clip(groups)
+	for _, group := range extra {
+		if !names[group.Name] {
+			merged = append(merged, group)
+			names[group.Name] = true
+		}
+	}
+	return merged
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestColorschemeGroupNamespace(t *testing.T) {
+	for name, expected := range map[string]GroupNamespace{
+		"CommentFg":             SyntaxNamespace,
+		"@keywordFg":            TreesitterNamespace,
+		"@function.builtinFg":   TreesitterNamespace,
+		"@lsp.type.functionFg":  LSPNamespace,
+		"@lsp.mod.deprecatedSp": LSPNamespace,
+	} {
+		if namespace := (ColorschemeGroup{Name: name}).Namespace(); namespace != expected {
+			t.Errorf("Namespace(%q) = %q, want %q", name, namespace, expected)
+		}
+	}
+}
+
+func TestHasNamespace(t *testing.T) {
+	groups := []ColorschemeGroup{{Name: "NormalFg"}, {Name: "@keywordFg"}}
+	if !HasNamespace(groups, TreesitterNamespace) {
+		t.Fatal("HasNamespace(treesitter) = false, want true")
+	}
+	if HasNamespace(groups, LSPNamespace) {
+		t.Fatal("HasNamespace(lsp) = true, want false")
+	}
+}
+
+func TestMergeGroups(t *testing.T) {
+	data := ColorschemeData{
+		Dark: []ColorschemeGroup{{Name: "NormalFg", HexCode: "#ffffff"}, {Name: "@keywordFg", HexCode: "#ff0000"}},
+	}
+	extra := ColorschemeData{
+		Light: []ColorschemeGroup{{Name: "@keywordFg", HexCode: "#0000ff"}},
+		Dark:  []ColorschemeGroup{{Name: "@keywordFg", HexCode: "#00ff00"}, {Name: "@lsp.type.functionFg", HexCode: "#ffff00"}},
+	}
+
+	merged := MergeGroups(data, extra)
+
+	expected := ColorschemeData{
+		Dark: []ColorschemeGroup{
+			{Name: "NormalFg", HexCode: "#ffffff"},
+			{Name: "@keywordFg", HexCode: "#ff0000"},
+			{Name: "@lsp.type.functionFg", HexCode: "#ffff00"},
+		},
+	}
+	if !reflect.DeepEqual(merged, expected) {
+		t.Fatalf("MergeGroups() = %#v, want %#v", merged, expected)
+	}
+}
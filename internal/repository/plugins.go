@@ -0,0 +1,28 @@
+package repository
+
+// SupportedPluginCoverage is the share of a plugin's groups a colorscheme
+// must define to support it
+const SupportedPluginCoverage = 0.5
+
+// PluginSupport represents how many highlight groups of a plugin a
+// colorscheme background explicitly defines
+type PluginSupport struct {
+	Background        BackgroundValue `json:"background"`
+	Plugin            string          `json:"plugin"`
+	ListVersion       int             `json:"listVersion"`
+	DefinedGroupCount int             `json:"definedGroupCount"`
+	GroupCount        int             `json:"groupCount"`
+}
+
+// Coverage returns the share of the plugin's groups defined, from 0 to 1
+func (support PluginSupport) Coverage() float64 {
+	if support.GroupCount == 0 {
+		return 0
+	}
+	return float64(support.DefinedGroupCount) / float64(support.GroupCount)
+}
+
+// Supported returns true if enough of the plugin's groups are defined
+func (support PluginSupport) Supported() bool {
+	return support.GroupCount > 0 && support.Coverage() >= SupportedPluginCoverage
+}
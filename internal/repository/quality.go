@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/vimcolorschemes/worker/internal/color"
+)
+
+// QualityPolicy decides which extracted colorschemes are good enough to be
+// shown
+type QualityPolicy struct {
+	// MinGroupCount is the number of groups each background needs
+	MinGroupCount int
+	// MinColorCount is the number of distinct colors each background needs
+	MinColorCount int
+	// MinContrastRatio is the contrast Normal needs between its foreground
+	// and background colors
+	MinContrastRatio float64
+	// RequiredGroups are the groups each background needs
+	RequiredGroups []string
+}
+
+// DefaultQualityPolicy rejects the backgrounds with almost no groups, a
+// single color, or an unreadable Normal
+var DefaultQualityPolicy = QualityPolicy{
+	MinGroupCount:    10,
+	MinColorCount:    3,
+	MinContrastRatio: 1.5,
+	RequiredGroups:   []string{"NormalFg", "NormalBg"},
+}
+
+// Quality represents how a colorscheme scored against a quality policy
+type Quality struct {
+	// Score is the share of the checks passed by the backgrounds, from 0 to 1
+	Score float64 `json:"score"`
+	// Reasons explain each failed check, e.g. "dark: 4 groups, below 10"
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+// Passed returns true if the colorscheme failed no check
+func (quality Quality) Passed() bool {
+	return len(quality.Reasons) == 0
+}
+
+// Check scores every background of the colorscheme data. A colorscheme
+// without any background fails.
+func (policy QualityPolicy) Check(data ColorschemeData) Quality {
+	var quality Quality
+	checkCount, passedCount := 0, 0
+
+	for _, bg := range []struct {
+		value  BackgroundValue
+		groups []ColorschemeGroup
+	}{
+		{LightBackground, data.Light},
+		{DarkBackground, data.Dark},
+	} {
+		if len(bg.groups) == 0 {
+			continue
+		}
+
+		for _, reason := range policy.checkBackground(bg.groups) {
+			checkCount++
+			if reason == "" {
+				passedCount++
+				continue
+			}
+			quality.Reasons = append(quality.Reasons, fmt.Sprintf("%s: %s", bg.value, reason))
+		}
+	}
+
+	if checkCount == 0 {
+		quality.Reasons = append(quality.Reasons, "no background extracted")
+		return quality
+	}
+	quality.Score = float64(passedCount) / float64(checkCount)
+	return quality
+}
+
+// Returns the reason each check failed, empty for the checks passed
+func (policy QualityPolicy) checkBackground(groups []ColorschemeGroup) []string {
+	reasons := make([]string, 4)
+
+	if len(groups) < policy.MinGroupCount {
+		reasons[0] = fmt.Sprintf("%d groups, below %d", len(groups), policy.MinGroupCount)
+	}
+
+	if colorCount := len(distinctColors(groups)); colorCount < policy.MinColorCount {
+		reasons[1] = fmt.Sprintf("%d distinct colors, below %d", colorCount, policy.MinColorCount)
+	}
+
+	normalBg, hasBg := parseGroupColor(groups, "NormalBg")
+	normalFg, hasFg := parseGroupColor(groups, "NormalFg")
+	if hasBg && hasFg {
+		if ratio := color.ContrastRatio(normalFg, normalBg); ratio < policy.MinContrastRatio {
+			reasons[2] = fmt.Sprintf("Normal contrast ratio %.2f, below %.2f", ratio, policy.MinContrastRatio)
+		}
+	}
+
+	var missing []string
+	for _, name := range policy.RequiredGroups {
+		if !slices.ContainsFunc(groups, func(group ColorschemeGroup) bool { return group.Name == name }) {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		reasons[3] = fmt.Sprintf("missing %v", missing)
+	}
+
+	return reasons
+}
+
+// IsQualityEligible returns true if at least one of the colorschemes of a
+// repository passed its quality checks
+func IsQualityEligible(colorschemes []Colorscheme) bool {
+	return slices.ContainsFunc(colorschemes, func(colorscheme Colorscheme) bool {
+		return !colorscheme.Hidden
+	})
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func qualityGroups(count int) []ColorschemeGroup {
+	groups := []ColorschemeGroup{
+		{Name: "NormalBg", HexCode: "#1d2021"},
+		{Name: "NormalFg", HexCode: "#ebdbb2"},
+	}
+	for index := len(groups); index < count; index++ {
+		groups = append(groups, ColorschemeGroup{Name: fmt.Sprintf("Group%dFg", index), HexCode: fmt.Sprintf("#%02x8040", index*8)})
+	}
+	return groups
+}
+
+func TestQualityPolicyCheck(t *testing.T) {
+	t.Run("should pass complete colorschemes", func(t *testing.T) {
+		quality := DefaultQualityPolicy.Check(ColorschemeData{Dark: qualityGroups(12)})
+		if !quality.Passed() || quality.Score != 1 {
+			t.Errorf("Incorrect result for Check, got %+v, want a passing score of 1", quality)
+		}
+	})
+
+	t.Run("should fail backgrounds with too few groups", func(t *testing.T) {
+		quality := DefaultQualityPolicy.Check(ColorschemeData{Dark: qualityGroups(12), Light: qualityGroups(4)})
+		if !slices.Equal(quality.Reasons, []string{"light: 4 groups, below 10"}) {
+			t.Errorf("Incorrect result for Check, got reasons %v", quality.Reasons)
+		}
+		if quality.Score != 7.0/8 {
+			t.Errorf("Incorrect result for Check, got score %f, want %f", quality.Score, 7.0/8)
+		}
+	})
+
+	t.Run("should fail identical colors and unreadable Normal", func(t *testing.T) {
+		groups := qualityGroups(12)
+		for index := range groups {
+			groups[index].HexCode = "#808080"
+		}
+		quality := DefaultQualityPolicy.Check(ColorschemeData{Dark: groups})
+		if !slices.Equal(quality.Reasons, []string{
+			"dark: 1 distinct colors, below 3",
+			"dark: Normal contrast ratio 1.00, below 1.50",
+		}) {
+			t.Errorf("Incorrect result for Check, got reasons %v", quality.Reasons)
+		}
+	})
+
+	t.Run("should fail missing required groups", func(t *testing.T) {
+		quality := DefaultQualityPolicy.Check(ColorschemeData{Dark: qualityGroups(12)[1:]})
+		if !slices.Equal(quality.Reasons, []string{"dark: missing [NormalBg]"}) {
+			t.Errorf("Incorrect result for Check, got reasons %v", quality.Reasons)
+		}
+	})
+
+	t.Run("should fail colorschemes without backgrounds", func(t *testing.T) {
+		quality := DefaultQualityPolicy.Check(ColorschemeData{})
+		if quality.Passed() || quality.Score != 0 {
+			t.Errorf("Incorrect result for Check, got %+v, want a failure", quality)
+		}
+	})
+}
+
+func TestIsQualityEligible(t *testing.T) {
+	if IsQualityEligible([]Colorscheme{{Hidden: true}, {Hidden: true}}) {
+		t.Error("Incorrect result for IsQualityEligible, got true with only hidden colorschemes")
+	}
+	if !IsQualityEligible([]Colorscheme{{Hidden: true}, {}}) {
+		t.Error("Incorrect result for IsQualityEligible, got false with a visible colorscheme")
+	}
+}
@@ -0,0 +1,75 @@
+package repository
+
+import "time"
+
+// FailureReason classifies why the generate of a repository failed
+type FailureReason string
+
+const (
+	// TimeoutFailure is a clone or an extraction that ran out of time
+	TimeoutFailure FailureReason = "timeout"
+
+	// CloneFailure is a repository that couldn't be cloned
+	CloneFailure FailureReason = "clone_error"
+
+	// LuaFailure is an extraction that failed while running the colorscheme code
+	LuaFailure FailureReason = "lua_error"
+
+	// EmptyDataFailure is an extraction that didn't output any data
+	EmptyDataFailure FailureReason = "empty_data"
+
+	// UnknownFailure is any other failure
+	UnknownFailure FailureReason = "unknown"
+)
+
+// RetryPolicy decides when a repository failing to generate is retried, and
+// when it's quarantined
+type RetryPolicy struct {
+	// BaseDelay is the delay after the first failure, doubled after each
+	// consecutive failure
+	BaseDelay time.Duration
+	// MaxDelay caps the delay between retries
+	MaxDelay time.Duration
+	// QuarantineAfter is the number of consecutive failures quarantining a
+	// repository until it's pushed again, never when 0
+	QuarantineAfter int
+}
+
+// DefaultRetryPolicy retries a day after a failure, then 2, 4, 8 days later,
+// and quarantines the repository after 5 consecutive failures
+var DefaultRetryPolicy = RetryPolicy{
+	BaseDelay:       24 * time.Hour,
+	MaxDelay:        30 * 24 * time.Hour,
+	QuarantineAfter: 5,
+}
+
+// RetryDelay returns the delay before retrying after failureCount
+// consecutive failures
+func (policy RetryPolicy) RetryDelay(failureCount int) time.Duration {
+	if failureCount < 1 {
+		return 0
+	}
+
+	delay := policy.BaseDelay
+	for range failureCount - 1 {
+		if delay >= policy.MaxDelay {
+			break
+		}
+		delay *= 2
+	}
+	return min(delay, policy.MaxDelay)
+}
+
+// ShouldQuarantine returns true if the repository should stop being retried
+// after failureCount consecutive failures
+func (policy RetryPolicy) ShouldQuarantine(failureCount int) bool {
+	return policy.QuarantineAfter > 0 && failureCount >= policy.QuarantineAfter
+}
+
+// GenerateFailure represents the consecutive generate failures of a repository
+type GenerateFailure struct {
+	Count       int           `json:"count"`
+	Reason      FailureReason `json:"reason"`
+	RetryAt     time.Time     `json:"retryAt"`
+	Quarantined bool          `json:"quarantined"`
+}
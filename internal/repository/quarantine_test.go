@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: time.Hour, MaxDelay: 5 * time.Hour, QuarantineAfter: 3}
+
+	for failureCount, expected := range map[int]time.Duration{
+		0:  0,
+		1:  time.Hour,
+		2:  2 * time.Hour,
+		3:  4 * time.Hour,
+		4:  5 * time.Hour,
+		60: 5 * time.Hour,
+	} {
+		if delay := policy.RetryDelay(failureCount); delay != expected {
+			t.Errorf("RetryDelay(%d) = %s, want %s", failureCount, delay, expected)
+		}
+	}
+
+	if policy.ShouldQuarantine(2) || !policy.ShouldQuarantine(3) {
+		t.Error("ShouldQuarantine should start at 3 failures")
+	}
+	if (RetryPolicy{}).ShouldQuarantine(100) {
+		t.Error("ShouldQuarantine should be disabled without QuarantineAfter")
+	}
+}
@@ -0,0 +1,70 @@
+package repository
+
+import "strings"
+
+// Recipe configures the extraction of the colorschemes of a repository, for
+// colorschemes whose colors depend on variables or a setup() call
+type Recipe struct {
+	// Vim is Vim script run before loading the colorschemes
+	Vim string `json:"vim,omitempty"`
+	// Lua is Lua code run before loading the colorschemes, by nvim only
+	Lua string `json:"lua,omitempty"`
+	// Variants are extracted once each, after the main extraction
+	Variants []RecipeVariant `json:"variants,omitempty"`
+}
+
+// RecipeVariant is a configuration of a colorscheme extracted as its own
+// colorscheme, e.g. the "storm" style of tokyonight
+type RecipeVariant struct {
+	Name string `json:"name"`
+	// Colorscheme is the colorscheme of the repository the variant configures,
+	// the others being left out of its extraction
+	Colorscheme string `json:"colorscheme"`
+	Vim         string `json:"vim,omitempty"`
+	Lua         string `json:"lua,omitempty"`
+}
+
+// HasCode returns true if the variant has code the runner runs, the vim
+// runner ignoring the Lua code
+func (variant RecipeVariant) HasCode(runner Runner) bool {
+	if strings.TrimSpace(variant.Vim) != "" {
+		return true
+	}
+	return runner != VimRunner && strings.TrimSpace(variant.Lua) != ""
+}
+
+// Env returns the environment running the code of the recipe, followed by
+// the code of the variant when not nil
+func (recipe Recipe) Env(variant *RecipeVariant) []string {
+	vim := []string{recipe.Vim}
+	lua := []string{recipe.Lua}
+	if variant != nil {
+		vim = append(vim, variant.Vim)
+		lua = append(lua, variant.Lua)
+	}
+
+	var env []string
+	if code := joinCode(vim); code != "" {
+		env = append(env, "RECIPE_VIM="+code)
+	}
+	if code := joinCode(lua); code != "" {
+		env = append(env, "RECIPE_LUA="+code)
+	}
+	return env
+}
+
+// VariantName returns the name of a colorscheme extracted with a variant,
+// e.g. "tokyonight-storm"
+func VariantName(colorscheme string, variant string) string {
+	return colorscheme + "-" + variant
+}
+
+func joinCode(snippets []string) string {
+	var code []string
+	for _, snippet := range snippets {
+		if snippet = strings.TrimSpace(snippet); snippet != "" {
+			code = append(code, snippet)
+		}
+	}
+	return strings.Join(code, "\n")
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRecipeEnv(t *testing.T) {
+	recipe := Recipe{
+		Vim: "let g:gruvbox_italic = 1",
+		Variants: []RecipeVariant{
+			{Name: "hard", Vim: "let g:gruvbox_contrast_dark = 'hard'", Lua: "vim.g.loaded = true"},
+		},
+	}
+
+	if env := recipe.Env(nil); !reflect.DeepEqual(env, []string{"RECIPE_VIM=let g:gruvbox_italic = 1"}) {
+		t.Errorf("Env(nil) = %q", env)
+	}
+
+	expected := []string{
+		"RECIPE_VIM=let g:gruvbox_italic = 1\nlet g:gruvbox_contrast_dark = 'hard'",
+		"RECIPE_LUA=vim.g.loaded = true",
+	}
+	if env := recipe.Env(&recipe.Variants[0]); !reflect.DeepEqual(env, expected) {
+		t.Errorf("Env(hard) = %q, want %q", env, expected)
+	}
+
+	if env := (Recipe{}).Env(nil); env != nil {
+		t.Errorf("Env() of an empty recipe = %q, want nil", env)
+	}
+}
+
+func TestRecipeVariantHasCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		variant  RecipeVariant
+		runner   Runner
+		expected bool
+	}{
+		{"vim code with vim", RecipeVariant{Vim: "let g:contrast = 'hard'"}, VimRunner, true},
+		{"lua code with vim", RecipeVariant{Lua: "vim.g.contrast = 'hard'"}, VimRunner, false},
+		{"lua code with nvim", RecipeVariant{Lua: "vim.g.contrast = 'hard'"}, NeovimRunner, true},
+		{"blank code with nvim", RecipeVariant{Vim: " ", Lua: "\n"}, NeovimRunner, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if hasCode := test.variant.HasCode(test.runner); hasCode != test.expected {
+				t.Fatalf("HasCode = %v, want %v", hasCode, test.expected)
+			}
+		})
+	}
+}
@@ -27,6 +27,7 @@ type Repository struct {
 	IsDisabled             bool                         `json:"isDisabled"`
 	UpdatedAt              time.Time                    `json:"updatedAt"`
 	FeaturedRank           *int                         `json:"featuredRank,omitempty"`
+	Runner                 Runner                       `json:"runner,omitempty"`
 }
 
 // Owner represents the owner of a repository
@@ -41,11 +42,36 @@ type StargazersCountHistoryItem struct {
 	StargazersCount int       `json:"stargazersCount"`
 }
 
+// DefaultLanguage is the language of the code sample Colorscheme.Data is extracted from
+const DefaultLanguage = "vim"
+
 // Colorscheme represents a colorscheme's metadata
 type Colorscheme struct {
-	Name        string            `json:"name"`
-	Data        ColorschemeData   `json:"data"`
-	Backgrounds []BackgroundValue `json:"backgrounds"`
+	ID          int64                      `json:"id,omitempty"`
+	Name        string                     `json:"name"`
+	Data        ColorschemeData            `json:"data"`
+	Languages   map[string]ColorschemeData `json:"languages,omitempty"`
+	Backgrounds []BackgroundValue          `json:"backgrounds"`
+	// BackgroundChecks validate the backgrounds the colorscheme was extracted for
+	BackgroundChecks []BackgroundCheck `json:"backgroundChecks,omitempty"`
+	Runner           Runner            `json:"runner,omitempty"`
+	Previews         []Preview         `json:"previews,omitempty"`
+	PluginSupport    []PluginSupport   `json:"pluginSupport,omitempty"`
+	CommitSHA        string            `json:"commitSHA,omitempty"`
+	GenerationID     int64             `json:"generationID,omitempty"`
+	Variant          string            `json:"variant,omitempty"`
+	Parent           string            `json:"parent,omitempty"`
+	// Quality is the score of the colorscheme data, a failing colorscheme
+	// being stored but hidden
+	Quality Quality `json:"quality"`
+	Hidden  bool    `json:"hidden,omitempty"`
+}
+
+// Preview represents a rendered preview image of a colorscheme background
+type Preview struct {
+	Background BackgroundValue `json:"background"`
+	Format     string          `json:"format"`
+	URL        string          `json:"url"`
 }
 
 // ColorschemeData represents the color values for light and dark backgrounds
@@ -67,6 +93,7 @@ type ColorschemeGroup struct {
 	Underdashed   bool   `json:"underdashed,omitempty"`
 	Strikethrough bool   `json:"strikethrough,omitempty"`
 	Reverse       bool   `json:"reverse,omitempty"`
+	Link          string `json:"link,omitempty"`
 }
 
 // BackgroundValue sets up an enum containing possible background values
@@ -80,6 +107,40 @@ const (
 	DarkBackground BackgroundValue = "dark"
 )
 
+// GroupHexCode returns the effective hex code of the group matching the
+// name, as produced by extractor.nvim (e.g. "NormalBg", "CommentFg"),
+// resolved through its links when it has no color of its own
+func GroupHexCode(groups []ColorschemeGroup, name string) (string, bool) {
+	group, ok := resolveGroup(groups, name)
+	if !ok {
+		return "", false
+	}
+	return group.HexCode, true
+}
+
+// Groups returns the groups extracted for the background
+func (data ColorschemeData) Groups(background BackgroundValue) []ColorschemeGroup {
+	switch background {
+	case LightBackground:
+		return data.Light
+	case DarkBackground:
+		return data.Dark
+	}
+	return nil
+}
+
+// Backgrounds returns the backgrounds the data was extracted for
+func (data ColorschemeData) Backgrounds() []BackgroundValue {
+	var backgrounds []BackgroundValue
+	if len(data.Light) > 0 {
+		backgrounds = append(backgrounds, LightBackground)
+	}
+	if len(data.Dark) > 0 {
+		backgrounds = append(backgrounds, DarkBackground)
+	}
+	return backgrounds
+}
+
 // UniquifyRepositories makes sure no repository is listed twice in a list
 func UniquifyRepositories(repositories []*gogithub.Repository) []*gogithub.Repository {
 	keys := make(map[int64]bool)
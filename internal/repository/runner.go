@@ -0,0 +1,22 @@
+package repository
+
+import "fmt"
+
+// Runner represents the editor colorschemes are extracted with
+type Runner string
+
+const (
+	// NeovimRunner extracts colors with nvim and vimcolorschemes/extractor.nvim
+	NeovimRunner Runner = "nvim"
+	// VimRunner extracts colors with vim and vim/autoload/vimcolorschemes/extract.vim
+	VimRunner Runner = "vim"
+)
+
+// ParseRunner returns the runner matching the value
+func ParseRunner(value string) (Runner, error) {
+	switch runner := Runner(value); runner {
+	case NeovimRunner, VimRunner:
+		return runner, nil
+	}
+	return "", fmt.Errorf("unknown runner %q, expected %q or %q", value, NeovimRunner, VimRunner)
+}
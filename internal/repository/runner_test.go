@@ -0,0 +1,16 @@
+package repository
+
+import "testing"
+
+func TestParseRunner(t *testing.T) {
+	for _, value := range []string{"nvim", "vim"} {
+		runner, err := ParseRunner(value)
+		if err != nil || string(runner) != value {
+			t.Errorf("ParseRunner(%q) = %q, %v", value, runner, err)
+		}
+	}
+
+	if _, err := ParseRunner("emacs"); err == nil {
+		t.Error("ParseRunner(emacs) returned no error")
+	}
+}
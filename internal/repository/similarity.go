@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"math"
+	"sort"
+
+	"github.com/vimcolorschemes/worker/internal/color"
+)
+
+// SimilarColorschemeCount is the number of neighbors kept per colorscheme background
+const SimilarColorschemeCount = 10
+
+// SimilarityGroupNames lists the groups making up a colorscheme's feature
+// vector. Groups other than NormalBg and NormalFg fall back to NormalFg
+// when a colorscheme does not define them.
+var SimilarityGroupNames = []string{
+	"NormalBg",
+	"NormalFg",
+	"CommentFg",
+	"StringFg",
+	"KeywordFg",
+	"FunctionFg",
+}
+
+// SimilarityCandidate represents a colorscheme background to compare
+type SimilarityCandidate struct {
+	ColorschemeID int64
+	RepositoryID  int64
+	Background    BackgroundValue
+	Features      []color.Lab
+}
+
+// SimilarColorscheme represents a nearest neighbor of a colorscheme background
+type SimilarColorscheme struct {
+	ColorschemeID        int64
+	Background           BackgroundValue
+	SimilarColorschemeID int64
+	Rank                 int
+	Distance             float64
+}
+
+// ColorschemeFeatures returns the perceptual colors used to compare
+// colorschemes, or false if the groups lack a Normal background or foreground
+func ColorschemeFeatures(groups []ColorschemeGroup) ([]color.Lab, bool) {
+	normalFg, ok := parseGroupColor(groups, "NormalFg")
+	if !ok {
+		return nil, false
+	}
+	if _, ok := parseGroupColor(groups, "NormalBg"); !ok {
+		return nil, false
+	}
+
+	features := make([]color.Lab, 0, len(SimilarityGroupNames))
+	for _, name := range SimilarityGroupNames {
+		value, ok := parseGroupColor(groups, name)
+		if !ok {
+			value = normalFg
+		}
+		features = append(features, value.Lab())
+	}
+
+	return features, true
+}
+
+// GetSimilarityCandidates returns a candidate for each background of the
+// visible colorschemes that has enough data to be compared
+func GetSimilarityCandidates(repositoryID int64, colorschemes []Colorscheme) []SimilarityCandidate {
+	var candidates []SimilarityCandidate
+	for _, colorscheme := range colorschemes {
+		// Hidden colorschemes aren't suggested
+		if colorscheme.Hidden {
+			continue
+		}
+		for _, background := range []BackgroundValue{LightBackground, DarkBackground} {
+			features, ok := ColorschemeFeatures(colorscheme.Data.Groups(background))
+			if !ok {
+				continue
+			}
+			candidates = append(candidates, SimilarityCandidate{
+				ColorschemeID: colorscheme.ID,
+				RepositoryID:  repositoryID,
+				Background:    background,
+				Features:      features,
+			})
+		}
+	}
+	return candidates
+}
+
+// ComputeSimilarColorschemes returns up to count nearest neighbors for each
+// candidate, among candidates of the same background from other repositories
+func ComputeSimilarColorschemes(candidates []SimilarityCandidate, count int) []SimilarColorscheme {
+	var similar []SimilarColorscheme
+
+	for _, candidate := range candidates {
+		neighbors := make([]SimilarColorscheme, 0, count+1)
+		for _, other := range candidates {
+			if other.Background != candidate.Background || other.RepositoryID == candidate.RepositoryID {
+				continue
+			}
+			neighbors = insertNeighbor(neighbors, SimilarColorscheme{
+				ColorschemeID:        candidate.ColorschemeID,
+				Background:           candidate.Background,
+				SimilarColorschemeID: other.ColorschemeID,
+				Distance:             featureDistance(candidate.Features, other.Features),
+			}, count)
+		}
+
+		for index := range neighbors {
+			neighbors[index].Rank = index + 1
+		}
+
+		similar = append(similar, neighbors...)
+	}
+
+	return similar
+}
+
+// Inserts neighbor in the sorted neighbors, keeping only the count closest
+func insertNeighbor(neighbors []SimilarColorscheme, neighbor SimilarColorscheme, count int) []SimilarColorscheme {
+	index := sort.Search(len(neighbors), func(i int) bool {
+		return closerNeighbor(neighbor, neighbors[i])
+	})
+	if index >= count {
+		return neighbors
+	}
+
+	neighbors = append(neighbors, SimilarColorscheme{})
+	copy(neighbors[index+1:], neighbors[index:])
+	neighbors[index] = neighbor
+	if len(neighbors) > count {
+		neighbors = neighbors[:count]
+	}
+	return neighbors
+}
+
+func closerNeighbor(a SimilarColorscheme, b SimilarColorscheme) bool {
+	if a.Distance == b.Distance {
+		return a.SimilarColorschemeID < b.SimilarColorschemeID
+	}
+	return a.Distance < b.Distance
+}
+
+func featureDistance(a []color.Lab, b []color.Lab) float64 {
+	sum := 0.0
+	for index := range a {
+		sum += math.Pow(color.DeltaE(a[index], b[index]), 2)
+	}
+	return math.Sqrt(sum / float64(len(a)))
+}
+
+func parseGroupColor(groups []ColorschemeGroup, name string) (color.RGB, bool) {
+	hexCode, ok := GroupHexCode(groups, name)
+	if !ok {
+		return color.RGB{}, false
+	}
+
+	value, err := color.ParseHex(hexCode)
+	if err != nil {
+		return color.RGB{}, false
+	}
+
+	return value, true
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"testing"
+)
+
+func TestColorschemeFeatures(t *testing.T) {
+	t.Run("should require Normal background and foreground", func(t *testing.T) {
+		_, ok := ColorschemeFeatures([]ColorschemeGroup{{Name: "NormalBg", HexCode: "#000000"}})
+		if ok {
+			t.Error("Incorrect result for ColorschemeFeatures, got ok without NormalFg")
+		}
+	})
+
+	t.Run("should fall back to Normal foreground", func(t *testing.T) {
+		features, ok := ColorschemeFeatures([]ColorschemeGroup{
+			{Name: "NormalBg", HexCode: "#000000"},
+			{Name: "NormalFg", HexCode: "#ffffff"},
+		})
+		if !ok {
+			t.Fatal("Incorrect result for ColorschemeFeatures, got not ok")
+		}
+		if len(features) != len(SimilarityGroupNames) {
+			t.Fatalf("Incorrect result for ColorschemeFeatures, got length: %d, want length: %d", len(features), len(SimilarityGroupNames))
+		}
+		if features[2] != features[1] {
+			t.Errorf("Incorrect result for ColorschemeFeatures, got CommentFg: %+v, want NormalFg: %+v", features[2], features[1])
+		}
+	})
+}
+
+func TestGetSimilarityCandidates(t *testing.T) {
+	data := ColorschemeData{Dark: []ColorschemeGroup{
+		{Name: "NormalBg", HexCode: "#000000"},
+		{Name: "NormalFg", HexCode: "#ffffff"},
+	}}
+	candidates := GetSimilarityCandidates(1, []Colorscheme{
+		{ID: 1, Data: data},
+		{ID: 2, Data: data, Hidden: true},
+	})
+	if len(candidates) != 1 || candidates[0].ColorschemeID != 1 || candidates[0].Background != DarkBackground {
+		t.Errorf("Incorrect result for GetSimilarityCandidates, got %+v, want the dark background of colorscheme 1", candidates)
+	}
+}
+
+func TestComputeSimilarColorschemes(t *testing.T) {
+	newCandidate := func(colorschemeID int64, repositoryID int64, background BackgroundValue, bg string, fg string) SimilarityCandidate {
+		features, _ := ColorschemeFeatures([]ColorschemeGroup{
+			{Name: "NormalBg", HexCode: bg},
+			{Name: "NormalFg", HexCode: fg},
+		})
+		return SimilarityCandidate{
+			ColorschemeID: colorschemeID,
+			RepositoryID:  repositoryID,
+			Background:    background,
+			Features:      features,
+		}
+	}
+
+	candidates := []SimilarityCandidate{
+		newCandidate(1, 1, DarkBackground, "#000000", "#ffffff"),
+		newCandidate(2, 1, DarkBackground, "#010101", "#ffffff"),
+		newCandidate(3, 2, DarkBackground, "#101010", "#eeeeee"),
+		newCandidate(4, 3, DarkBackground, "#303030", "#cccccc"),
+		newCandidate(5, 4, LightBackground, "#000000", "#ffffff"),
+	}
+
+	similar := ComputeSimilarColorschemes(candidates, 1)
+
+	neighbors := map[int64]int64{}
+	for _, item := range similar {
+		if item.Rank != 1 {
+			t.Errorf("Incorrect result for ComputeSimilarColorschemes, got rank: %d, want rank: 1", item.Rank)
+		}
+		neighbors[item.ColorschemeID] = item.SimilarColorschemeID
+	}
+
+	if neighbors[1] != 3 {
+		t.Errorf("Incorrect result for ComputeSimilarColorschemes, got neighbor: %d, want neighbor: 3", neighbors[1])
+	}
+	if neighbors[4] != 3 {
+		t.Errorf("Incorrect result for ComputeSimilarColorschemes, got neighbor: %d, want neighbor: 3", neighbors[4])
+	}
+	if _, ok := neighbors[5]; ok {
+		t.Error("Incorrect result for ComputeSimilarColorschemes, got neighbor for the only light background")
+	}
+}
+
+func TestInsertNeighbor(t *testing.T) {
+	var neighbors []SimilarColorscheme
+	for _, neighbor := range []SimilarColorscheme{
+		{SimilarColorschemeID: 1, Distance: 3},
+		{SimilarColorschemeID: 2, Distance: 1},
+		{SimilarColorschemeID: 3, Distance: 4},
+		{SimilarColorschemeID: 4, Distance: 1},
+		{SimilarColorschemeID: 5, Distance: 2},
+	} {
+		neighbors = insertNeighbor(neighbors, neighbor, 3)
+	}
+
+	var ids []int64
+	for _, neighbor := range neighbors {
+		ids = append(ids, neighbor.SimilarColorschemeID)
+	}
+	if len(ids) != 3 || ids[0] != 2 || ids[1] != 4 || ids[2] != 5 {
+		t.Fatalf("Incorrect result for insertNeighbor, got: %v, want: [2 4 5]", ids)
+	}
+}
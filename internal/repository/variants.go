@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"sort"
+	"strings"
+)
+
+// Separators between the name of a colorscheme and the name of its variant,
+// e.g. "tokyonight-storm" or "kanagawa_dragon"
+var variantSeparators = []string{"-", "_"}
+
+// AssignVariants sets the parent of the colorschemes shipped as variants of
+// another colorscheme of the repository in their own colors/ file, e.g.
+// "tokyonight-storm" is the "storm" variant of "tokyonight". Variants are
+// attached to their root colorscheme, and the recipe variants, which already
+// have a parent, are left as is.
+func AssignVariants(colorschemes []Colorscheme) []Colorscheme {
+	names := make([]string, 0, len(colorschemes))
+	for _, colorscheme := range colorschemes {
+		if colorscheme.Parent == "" {
+			names = append(names, colorscheme.Name)
+		}
+	}
+	// The shortest parent is found first, making it the root
+	sort.Slice(names, func(i int, j int) bool {
+		if len(names[i]) != len(names[j]) {
+			return len(names[i]) < len(names[j])
+		}
+		return names[i] < names[j]
+	})
+
+	for index, colorscheme := range colorschemes {
+		if colorscheme.Parent != "" {
+			continue
+		}
+		for _, name := range names {
+			if variant, ok := variantOf(colorscheme.Name, name); ok {
+				colorschemes[index].Parent = name
+				colorschemes[index].Variant = variant
+				break
+			}
+		}
+	}
+	return colorschemes
+}
+
+// Returns the name of the variant if name is a variant of parent
+func variantOf(name string, parent string) (string, bool) {
+	for _, separator := range variantSeparators {
+		if variant, ok := strings.CutPrefix(name, parent+separator); ok && variant != "" {
+			return variant, true
+		}
+	}
+	return "", false
+}
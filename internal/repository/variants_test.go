@@ -0,0 +1,28 @@
+package repository
+
+import "testing"
+
+func TestAssignVariants(t *testing.T) {
+	colorschemes := AssignVariants([]Colorscheme{
+		{Name: "tokyonight-storm"},
+		{Name: "tokyonight"},
+		{Name: "tokyonight-night"},
+		{Name: "tokyonight-night-hard", Parent: "tokyonight-night", Variant: "hard"},
+		{Name: "kanagawa_dragon"},
+		{Name: "gruvbox-material"},
+	})
+
+	expected := map[string][2]string{
+		"tokyonight-storm":      {"tokyonight", "storm"},
+		"tokyonight":            {"", ""},
+		"tokyonight-night":      {"tokyonight", "night"},
+		"tokyonight-night-hard": {"tokyonight-night", "hard"},
+		"kanagawa_dragon":       {"", ""},
+		"gruvbox-material":      {"", ""},
+	}
+	for _, colorscheme := range colorschemes {
+		if got := [2]string{colorscheme.Parent, colorscheme.Variant}; got != expected[colorscheme.Name] {
+			t.Errorf("%s parent and variant = %q, want %q", colorscheme.Name, got, expected[colorscheme.Name])
+		}
+	}
+}
@@ -0,0 +1,14 @@
+package repository
+
+// NvimVersionResult represents the extraction of a repository with one of
+// the nvim binaries of the version matrix, compared to the main extraction
+type NvimVersionResult struct {
+	Version          string      `json:"version"`
+	BinaryPath       string      `json:"binaryPath"`
+	Success          bool        `json:"success"`
+	Error            string      `json:"error,omitempty"`
+	ErrorCode        ErrorCode   `json:"errorCode,omitempty"`
+	ErrorOutput      ErrorOutput `json:"errorOutput,omitempty"`
+	ColorschemeCount int         `json:"colorschemeCount"`
+	Diff             DataDiff    `json:"diff"`
+}
@@ -0,0 +1,217 @@
+// Package sandbox runs untrusted colorscheme code with a scrubbed
+// environment, a throwaway home, resource limits and, when available,
+// Linux namespaces.
+package sandbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Environment variables kept from the worker environment, everything else
+// (e.g. DATABASE_AUTH_TOKEN, GITHUB_TOKEN) is dropped
+var allowedEnv = []string{"PATH", "TERM", "LANG", "LC_ALL", "LC_CTYPE", "TZ", "VIMRUNTIME"}
+
+// Config of the sandbox, a zero limit is not enforced
+type Config struct {
+	CPUTime       time.Duration
+	MemoryBytes   int64
+	FileSizeBytes int64
+	// Namespaces runs the command without network and in its own PID
+	// namespace, and with a read-only root file system when bwrap is installed
+	Namespaces bool
+	// HiddenPaths are replaced by empty directories with a read-only root file
+	// system, e.g. the worker directory and its .env file
+	HiddenPaths []string
+	// ReadOnlyPaths stay readable under the hidden paths
+	ReadOnlyPaths []string
+	// WritablePaths stay writable with a read-only root file system
+	WritablePaths []string
+}
+
+var errNoNamespaces = errors.New("bwrap and unshare are unavailable, set SANDBOX_NAMESPACES=false to run without namespaces")
+
+// Sandbox wraps commands in the configured isolation
+type Sandbox struct {
+	config  Config
+	wrapper []string
+}
+
+// New returns a sandbox for the config. It fails when namespaces are enabled
+// but neither bwrap nor unshare can be used.
+func New(config Config) (Sandbox, error) {
+	sandbox := Sandbox{config: config}
+	if !config.Namespaces {
+		return sandbox, nil
+	}
+
+	if _, err := exec.LookPath("bwrap"); err == nil {
+		sandbox.wrapper = bwrapArgs(config)
+		return sandbox, nil
+	}
+
+	unshare := []string{"unshare", "--net", "--pid", "--fork", "--mount-proc", "--map-root-user", "--"}
+	if err := exec.Command(unshare[0], append(unshare[1:], "true")...).Run(); err == nil {
+		log.Print("WARNING: bwrap is not installed, running without a read-only root file system, the worker directory and its .env file stay readable")
+		sandbox.wrapper = unshare
+		return sandbox, nil
+	}
+
+	return Sandbox{}, errNoNamespaces
+}
+
+// Mounts are applied in order, so the read-only and writable paths are
+// mounted back inside the hidden ones
+func bwrapArgs(config Config) []string {
+	args := []string{
+		"bwrap",
+		"--ro-bind", "/", "/",
+		"--dev", "/dev",
+		"--proc", "/proc",
+		"--tmpfs", "/tmp",
+		"--unshare-all",
+		"--die-with-parent",
+	}
+	for _, path := range config.HiddenPaths {
+		args = append(args, "--tmpfs", path)
+	}
+	for _, path := range config.ReadOnlyPaths {
+		args = append(args, "--ro-bind", path, path)
+	}
+	for _, path := range config.WritablePaths {
+		args = append(args, "--bind", path, path)
+	}
+	return append(args, "--")
+}
+
+// Command returns the command running name in the sandbox. home is created
+// if needed and used as HOME and the XDG base directories, env is added to
+// the scrubbed environment.
+func (s Sandbox) Command(ctx context.Context, home string, env []string, name string, args ...string) (*exec.Cmd, error) {
+	commandEnv, err := Env(os.Environ(), home)
+	if err != nil {
+		return nil, err
+	}
+
+	command := append([]string{}, s.wrapper...)
+	if limits := s.limitArgs(); len(limits) > 0 {
+		command = append(command, "prlimit")
+		command = append(command, limits...)
+		command = append(command, "--")
+	}
+	command = append(command, name)
+	command = append(command, args...)
+
+	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+	cmd.Env = append(commandEnv, env...)
+	return cmd, nil
+}
+
+// The soft CPU limit sends SIGXCPU, the hard one a second later SIGKILL
+func (s Sandbox) limitArgs() []string {
+	var args []string
+	if seconds := int64(s.config.CPUTime / time.Second); seconds > 0 {
+		args = append(args, fmt.Sprintf("--cpu=%d:%d", seconds, seconds+1))
+	}
+	if s.config.MemoryBytes > 0 {
+		args = append(args, fmt.Sprintf("--as=%d", s.config.MemoryBytes))
+	}
+	if s.config.FileSizeBytes > 0 {
+		args = append(args, fmt.Sprintf("--fsize=%d", s.config.FileSizeBytes))
+	}
+	return args
+}
+
+// Env returns the allowed variables of environ, with HOME and the XDG base
+// directories pointing to directories created in home
+func Env(environ []string, home string) ([]string, error) {
+	var env []string
+	for _, variable := range environ {
+		key, _, _ := strings.Cut(variable, "=")
+		for _, allowed := range allowedEnv {
+			if key == allowed {
+				env = append(env, variable)
+				break
+			}
+		}
+	}
+
+	directories := []struct {
+		key  string
+		path string
+	}{
+		{"HOME", home},
+		{"XDG_CONFIG_HOME", filepath.Join(home, ".config")},
+		{"XDG_DATA_HOME", filepath.Join(home, ".local", "share")},
+		{"XDG_STATE_HOME", filepath.Join(home, ".local", "state")},
+		{"XDG_CACHE_HOME", filepath.Join(home, ".cache")},
+		{"TMPDIR", filepath.Join(home, "tmp")},
+	}
+	for _, directory := range directories {
+		if err := os.MkdirAll(directory.path, 0o700); err != nil {
+			return nil, err
+		}
+		env = append(env, directory.key+"="+directory.path)
+	}
+
+	return env, nil
+}
+
+// ViolationError is returned when the sandboxed command is killed for going
+// over a resource limit
+type ViolationError struct {
+	Limit  string
+	Signal syscall.Signal
+}
+
+func (e *ViolationError) Error() string {
+	return fmt.Sprintf("sandbox violation: %s limit exceeded (%s)", e.Limit, e.Signal)
+}
+
+// Violation returns a ViolationError when err is the exit of a command killed
+// by a resource limit, or err otherwise. Signals are also read from the exit
+// code, as bwrap exits with 128 + the signal of its child.
+func (s Sandbox) Violation(err error) error {
+	var exitError *exec.ExitError
+	if !errors.As(err, &exitError) {
+		return err
+	}
+
+	status, ok := exitError.Sys().(syscall.WaitStatus)
+	if !ok {
+		return err
+	}
+
+	var signal syscall.Signal
+	switch {
+	case status.Signaled():
+		signal = status.Signal()
+	case status.ExitStatus() > 128:
+		signal = syscall.Signal(status.ExitStatus() - 128)
+	default:
+		return err
+	}
+
+	// SIGKILL is also sent by the OOM killer and on timeouts, it's the hard
+	// CPU limit only when the command used up its CPU time
+	cpuTime := exitError.UserTime() + exitError.SystemTime()
+	cpuKilled := signal == syscall.SIGKILL && s.config.CPUTime > 0 && cpuTime >= s.config.CPUTime
+
+	switch {
+	case signal == syscall.SIGXCPU, cpuKilled:
+		return &ViolationError{Limit: "CPU time", Signal: signal}
+	case signal == syscall.SIGXFSZ:
+		return &ViolationError{Limit: "file size", Signal: signal}
+	case (signal == syscall.SIGABRT || signal == syscall.SIGSEGV) && s.config.MemoryBytes > 0:
+		return &ViolationError{Limit: "memory", Signal: signal}
+	}
+	return err
+}
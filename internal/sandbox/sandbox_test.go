@@ -0,0 +1,145 @@
+package sandbox
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestEnv(t *testing.T) {
+	home := t.TempDir()
+	env, err := Env([]string{
+		"PATH=/usr/bin",
+		"TERM=xterm-256color",
+		"DATABASE_AUTH_TOKEN=secret",
+		"GITHUB_TOKEN=secret",
+		"HOME=/root",
+		"XDG_CONFIG_HOME=/root/.config",
+	}, home)
+	if err != nil {
+		t.Fatalf("Env returned error: %v", err)
+	}
+
+	for _, variable := range env {
+		if strings.Contains(variable, "secret") || strings.HasPrefix(variable, "HOME=/root") || strings.HasPrefix(variable, "XDG_CONFIG_HOME=/root") {
+			t.Fatalf("env = %v, want %s dropped", env, variable)
+		}
+	}
+	for _, expected := range []string{"PATH=/usr/bin", "TERM=xterm-256color", "HOME=" + home, "XDG_CONFIG_HOME=" + filepath.Join(home, ".config")} {
+		if !slices.Contains(env, expected) {
+			t.Fatalf("env = %v, want %s", env, expected)
+		}
+	}
+}
+
+func TestCommand(t *testing.T) {
+	t.Run("runs the command directly without limits", func(t *testing.T) {
+		cmd, err := newSandbox(t, Config{}).Command(context.Background(), t.TempDir(), []string{"COLOR_DATA_PATH=/tmp/data.json"}, "nvim", "--headless")
+		if err != nil {
+			t.Fatalf("Command returned error: %v", err)
+		}
+		if !reflect.DeepEqual(cmd.Args, []string{"nvim", "--headless"}) {
+			t.Fatalf("Args = %v, want nvim --headless", cmd.Args)
+		}
+		if !slices.Contains(cmd.Env, "COLOR_DATA_PATH=/tmp/data.json") {
+			t.Fatalf("Env = %v, want COLOR_DATA_PATH", cmd.Env)
+		}
+	})
+
+	t.Run("sets the resource limits", func(t *testing.T) {
+		sandbox := newSandbox(t, Config{CPUTime: 30 * time.Second, MemoryBytes: 1 << 30, FileSizeBytes: 1 << 20})
+		cmd, err := sandbox.Command(context.Background(), t.TempDir(), nil, "nvim")
+		if err != nil {
+			t.Fatalf("Command returned error: %v", err)
+		}
+		expected := []string{"prlimit", "--cpu=30:31", "--as=1073741824", "--fsize=1048576", "--", "nvim"}
+		if !reflect.DeepEqual(cmd.Args, expected) {
+			t.Fatalf("Args = %v, want %v", cmd.Args, expected)
+		}
+	})
+}
+
+func TestViolation(t *testing.T) {
+	if _, err := exec.LookPath("prlimit"); err != nil {
+		t.Skip("prlimit is not installed")
+	}
+
+	sandbox := newSandbox(t, Config{FileSizeBytes: 1024})
+	home := t.TempDir()
+	cmd, err := sandbox.Command(context.Background(), home, nil, "sh", "-c", "head -c 4096 /dev/zero > "+filepath.Join(home, "big"))
+	if err != nil {
+		t.Fatalf("Command returned error: %v", err)
+	}
+
+	err = sandbox.Violation(cmd.Run())
+	var violation *ViolationError
+	if !errors.As(err, &violation) {
+		t.Fatalf("Violation = %v, want a ViolationError", err)
+	}
+	if violation.Limit != "file size" {
+		t.Fatalf("Limit = %q, want file size", violation.Limit)
+	}
+
+	if err := sandbox.Violation(exec.Command("false").Run()); errors.As(err, &violation) {
+		t.Fatalf("Violation = %v, want the exit error", err)
+	}
+}
+
+func TestViolationSIGKILL(t *testing.T) {
+	sandbox := newSandbox(t, Config{CPUTime: 30 * time.Second})
+
+	// Killed before using up its CPU time, like a timeout or the OOM killer
+	cmd := exec.Command("sh", "-c", "kill -KILL $$")
+	err := sandbox.Violation(cmd.Run())
+	var violation *ViolationError
+	if errors.As(err, &violation) {
+		t.Fatalf("Violation = %v, want the exit error", err)
+	}
+}
+
+func TestViolationHardCPULimit(t *testing.T) {
+	if _, err := exec.LookPath("prlimit"); err != nil {
+		t.Skip("prlimit is not installed")
+	}
+
+	sandbox := newSandbox(t, Config{CPUTime: time.Second})
+	// Ignoring SIGXCPU gets the command killed by the hard limit
+	cmd, err := sandbox.Command(context.Background(), t.TempDir(), nil, "sh", "-c", "trap '' XCPU; while :; do :; done")
+	if err != nil {
+		t.Fatalf("Command returned error: %v", err)
+	}
+
+	err = sandbox.Violation(cmd.Run())
+	var violation *ViolationError
+	if !errors.As(err, &violation) || violation.Limit != "CPU time" || violation.Signal != syscall.SIGKILL {
+		t.Fatalf("Violation = %v, want a CPU time violation from SIGKILL", err)
+	}
+}
+
+func TestBwrapArgs(t *testing.T) {
+	args := bwrapArgs(Config{
+		HiddenPaths:   []string{"/app"},
+		ReadOnlyPaths: []string{"/app/vim"},
+		WritablePaths: []string{"/app/.tmp"},
+	})
+	expected := []string{"--tmpfs", "/app", "--ro-bind", "/app/vim", "/app/vim", "--bind", "/app/.tmp", "/app/.tmp", "--"}
+	if !reflect.DeepEqual(args[len(args)-len(expected):], expected) {
+		t.Fatalf("args = %v, want them to end with %v", args, expected)
+	}
+}
+
+func newSandbox(t *testing.T, config Config) Sandbox {
+	t.Helper()
+	sandbox, err := New(config)
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	return sandbox
+}
@@ -0,0 +1,244 @@
+// Package scan statically analyzes a cloned colorscheme repository, to reject
+// or explain a repository before running its code.
+package scan
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Framework is a library colorschemes are built with
+type Framework string
+
+const (
+	// LushFramework is rktjmp/lush.nvim
+	LushFramework Framework = "lush"
+
+	// MiniBase16Framework is the mini.base16 module of echasnovski/mini.nvim
+	MiniBase16Framework Framework = "mini.base16"
+
+	// ColorbuddyFramework is tjdevries/colorbuddy.nvim
+	ColorbuddyFramework Framework = "colorbuddy"
+)
+
+// Modules required by each framework
+var frameworkModules = map[string]Framework{
+	"lush":        LushFramework,
+	"mini.base16": MiniBase16Framework,
+	"colorbuddy":  ColorbuddyFramework,
+}
+
+// Bytes read of each file, the patterns being found near the top of real
+// colorscheme files
+const maxFileSize = 1 << 20
+
+// Lua modules provided by nvim and LuaJIT, which aren't dependencies
+var builtinModules = map[string]bool{
+	"vim": true, "bit": true, "ffi": true, "jit": true, "string": true, "table": true,
+	"math": true, "os": true, "io": true, "coroutine": true, "debug": true, "package": true,
+	"utf8": true,
+}
+
+var (
+	requirePattern    = regexp.MustCompile(`\brequire\s*\(?\s*["']([A-Za-z0-9_.\-]+)["']`)
+	setPattern        = regexp.MustCompile(`(?m)^\s*(?:silent!?\s+)?(?:exe(?:cute)?\s+["'])?set?\s+(?:background|bg)\s*=\s*(dark|light)`)
+	luaSetPattern     = regexp.MustCompile(`vim\.(?:o|opt|go)\.background\s*=\s*["'](dark|light)["']`)
+	backgroundPattern = regexp.MustCompile(`&(?:background|bg)\b|vim\.(?:o|opt|go)\.background\b(?:\s*[=~]=|:get\(\))|nvim_get_option(?:_value)?\(\s*["']background["']`)
+)
+
+// Result represents what a repository's code uses, as read from its files
+type Result struct {
+	// Files are the colorscheme files, e.g. "colors/gruvbox.vim"
+	Files []string `json:"files"`
+	// Frameworks are the libraries the colorschemes are built with
+	Frameworks []Framework `json:"frameworks,omitempty"`
+	// Dependencies are the top level Lua modules required and not provided
+	// by the repository, e.g. "lush" or "plenary"
+	Dependencies []string `json:"dependencies,omitempty"`
+	// Backgrounds are the backgrounds the code sets
+	Backgrounds []string `json:"backgrounds,omitempty"`
+	// ReadsBackground is true if the code adapts to the background option
+	ReadsBackground bool `json:"readsBackground"`
+}
+
+// Scan analyzes the colors/ files of the repository, and the Lua modules
+// of its lua/ directory they load
+func Scan(path string) (Result, error) {
+	var result Result
+
+	files, err := filepath.Glob(filepath.Join(path, "colors", "*"))
+	if err != nil {
+		return Result{}, err
+	}
+	for _, file := range files {
+		if extension := filepath.Ext(file); extension == ".vim" || extension == ".lua" {
+			result.Files = append(result.Files, filepath.ToSlash(filepath.Join("colors", filepath.Base(file))))
+		}
+	}
+	slices.Sort(result.Files)
+
+	provided, luaFiles, err := luaModules(filepath.Join(path, "lua"))
+	if err != nil {
+		return Result{}, err
+	}
+
+	root, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return Result{}, err
+	}
+
+	modules := map[string]bool{}
+	backgrounds := map[string]bool{}
+	for _, file := range append(slices.Clone(result.Files), luaFiles...) {
+		content, ok, err := readFile(root, file)
+		if err != nil {
+			return Result{}, err
+		}
+		if !ok {
+			continue
+		}
+		text := string(content)
+
+		for _, match := range requirePattern.FindAllStringSubmatch(text, -1) {
+			modules[match[1]] = true
+		}
+		for _, pattern := range []*regexp.Regexp{setPattern, luaSetPattern} {
+			for _, match := range pattern.FindAllStringSubmatch(text, -1) {
+				backgrounds[match[1]] = true
+			}
+		}
+		if backgroundPattern.MatchString(text) {
+			result.ReadsBackground = true
+		}
+	}
+
+	dependencies := map[string]bool{}
+	frameworks := map[Framework]bool{}
+	for module := range modules {
+		if framework, ok := frameworkModules[module]; ok {
+			frameworks[framework] = true
+		}
+		root, _, _ := strings.Cut(module, ".")
+		if !provided[root] && !builtinModules[root] {
+			dependencies[root] = true
+		}
+	}
+
+	result.Frameworks = sortedKeys(frameworks)
+	result.Dependencies = sortedKeys(dependencies)
+	result.Backgrounds = sortedKeys(backgrounds)
+	return result, nil
+}
+
+// HasColorschemes returns true if the repository has colors/ files
+func (result Result) HasColorschemes() bool {
+	return len(result.Files) > 0
+}
+
+// String describes the scan, e.g. "2 colors/ files, uses lush, requires lush"
+func (result Result) String() string {
+	parts := []string{fmt.Sprintf("%d colors/ files", len(result.Files))}
+	if len(result.Frameworks) > 0 {
+		parts = append(parts, "uses "+joinStrings(result.Frameworks))
+	}
+	if len(result.Dependencies) > 0 {
+		parts = append(parts, "requires "+strings.Join(result.Dependencies, ", "))
+	}
+	if len(result.Backgrounds) > 0 {
+		parts = append(parts, "sets background "+strings.Join(result.Backgrounds, ", "))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Returns the first maxFileSize bytes of the file at the path relative to the
+// root of the repository, skipping the files that aren't regular files inside
+// the repository once their links are followed, like a link to /dev/zero
+func readFile(root string, relative string) ([]byte, bool, error) {
+	path, err := filepath.EvalSymlinks(filepath.Join(root, relative))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	if inside, err := filepath.Rel(root, path); err != nil || inside == ".." || strings.HasPrefix(inside, ".."+string(filepath.Separator)) {
+		return nil, false, nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, false, err
+	}
+	if !info.Mode().IsRegular() {
+		return nil, false, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, false, err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	content, err := io.ReadAll(io.LimitReader(file, maxFileSize))
+	if err != nil {
+		return nil, false, err
+	}
+	return content, true, nil
+}
+
+// Returns the top level Lua modules of the lua/ directory, and the paths of
+// its files relative to the repository
+func luaModules(path string) (map[string]bool, []string, error) {
+	modules := map[string]bool{}
+	var files []string
+
+	err := filepath.WalkDir(path, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return fs.SkipDir
+			}
+			return err
+		}
+		if entry.IsDir() || filepath.Ext(file) != ".lua" {
+			return nil
+		}
+
+		relative, err := filepath.Rel(path, file)
+		if err != nil {
+			return err
+		}
+		root, _, _ := strings.Cut(filepath.ToSlash(relative), "/")
+		modules[strings.TrimSuffix(root, ".lua")] = true
+		files = append(files, filepath.ToSlash(filepath.Join("lua", relative)))
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return modules, files, nil
+}
+
+func sortedKeys[K ~string](values map[K]bool) []K {
+	keys := make([]K, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func joinStrings[S ~string](values []S) string {
+	parts := make([]string, len(values))
+	for index, value := range values {
+		parts[index] = string(value)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,110 @@
+package scan
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	path := t.TempDir()
+	for name, content := range files {
+		file := filepath.Join(path, name)
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return path
+}
+
+func TestScan(t *testing.T) {
+	t.Run("detects frameworks, dependencies and backgrounds", func(t *testing.T) {
+		path := writeFiles(t, map[string]string{
+			"colors/mytheme.lua":       `vim.o.background = "dark"` + "\n" + `require("lush")(require("mytheme.theme"))`,
+			"colors/mytheme-light.vim": "set background=light\nlua require('mytheme').load()",
+			"colors/README.md":         "require('ignored')",
+			"lua/mytheme/init.lua":     `local util = require 'plenary.functional'` + "\n" + `if vim.o.background == "light" then end`,
+			"lua/mytheme/theme.lua":    `local lush = require("lush")` + "\n" + `local bit = require("bit")`,
+		})
+
+		result, err := Scan(path)
+		if err != nil {
+			t.Fatalf("Scan returned error: %v", err)
+		}
+
+		expected := Result{
+			Files:           []string{"colors/mytheme-light.vim", "colors/mytheme.lua"},
+			Frameworks:      []Framework{LushFramework},
+			Dependencies:    []string{"lush", "plenary"},
+			Backgrounds:     []string{"dark", "light"},
+			ReadsBackground: true,
+		}
+		if !reflect.DeepEqual(result, expected) {
+			t.Fatalf("Scan() = %+v, want %+v", result, expected)
+		}
+		if description := result.String(); description != "2 colors/ files, uses lush, requires lush, plenary, sets background dark, light" {
+			t.Fatalf("String() = %q", description)
+		}
+	})
+
+	t.Run("detects mini.base16", func(t *testing.T) {
+		path := writeFiles(t, map[string]string{
+			"colors/minischeme.lua": "require('mini.base16').setup({ palette = {} })",
+		})
+
+		result, err := Scan(path)
+		if err != nil {
+			t.Fatalf("Scan returned error: %v", err)
+		}
+		if !reflect.DeepEqual(result.Frameworks, []Framework{MiniBase16Framework}) || !reflect.DeepEqual(result.Dependencies, []string{"mini"}) {
+			t.Fatalf("Scan() = %+v, want mini.base16 from mini", result)
+		}
+		if result.ReadsBackground || len(result.Backgrounds) != 0 {
+			t.Fatalf("Scan() = %+v, want no background handling", result)
+		}
+	})
+
+	t.Run("reads only regular files inside the repository", func(t *testing.T) {
+		outside := writeFiles(t, map[string]string{"secret.lua": "require('outside')"})
+		path := writeFiles(t, map[string]string{
+			"colors/mytheme.lua":     "require('mytheme')",
+			"lua/mytheme/shared.lua": "require('plenary')",
+			"lua/mytheme/large.lua":  strings.Repeat(" ", maxFileSize) + "require('truncated')",
+		})
+		for link, target := range map[string]string{
+			"colors/zero.vim":        "/dev/zero",
+			"colors/secret.lua":      filepath.Join(outside, "secret.lua"),
+			"lua/mytheme/linked.lua": filepath.Join(path, "lua", "mytheme", "shared.lua"),
+		} {
+			if err := os.Symlink(target, filepath.Join(path, link)); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		result, err := Scan(path)
+		if err != nil {
+			t.Fatalf("Scan returned error: %v", err)
+		}
+		if !reflect.DeepEqual(result.Dependencies, []string{"plenary"}) {
+			t.Fatalf("Dependencies = %v, want [plenary] only", result.Dependencies)
+		}
+	})
+
+	t.Run("finds no colorschemes without colors/", func(t *testing.T) {
+		path := writeFiles(t, map[string]string{"plugin/plugin.vim": "set background=dark"})
+
+		result, err := Scan(path)
+		if err != nil {
+			t.Fatalf("Scan returned error: %v", err)
+		}
+		if result.HasColorschemes() {
+			t.Fatalf("Scan() = %+v, want no colorschemes", result)
+		}
+	})
+}
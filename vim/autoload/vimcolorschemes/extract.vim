@@ -0,0 +1,92 @@
+" Extracts the colors of the installed colorschemes for Vim, which can't run
+" vimcolorschemes/extractor.nvim, writing the same JSON format
+
+let s:attributes = ['bold', 'italic', 'underline', 'undercurl', 'underdouble', 'underdotted', 'underdashed', 'strikethrough', 'reverse']
+
+" Returns the colorschemes of the runtime path, except the built-in ones
+function! vimcolorschemes#extract#colorschemes() abort
+  let l:runtime = resolve($VIMRUNTIME)
+  let l:names = []
+  for l:path in globpath(&runtimepath, 'colors/*.vim', 0, 1)
+    if stridx(resolve(l:path), l:runtime) == 0
+      continue
+    endif
+    call add(l:names, fnamemodify(l:path, ':t:r'))
+  endfor
+  return uniq(sort(l:names))
+endfunction
+
+" Returns the hex code of a gui color, which can be a color name
+function! s:hex_code(value) abort
+  if a:value =~? '^#\x\{6}$'
+    return tolower(a:value)
+  endif
+  return tolower(get(v:colornames, tolower(a:value), ''))
+endfunction
+
+" Returns the <Name>Fg, <Name>Bg and <Name>Sp groups of every highlight,
+" resolved through their links
+function! s:groups() abort
+  let l:groups = []
+  for l:highlight in hlget()
+    let l:resolved = get(hlget(l:highlight.name, v:true), 0, {})
+    let l:link = get(l:highlight, 'linksto', '')
+    let l:gui = get(l:resolved, 'gui', {})
+    let l:first = 1
+
+    for [l:key, l:suffix] in [['guifg', 'Fg'], ['guibg', 'Bg'], ['guisp', 'Sp']]
+      let l:hex_code = s:hex_code(get(l:resolved, l:key, ''))
+      if empty(l:hex_code)
+        continue
+      endif
+
+      let l:group = {'name': l:highlight.name . l:suffix, 'hexCode': l:hex_code}
+      if !empty(l:link)
+        let l:group.link = l:link
+      endif
+      if l:first
+        for l:attribute in s:attributes
+          if get(l:gui, l:attribute, v:false)
+            let l:group[l:attribute] = v:true
+          endif
+        endfor
+        let l:first = 0
+      endif
+      call add(l:groups, l:group)
+    endfor
+  endfor
+  return l:groups
+endfunction
+
+" Loads every colorscheme with both backgrounds and writes their groups to
+" the output path
+function! vimcolorschemes#extract#run(output_path) abort
+  let l:data = {}
+  for l:name in vimcolorschemes#extract#colorschemes()
+    let l:entry = {}
+    for l:background in ['light', 'dark']
+      highlight clear
+      execute 'set background=' . l:background
+      " The colorscheme events don't fire in the autocommand running the
+      " extraction, the vimrc reads the colorscheme loaded from here
+      let g:vimcolorschemes_colorscheme = l:name
+      try
+        execute 'silent colorscheme ' . fnameescape(l:name)
+      catch
+        continue
+      endtry
+
+      " Colorschemes supporting a single background switch to it
+      if &background !=# l:background
+        continue
+      endif
+      let l:entry[l:background] = s:groups()
+    endfor
+
+    if !empty(l:entry)
+      let l:data[l:name] = l:entry
+    endif
+  endfor
+
+  call writefile([json_encode(l:data)], a:output_path)
+endfunction
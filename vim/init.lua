@@ -7,12 +7,81 @@ vim.opt.termguicolors = true
 vim.cmd("syntax on")
 vim.cmd("colorscheme default")
 
--- Necessary custom settings for some colorschemes
-vim.cmd("let g:solarized_termcolors=256")
+-- Highlight the other language samples with Treesitter when a parser is
+-- available, code_sample.vim keeps its legacy syntax groups
+vim.api.nvim_create_autocmd("FileType", {
+  pattern = { "lua", "python", "typescript", "rust" },
+  callback = function()
+    pcall(vim.treesitter.start)
+  end,
+})
+
+-- Writes the last colorscheme loaded, :messages and v:errmsg before exiting,
+-- explaining an extraction that failed
+if vim.env.MESSAGES_PATH then
+  local colorscheme = ""
+  vim.api.nvim_create_autocmd("ColorSchemePre", {
+    callback = function(args)
+      colorscheme = args.match
+    end,
+  })
+  vim.api.nvim_create_autocmd("VimLeavePre", {
+    callback = function()
+      local messages = vim.fn.execute("messages")
+      vim.fn.writefile({
+        vim.json.encode({
+          colorscheme = colorscheme ~= "" and colorscheme or (vim.g.colors_name or ""),
+          messages = vim.trim(messages),
+          errorMessage = vim.v.errmsg,
+        }),
+      }, vim.env.MESSAGES_PATH)
+    end,
+  })
+end
+
+-- Modules recording more data while extractor.nvim loads each colorscheme,
+-- enabled by setting the environment variable of their output path
+local recorders = {
+  { module = "vimcolorschemes.captures", output = "HIGHLIGHT_CAPTURES_PATH" },
+  { module = "vimcolorschemes.plugins", output = "PLUGIN_SUPPORT_PATH", input = "PLUGIN_QUERY_PATH" },
+  { module = "vimcolorschemes.links", output = "HIGHLIGHT_LINKS_PATH" },
+}
+
+-- Runs the configuration of the repository's recipe, see recipes.json
+local function run_recipe()
+  if vim.env.RECIPE_VIM then
+    vim.cmd(vim.env.RECIPE_VIM)
+  end
+  if vim.env.RECIPE_LUA then
+    assert(load(vim.env.RECIPE_LUA, "recipe"))()
+  end
+end
 
 vim.api.nvim_create_autocmd("BufReadPost", {
-  pattern = "code_sample.vim",
+  pattern = "code_sample.*",
   callback = function()
-    pcall(require("extractor").extract, { output_path = vim.env.COLOR_DATA_PATH })
+    local enabled = vim.tbl_filter(function(recorder)
+      return vim.env[recorder.output] ~= nil
+    end, recorders)
+
+    for _, recorder in ipairs(enabled) do
+      pcall(function()
+        require(recorder.module).start(recorder.input and vim.env[recorder.input])
+      end)
+    end
+
+    local ok, err = pcall(function()
+      run_recipe()
+      require("extractor").extract({ output_path = vim.env.COLOR_DATA_PATH })
+    end)
+    if not ok and vim.env.EXTRACT_ERROR_PATH then
+      vim.fn.writefile(vim.split(tostring(err), "\n"), vim.env.EXTRACT_ERROR_PATH)
+    end
+
+    for _, recorder in ipairs(enabled) do
+      pcall(function()
+        require(recorder.module).write(vim.env[recorder.output])
+      end)
+    end
   end,
 })
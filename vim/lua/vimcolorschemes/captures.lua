@@ -0,0 +1,90 @@
+-- Records the Treesitter capture and LSP semantic token highlight groups
+-- defined by each colorscheme loaded during the extraction
+local M = {}
+
+local attributes = {
+  "bold",
+  "italic",
+  "underline",
+  "undercurl",
+  "underdouble",
+  "underdotted",
+  "underdashed",
+  "strikethrough",
+  "reverse",
+}
+
+local defaults = {}
+local captures = {}
+
+local function is_capture(name)
+  return name:sub(1, 1) == "@"
+end
+
+-- Returns the "<name>Fg", "<name>Bg" and "<name>Sp" groups of a highlight,
+-- resolved through its links
+local function resolve(name)
+  local highlight = vim.api.nvim_get_hl(0, { name = name, link = false })
+
+  local groups = {}
+  for _, target in ipairs({ { "fg", "Fg" }, { "bg", "Bg" }, { "sp", "Sp" } }) do
+    local value = highlight[target[1]]
+    if value then
+      table.insert(groups, { name = name .. target[2], hexCode = string.format("#%06x", value) })
+    end
+  end
+
+  if #groups > 0 then
+    for _, attribute in ipairs(attributes) do
+      if highlight[attribute] then
+        groups[1][attribute] = true
+      end
+    end
+  end
+
+  return groups
+end
+
+local function record(args)
+  local name = args.match
+  if name == nil or name == "" then
+    return
+  end
+
+  local groups = {}
+  for group, highlight in pairs(vim.api.nvim_get_hl(0, {})) do
+    if is_capture(group) and not vim.deep_equal(highlight, defaults[group]) then
+      vim.list_extend(groups, resolve(group))
+    end
+  end
+  table.sort(groups, function(a, b)
+    return a.name < b.name
+  end)
+
+  captures[name] = captures[name] or {}
+  -- Empty Lua tables are encoded as JSON objects, encode them as null instead
+  captures[name][vim.o.background] = #groups > 0 and groups or vim.NIL
+end
+
+-- Snapshots the default capture groups, then records the groups of every
+-- colorscheme loaded afterwards that differ from the defaults
+function M.start()
+  for group, highlight in pairs(vim.api.nvim_get_hl(0, {})) do
+    if is_capture(group) then
+      defaults[group] = highlight
+    end
+  end
+
+  vim.api.nvim_create_autocmd("ColorScheme", { callback = record })
+end
+
+-- Writes the recorded groups as JSON, in the same shape as extractor.nvim's output
+function M.write(path)
+  local content = next(captures) == nil and "{}" or vim.json.encode(captures)
+
+  local file = assert(io.open(path, "w"))
+  file:write(content)
+  file:close()
+end
+
+return M
@@ -0,0 +1,39 @@
+-- Records the highlight links defined by each colorscheme loaded during the
+-- extraction, since extractor.nvim only outputs resolved colors
+local M = {}
+
+local links = {}
+
+local function record(args)
+  local name = args.match
+  if name == nil or name == "" then
+    return
+  end
+
+  local recorded = {}
+  for group, highlight in pairs(vim.api.nvim_get_hl(0, {})) do
+    if highlight.link then
+      recorded[group] = highlight.link
+    end
+  end
+
+  links[name] = links[name] or {}
+  -- Empty Lua tables are encoded as JSON objects, encode them as null instead
+  links[name][vim.o.background] = next(recorded) ~= nil and recorded or vim.NIL
+end
+
+-- Records the links of every colorscheme loaded afterwards
+function M.start()
+  vim.api.nvim_create_autocmd("ColorScheme", { callback = record })
+end
+
+-- Writes the recorded links as JSON, keyed by colorscheme, background and group
+function M.write(path)
+  local content = next(links) == nil and "{}" or vim.json.encode(links)
+
+  local file = assert(io.open(path, "w"))
+  file:write(content)
+  file:close()
+end
+
+return M
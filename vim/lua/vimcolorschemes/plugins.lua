@@ -0,0 +1,58 @@
+-- Records the plugin highlight groups explicitly defined by each colorscheme
+-- loaded during the extraction, and the plugin modules shipping a theme
+-- named after it
+local M = {}
+
+local query = { groups = {}, modules = {} }
+local support = {}
+
+-- Empty Lua tables are encoded as JSON objects, encode them as null instead
+local function list(values)
+  return #values > 0 and values or vim.NIL
+end
+
+local function record(args)
+  local name = args.match
+  if name == nil or name == "" then
+    return
+  end
+
+  local groups = {}
+  for _, group in ipairs(query.groups) do
+    if next(vim.api.nvim_get_hl(0, { name = group, create = false })) ~= nil then
+      table.insert(groups, group)
+    end
+  end
+
+  local modules = {}
+  for _, module in ipairs(query.modules) do
+    local path = "lua/" .. module:gsub("%.", "/") .. "/" .. name .. ".lua"
+    if #vim.api.nvim_get_runtime_file(path, false) > 0 then
+      table.insert(modules, module)
+    end
+  end
+
+  support[name] = support[name] or {}
+  support[name][vim.o.background] = { groups = list(groups), modules = list(modules) }
+end
+
+-- Reads the groups and modules to look for, then records them for every
+-- colorscheme loaded afterwards
+function M.start(query_path)
+  local file = assert(io.open(query_path, "r"))
+  query = vim.json.decode(file:read("*a"))
+  file:close()
+
+  vim.api.nvim_create_autocmd("ColorScheme", { callback = record })
+end
+
+-- Writes the recorded groups and modules as JSON, keyed by colorscheme and background
+function M.write(path)
+  local content = next(support) == nil and "{}" or vim.json.encode(support)
+
+  local file = assert(io.open(path, "w"))
+  file:write(content)
+  file:close()
+end
+
+return M
@@ -0,0 +1,28 @@
+-- Returns true if the color hex value is light
+local function is_hex_color_light(color)
+  local raw_color = color:gsub("#", "")
+
+  local red = tonumber(raw_color:sub(1, 2), 16)
+  local green = tonumber(raw_color:sub(3, 4), 16)
+  local blue = tonumber(raw_color:sub(5, 6), 16)
+
+  local brightness = ((red * 299) + (green * 587) + (blue * 114)) / 1000
+
+  return brightness > 155
+end
+
+local M = {}
+
+---@param groups table<string, string>
+function M.light_groups(groups)
+  local result = {}
+  for name, color in pairs(groups) do
+    if is_hex_color_light(color) then
+      table.insert(result, name)
+    end
+  end
+  vim.notify(string.format("%d light groups", #result), vim.log.levels.INFO)
+  return result
+end
+
+return M
@@ -0,0 +1,29 @@
+from dataclasses import dataclass
+
+
+@dataclass
+class Color:
+    """A color parsed from a hex value"""
+
+    red: int
+    green: int
+    blue: int
+
+    @classmethod
+    def from_hex(cls, value: str) -> "Color":
+        raw_color = value.lstrip("#")
+        return cls(*(int(raw_color[i : i + 2], 16) for i in range(0, 6, 2)))
+
+    @property
+    def is_light(self) -> bool:
+        # Perceived brightness
+        brightness = (self.red * 299 + self.green * 587 + self.blue * 114) / 1000
+        return brightness > 155
+
+
+def light_groups(groups: dict[str, str]) -> list[str]:
+    return [name for name, value in groups.items() if Color.from_hex(value).is_light]
+
+
+if __name__ == "__main__":
+    print(light_groups({"Normal": "#ffffff", "Comment": "#808080"}))
@@ -0,0 +1,31 @@
+use std::collections::HashMap;
+
+/// A color parsed from a hex value
+#[derive(Debug, Clone, Copy)]
+pub struct Color {
+    red: u8,
+    green: u8,
+    blue: u8,
+}
+
+impl Color {
+    pub fn from_hex(value: &str) -> Option<Self> {
+        let raw_color = value.trim_start_matches('#');
+        let channel = |i: usize| u8::from_str_radix(raw_color.get(i..i + 2)?, 16).ok();
+        Some(Self { red: channel(0)?, green: channel(2)?, blue: channel(4)? })
+    }
+
+    // Perceived brightness
+    pub fn is_light(&self) -> bool {
+        let brightness = (self.red as u32 * 299 + self.green as u32 * 587 + self.blue as u32 * 114) / 1000;
+        brightness > 155
+    }
+}
+
+pub fn light_groups(groups: &HashMap<String, String>) -> Vec<&str> {
+    groups
+        .iter()
+        .filter(|(_, value)| Color::from_hex(value).is_some_and(|color| color.is_light()))
+        .map(|(name, _)| name.as_str())
+        .collect()
+}
@@ -0,0 +1,30 @@
+// Returns true if the color hex value is light
+export function isHexColorLight(color: string): boolean {
+  const rawColor = color.replace("#", "");
+
+  const red = parseInt(rawColor.slice(0, 2), 16);
+  const green = parseInt(rawColor.slice(2, 4), 16);
+  const blue = parseInt(rawColor.slice(4, 6), 16);
+
+  const brightness = (red * 299 + green * 587 + blue * 114) / 1000;
+
+  return brightness > 155;
+}
+
+interface Group {
+  name: string;
+  hexCode: string;
+}
+
+export class Colorscheme {
+  constructor(
+    private readonly name: string,
+    private readonly groups: Group[] = [],
+  ) {}
+
+  get lightGroups(): string[] {
+    return this.groups
+      .filter(({ hexCode }) => isHexColorLight(hexCode))
+      .map(({ name }) => `${this.name}/${name}`);
+  }
+}